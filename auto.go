@@ -0,0 +1,248 @@
+package grpcwasm
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// DefaultAddr is the address [ServeAuto] listens on natively unless [WithAddr] is given.
+const DefaultAddr = ":50051"
+
+// ServeOption configures [ServeAuto].
+// Options for the native target are ignored in WASM and vice versa.
+type ServeOption func(c *serveConfig)
+
+type serveConfig struct {
+	ctx context.Context
+
+	// Native target.
+	addr     string
+	lis      net.Listener
+	web_addr string
+	web_lis  net.Listener
+
+	// WASM target.
+	listen_opts []ListenOption
+
+	logger     *slog.Logger
+	health     bool
+	reflection bool
+}
+
+// WithServeContext stops serving natively when the context ends.
+// By default it stops on SIGINT or SIGTERM.
+func WithServeContext(ctx context.Context) ServeOption {
+	return func(c *serveConfig) {
+		c.ctx = ctx
+	}
+}
+
+// WithAddr sets the TCP address gRPC is served on natively. See [DefaultAddr].
+func WithAddr(addr string) ServeOption {
+	return func(c *serveConfig) {
+		c.addr = addr
+	}
+}
+
+// WithListener serves gRPC natively on given listener instead of [WithAddr].
+func WithListener(lis net.Listener) ServeOption {
+	return func(c *serveConfig) {
+		c.lis = lis
+	}
+}
+
+// WithWeb also serves gRPC-Web over HTTP natively on given address,
+// so browsers can call the server without a proxy.
+// Connect clients call it through their gRPC-Web transport.
+func WithWeb(addr string) ServeOption {
+	return func(c *serveConfig) {
+		c.web_addr = addr
+	}
+}
+
+// WithWebListener is [WithWeb] with given listener.
+func WithWebListener(lis net.Listener) ServeOption {
+	return func(c *serveConfig) {
+		c.web_lis = lis
+	}
+}
+
+// WithListenOptions sets the options of the listener the server is served on in JS and WASI.
+func WithListenOptions(opts ...ListenOption) ServeOption {
+	return func(c *serveConfig) {
+		c.listen_opts = append(c.listen_opts, opts...)
+	}
+}
+
+// WithLogger logs where the server is served and every call with its status and duration.
+func WithLogger(l *slog.Logger) ServeOption {
+	return func(c *serveConfig) {
+		c.logger = l
+	}
+}
+
+// WithHealth registers grpc.health.v1.Health reporting every service as serving
+// until the server stops.
+func WithHealth() ServeOption {
+	return func(c *serveConfig) {
+		c.health = true
+	}
+}
+
+// WithReflection registers the gRPC server reflection service.
+func WithReflection() ServeOption {
+	return func(c *serveConfig) {
+		c.reflection = true
+	}
+}
+
+func newServeConfig(opts []ServeOption) *serveConfig {
+	c := &serveConfig{addr: DefaultAddr}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// register registers the services enabled by the options on the server.
+// It returns a function to call once the server stops.
+func (c *serveConfig) register(s *grpc.Server) func() {
+	if c.reflection {
+		reflection.Register(s)
+	}
+	if !c.health {
+		return func() {}
+	}
+
+	h := health.NewServer()
+	healthpb.RegisterHealthServer(s, h)
+	for name := range s.GetServiceInfo() {
+		h.SetServingStatus(name, healthpb.HealthCheckResponse_SERVING)
+	}
+	return h.Shutdown
+}
+
+// statsHandler returns the handler logging the calls made through given transport,
+// or nil if logging is not enabled.
+func (c *serveConfig) statsHandler(transport string) stats.Handler {
+	if c.logger == nil {
+		return nil
+	}
+	return &callLogger{l: c.logger.With("transport", transport)}
+}
+
+var _ stats.Handler = serverStats{}
+
+// serverStats is the stats handler [NewServer] installs, so [ServeAuto] can log the calls
+// of the server it serves directly.
+// Calls go to the handler of the [transportListener] they came through.
+type serverStats struct{}
+
+type serverStatsKey struct{}
+
+func (serverStats) TagConn(ctx context.Context, info *stats.ConnTagInfo) context.Context {
+	if a, ok := info.LocalAddr.(transportAddr); ok && a.handler != nil {
+		ctx = context.WithValue(ctx, serverStatsKey{}, a.handler)
+	}
+	return ctx
+}
+
+func (serverStats) HandleConn(ctx context.Context, s stats.ConnStats) {}
+
+func (serverStats) TagRPC(ctx context.Context, info *stats.RPCTagInfo) context.Context {
+	if v, ok := ctx.Value(serverStatsKey{}).(stats.Handler); ok {
+		return v.TagRPC(ctx, info)
+	}
+	return ctx
+}
+
+func (serverStats) HandleRPC(ctx context.Context, s stats.RPCStats) {
+	if v, ok := ctx.Value(serverStatsKey{}).(stats.Handler); ok {
+		v.HandleRPC(ctx, s)
+	}
+}
+
+// transportListener sets the stats handler of the calls made through the connections it accepts
+// for [serverStats]. The handler is nil if the calls are not logged.
+type transportListener struct {
+	net.Listener
+	handler stats.Handler
+}
+
+func (l transportListener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return transportConn{Conn: c, handler: l.handler}, nil
+}
+
+type transportConn struct {
+	net.Conn
+	handler stats.Handler
+}
+
+func (c transportConn) LocalAddr() net.Addr {
+	return transportAddr{Addr: c.Conn.LocalAddr(), handler: c.handler}
+}
+
+type transportAddr struct {
+	net.Addr
+	handler stats.Handler
+}
+
+var _ stats.Handler = (*callLogger)(nil)
+
+// callLogger logs every call when it ends.
+type callLogger struct {
+	l *slog.Logger
+}
+
+type callLoggerKey struct{}
+
+func (h *callLogger) TagRPC(ctx context.Context, info *stats.RPCTagInfo) context.Context {
+	return context.WithValue(ctx, callLoggerKey{}, info.FullMethodName)
+}
+
+func (h *callLogger) HandleRPC(ctx context.Context, s stats.RPCStats) {
+	end, ok := s.(*stats.End)
+	if !ok {
+		return
+	}
+
+	method, _ := ctx.Value(callLoggerKey{}).(string)
+	code := status.Code(end.Error)
+	h.l.LogAttrs(ctx, slog.LevelInfo, "call",
+		slog.String("method", method),
+		slog.String("code", code.String()),
+		slog.Duration("duration", end.EndTime.Sub(end.BeginTime).Round(time.Microsecond)),
+	)
+}
+
+func (h *callLogger) TagConn(ctx context.Context, info *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (h *callLogger) HandleConn(ctx context.Context, s stats.ConnStats) {}
+
+// dialBuffered returns a connection passing raw messages to the server listening on given listener.
+func dialBuffered(lis *bufconn.Listener, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	return grpc.NewClient("passthrough://bufnet", append([]grpc.DialOption{
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(NoopCodec{})),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(ctx context.Context, s string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+	}, opts...)...)
+}
@@ -0,0 +1,381 @@
+//go:build !wasm
+
+package grpcwasm_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"log"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"testing"
+
+	grpcwasm "github.com/lesomnus/grpc-wasm"
+	"github.com/lesomnus/grpc-wasm/internal/echo"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+type lockedBuffer struct {
+	mu sync.Mutex
+	b  bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.b.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.b.String()
+}
+
+// autoServer is a server served by [grpcwasm.ServeAuto] on local ports.
+type autoServer struct {
+	conn *grpc.ClientConn
+	web  string
+	logs *lockedBuffer
+}
+
+func withAutoServer(f func(x *require.Assertions, s *autoServer)) func(t *testing.T) {
+	return func(t *testing.T) {
+		x := require.New(t)
+
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		x.NoError(err)
+		web_lis, err := net.Listen("tcp", "127.0.0.1:0")
+		x.NoError(err)
+
+		server := grpcwasm.NewServer()
+		echo.RegisterEchoServiceServer(server, echo.EchoServer{})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		logs := &lockedBuffer{}
+		done := make(chan error, 1)
+		go func() {
+			done <- grpcwasm.ServeAuto(server,
+				grpcwasm.WithServeContext(ctx),
+				grpcwasm.WithListener(lis),
+				grpcwasm.WithWebListener(web_lis),
+				grpcwasm.WithLogger(slog.New(slog.NewTextHandler(logs, nil))),
+				grpcwasm.WithHealth(),
+				grpcwasm.WithReflection(),
+			)
+		}()
+
+		conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+		x.NoError(err)
+		defer conn.Close()
+
+		f(x, &autoServer{conn: conn, web: "http://" + web_lis.Addr().String(), logs: logs})
+
+		cancel()
+		x.NoError(<-done)
+	}
+}
+
+// post posts given body to the method over HTTP.
+func (s *autoServer) post(x *require.Assertions, method string, content_type string, body []byte) *http.Response {
+	req, err := http.NewRequest(http.MethodPost, s.web+method, bytes.NewReader(body))
+	x.NoError(err)
+	req.Header.Set("Content-Type", content_type)
+	req.Header.Set("Foo", "bar")
+
+	res, err := http.DefaultClient.Do(req)
+	x.NoError(err)
+	return res
+}
+
+func envelope(flags byte, msg []byte) []byte {
+	b := make([]byte, 5, 5+len(msg))
+	b[0] = flags
+	binary.BigEndian.PutUint32(b[1:], uint32(len(msg)))
+	return append(b, msg...)
+}
+
+// envelopes splits the body into the flags and the payloads of the envelopes.
+func envelopes(x *require.Assertions, body []byte) ([]byte, [][]byte) {
+	flags := []byte{}
+	msgs := [][]byte{}
+	for len(body) > 0 {
+		x.GreaterOrEqual(len(body), 5)
+		n := int(binary.BigEndian.Uint32(body[1:5]))
+		x.GreaterOrEqual(len(body), 5+n)
+		flags = append(flags, body[0])
+		msgs = append(msgs, body[5:5+n])
+		body = body[5+n:]
+	}
+	return flags, msgs
+}
+
+func TestServeAuto(t *testing.T) {
+	t.Run("grpc", withAutoServer(func(x *require.Assertions, s *autoServer) {
+		client := echo.NewEchoServiceClient(s.conn)
+
+		ctx := metadata.AppendToOutgoingContext(context.Background(), "foo", "bar")
+		header := metadata.MD{}
+		trailer := metadata.MD{}
+		req := &echo.EchoRequest{}
+		req.SetMessage("foo")
+		req.SetCircularShift(1)
+		res, err := client.Once(ctx, req, grpc.Header(&header), grpc.Trailer(&trailer))
+		x.NoError(err)
+		x.Equal("ofo", res.GetMessage())
+		x.Equal([]string{"bar"}, header.Get("foo"))
+		x.Equal([]string{"header"}, header.Get("timing"))
+		x.Equal([]string{"trailer"}, trailer.Get("timing"))
+
+		req.SetRepeat(3)
+		stream, err := client.Many(context.Background(), req)
+		x.NoError(err)
+		msgs := []string{}
+		for {
+			res, err := stream.Recv()
+			if err == io.EOF {
+				break
+			}
+			x.NoError(err)
+			msgs = append(msgs, res.GetMessage())
+		}
+		x.Equal([]string{"ofo", "oof", "foo"}, msgs)
+
+		st := &echo.Status{}
+		st.SetCode(int32(codes.NotFound))
+		st.SetMessage("no foo")
+		req.SetStatus(st)
+		_, err = client.Once(context.Background(), req)
+		x.Equal(codes.NotFound, status.Code(err))
+		x.Equal("no foo", status.Convert(err).Message())
+
+		x.Contains(s.logs.String(), "transport=grpc method=/echo.EchoService/Once code=NotFound")
+	}))
+	t.Run("health", withAutoServer(func(x *require.Assertions, s *autoServer) {
+		client := healthpb.NewHealthClient(s.conn)
+		for _, name := range []string{"", "echo.EchoService"} {
+			res, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: name})
+			x.NoError(err)
+			x.Equal(healthpb.HealthCheckResponse_SERVING, res.GetStatus())
+		}
+	}))
+	t.Run("reflection", withAutoServer(func(x *require.Assertions, s *autoServer) {
+		stream, err := reflectionpb.NewServerReflectionClient(s.conn).ServerReflectionInfo(context.Background())
+		x.NoError(err)
+
+		x.NoError(stream.Send(&reflectionpb.ServerReflectionRequest{
+			MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+		}))
+		res, err := stream.Recv()
+		x.NoError(err)
+
+		names := []string{}
+		for _, s := range res.GetListServicesResponse().GetService() {
+			names = append(names, s.GetName())
+		}
+		x.Contains(names, "echo.EchoService")
+
+		x.NoError(stream.CloseSend())
+		_, err = stream.Recv()
+		x.ErrorIs(err, io.EOF)
+	}))
+	t.Run("grpc-web", withAutoServer(func(x *require.Assertions, s *autoServer) {
+		res := s.post(x, "/echo.EchoService/Once", "application/grpc-web+proto", envelope(0, echoRequest(x, "foo", 1)))
+		defer res.Body.Close()
+		x.Equal(http.StatusOK, res.StatusCode)
+		x.Equal("bar", res.Header.Get("Foo"))
+
+		body, err := io.ReadAll(res.Body)
+		x.NoError(err)
+		flags, msgs := envelopes(x, body)
+		x.Equal([]byte{0x00, 0x80}, flags)
+		x.Equal("ofo", echoMessage(x, msgs[0]))
+		x.Contains(string(msgs[1]), "grpc-status: 0\r\n")
+		x.Contains(string(msgs[1]), "timing: trailer\r\n")
+
+		x.Contains(s.logs.String(), "transport=web method=/echo.EchoService/Once code=OK")
+	}))
+	t.Run("grpc-web error", withAutoServer(func(x *require.Assertions, s *autoServer) {
+		st := &echo.Status{}
+		st.SetCode(int32(codes.NotFound))
+		st.SetMessage("no foo%")
+		req := &echo.EchoRequest{}
+		req.SetStatus(st)
+		data, err := proto.Marshal(req)
+		x.NoError(err)
+
+		res := s.post(x, "/echo.EchoService/Once", "application/grpc-web+proto", envelope(0, data))
+		defer res.Body.Close()
+		x.Equal(http.StatusOK, res.StatusCode)
+
+		body, err := io.ReadAll(res.Body)
+		x.NoError(err)
+		flags, msgs := envelopes(x, body)
+		x.Equal([]byte{0x80}, flags)
+		x.Contains(string(msgs[0]), "grpc-status: 5\r\n")
+		x.Contains(string(msgs[0]), "grpc-message: no foo%25\r\n")
+	}))
+	t.Run("grpc-web stream", withAutoServer(func(x *require.Assertions, s *autoServer) {
+		req := &echo.EchoRequest{}
+		req.SetMessage("foo")
+		req.SetCircularShift(1)
+		req.SetRepeat(3)
+		data, err := proto.Marshal(req)
+		x.NoError(err)
+
+		res := s.post(x, "/echo.EchoService/Many", "application/grpc-web", envelope(0, data))
+		defer res.Body.Close()
+		x.Equal(http.StatusOK, res.StatusCode)
+		x.Equal("application/grpc-web", res.Header.Get("Content-Type"))
+
+		body, err := io.ReadAll(res.Body)
+		x.NoError(err)
+		flags, msgs := envelopes(x, body)
+		x.Equal([]byte{0x00, 0x00, 0x00, 0x80}, flags)
+		for i, want := range []string{"ofo", "oof", "foo"} {
+			x.Equal(want, echoMessage(x, msgs[i]))
+		}
+		x.Contains(string(msgs[3]), "grpc-status: 0\r\n")
+	}))
+	t.Run("grpc-web timeout", withAutoServer(func(x *require.Assertions, s *autoServer) {
+		req, err := http.NewRequest(http.MethodPost, s.web+"/echo.EchoService/Once", bytes.NewReader(envelope(0, echoRequest(x, "foo", 0))))
+		x.NoError(err)
+		req.Header.Set("Content-Type", "application/grpc-web+proto")
+		req.Header.Set("Grpc-Timeout", "1x")
+
+		res, err := http.DefaultClient.Do(req)
+		x.NoError(err)
+		defer res.Body.Close()
+
+		body, err := io.ReadAll(res.Body)
+		x.NoError(err)
+		_, msgs := envelopes(x, body)
+		x.Contains(string(msgs[0]), "grpc-status: 3\r\n")
+	}))
+	t.Run("grpc-web compressed", withAutoServer(func(x *require.Assertions, s *autoServer) {
+		res := s.post(x, "/echo.EchoService/Once", "application/grpc-web+proto", envelope(0x01, echoRequest(x, "foo", 0)))
+		defer res.Body.Close()
+
+		body, err := io.ReadAll(res.Body)
+		x.NoError(err)
+		_, msgs := envelopes(x, body)
+		x.Contains(string(msgs[len(msgs)-1]), "grpc-status: 12\r\n")
+	}))
+	t.Run("preflight", withAutoServer(func(x *require.Assertions, s *autoServer) {
+		req, err := http.NewRequest(http.MethodOptions, s.web+"/echo.EchoService/Once", nil)
+		x.NoError(err)
+		req.Header.Set("Origin", "http://example.com")
+		req.Header.Set("Access-Control-Request-Headers", "x-grpc-web,content-type")
+
+		res, err := http.DefaultClient.Do(req)
+		x.NoError(err)
+		defer res.Body.Close()
+		x.Equal(http.StatusNoContent, res.StatusCode)
+		x.Equal("*", res.Header.Get("Access-Control-Allow-Origin"))
+		x.Equal("x-grpc-web,content-type", res.Header.Get("Access-Control-Allow-Headers"))
+	}))
+	t.Run("unsupported content type", withAutoServer(func(x *require.Assertions, s *autoServer) {
+		// JSON is not supported.
+		res := s.post(x, "/echo.EchoService/Once", "application/grpc-web+json", nil)
+		defer res.Body.Close()
+		x.Equal(http.StatusUnsupportedMediaType, res.StatusCode)
+	}))
+}
+
+// peerEchoServer responds with the address of the peer the handler sees.
+type peerEchoServer struct {
+	echo.UnimplementedEchoServiceServer
+}
+
+func (peerEchoServer) Once(ctx context.Context, req *echo.EchoRequest) (*echo.EchoResponse, error) {
+	res := &echo.EchoResponse{}
+	if p, ok := peer.FromContext(ctx); ok {
+		res.SetMessage(p.Addr.String())
+	}
+	return res, nil
+}
+
+func TestServeAuto_Peer(t *testing.T) {
+	x := require.New(t)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	x.NoError(err)
+
+	server := grpcwasm.NewServer()
+	echo.RegisterEchoServiceServer(server, peerEchoServer{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- grpcwasm.ServeAuto(server, grpcwasm.WithServeContext(ctx), grpcwasm.WithListener(lis))
+	}()
+	defer func() {
+		cancel()
+		x.NoError(<-done)
+	}()
+
+	var local net.Addr
+	conn, err := grpc.NewClient(lis.Addr().String(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			c, err := (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+			if err == nil {
+				local = c.LocalAddr()
+			}
+			return c, err
+		}),
+	)
+	x.NoError(err)
+	defer conn.Close()
+
+	res, err := echo.NewEchoServiceClient(conn).Once(ctx, &echo.EchoRequest{})
+	x.NoError(err)
+	x.Equal(local.String(), res.GetMessage())
+}
+
+func TestServeAuto_NotRecovered(t *testing.T) {
+	x := require.New(t)
+
+	logs := &lockedBuffer{}
+	log.SetOutput(logs)
+	defer log.SetOutput(os.Stderr)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	x.NoError(err)
+
+	server := grpc.NewServer()
+	echo.RegisterEchoServiceServer(server, echo.EchoServer{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- grpcwasm.ServeAuto(server, grpcwasm.WithServeContext(ctx), grpcwasm.WithListener(lis))
+	}()
+	defer func() {
+		cancel()
+		x.NoError(<-done)
+	}()
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	x.NoError(err)
+	defer conn.Close()
+
+	// Served with a warning.
+	_, err = echo.NewEchoServiceClient(conn).Once(ctx, &echo.EchoRequest{})
+	x.NoError(err)
+	x.Contains(logs.String(), "grpcwasm.NewServer")
+}
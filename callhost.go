@@ -0,0 +1,131 @@
+package grpcwasm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/lesomnus/grpc-wasm/internal/js"
+	"github.com/lesomnus/grpc-wasm/internal/jz"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var ErrHostFunctionNotFound = errors.New("host function not found")
+
+// HostError is the error a host function threw or rejected with.
+type HostError struct {
+	// Name of the host function.
+	Function string
+	// Name of the error class, e.g. "TypeError". Empty if the value is not an Error.
+	Name    string
+	Message string
+	// Code is the `code` of the error if it is a gRPC status code, e.g. of GrpcWasmError,
+	// or Unknown.
+	Code codes.Code
+}
+
+func newHostError(function string, v js.Value) *HostError {
+	e := &HostError{Function: function, Code: codes.Unknown}
+	if err, ok := jz.FromJs(v).(*jz.JsError); ok {
+		e.Name = err.Name
+		e.Message = err.Message
+	}
+	if v.Type() == js.TypeObject {
+		if c := v.Get("code"); c.Type() == js.TypeNumber && c.Int() >= 0 && c.Int() <= int(codes.Unauthenticated) {
+			e.Code = codes.Code(c.Int())
+		}
+	}
+	return e
+}
+
+func (e *HostError) Error() string {
+	msg := e.Message
+	if e.Name != "" {
+		msg = e.Name + ": " + msg
+	}
+	return fmt.Sprintf("host function %q: %s", e.Function, msg)
+}
+
+// GRPCStatus returns the status of the code and the message,
+// so handlers can return the error as is.
+func (e *HostError) GRPCStatus() *status.Status {
+	return status.New(e.Code, e.Message)
+}
+
+// HostResult is the value a host function resolved with.
+type HostResult struct {
+	v js.Value
+}
+
+// Decode decodes the value into v.
+// Struct fields are matched by their `js` tags; Uint8Array is decoded into []byte and Date into [time.Time].
+func (r HostResult) Decode(v any) error {
+	if err := jz.Unmarshal(r.v, v); err != nil {
+		return fmt.Errorf("decode host result: %w", err)
+	}
+	return nil
+}
+
+// IsUndefined reports whether the function resolved with nothing.
+func (r HostResult) IsUndefined() bool {
+	return r.v.IsUndefined()
+}
+
+// CallHost calls the host function of given name the JS side registered at open()
+// and waits for it to settle or the context to end.
+// The arguments are converted to JS values as [HostResult.Decode] does in reverse,
+// e.g. []byte to Uint8Array and structs to objects keyed by their `js` tags.
+// It returns [HostError] if the function throws or rejects,
+// and [ErrHostFunctionNotFound] if no function of the name is registered.
+func CallHost(ctx context.Context, name string, args ...any) (HostResult, error) {
+	host := js.Global().Get("grpc_wasm_host")
+	if host.Type() != js.TypeObject || host.Get(name).Type() != js.TypeFunction {
+		return HostResult{}, fmt.Errorf("%w: %q", ErrHostFunctionNotFound, name)
+	}
+
+	params := make([]any, len(args))
+	for i, arg := range args {
+		v, err := jz.Marshal(arg)
+		if err != nil {
+			return HostResult{}, fmt.Errorf("host function %q: argument %d: %w", name, i, err)
+		}
+		params[i] = v
+	}
+
+	v, reason, err := awaitCall(ctx, func() js.Value {
+		return host.Call(name, params...)
+	})
+	if err != nil {
+		return HostResult{}, fmt.Errorf("host function %q: %w", name, err)
+	}
+	if !reason.IsUndefined() {
+		return HostResult{}, newHostError(name, reason)
+	}
+	return HostResult{v: v}, nil
+}
+
+// awaitCall calls f, which calls a JS function, and waits for what it returns to settle
+// or the context to end. What the JS function throws synchronously is returned as the reason.
+func awaitCall(ctx context.Context, f func() js.Value) (js.Value, js.Value, error) {
+	p, thrown := func() (v js.Value, thrown js.Value) {
+		defer func() {
+			r := recover()
+			if r == nil {
+				return
+			}
+			if e, ok := r.(js.Error); ok {
+				thrown = e.Value
+				return
+			}
+			panic(r)
+		}()
+
+		return f(), js.Undefined()
+	}()
+	if !thrown.IsUndefined() {
+		return js.Undefined(), thrown, nil
+	}
+
+	return jz.AwaitContext(ctx, js.Global().Get("Promise").Call("resolve", p))
+}
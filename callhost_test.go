@@ -0,0 +1,99 @@
+package grpcwasm_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	grpcwasm "github.com/lesomnus/grpc-wasm"
+	"github.com/lesomnus/grpc-wasm/internal/js"
+	"github.com/lesomnus/grpc-wasm/internal/jz"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCallHost(t *testing.T) {
+	never := make(chan struct{})
+	defer close(never)
+
+	fs := map[string]func(this js.Value, args []js.Value) any{
+		"echo": func(this js.Value, args []js.Value) any {
+			return jz.Resolve(args[0])
+		},
+		"answer": func(this js.Value, args []js.Value) any {
+			return 42
+		},
+		"fail": func(this js.Value, args []js.Value) any {
+			e := js.Global().Get("Error").New("no scripted answer")
+			e.Set("code", int(codes.NotFound))
+			return jz.Reject(e)
+		},
+		"never": func(this js.Value, args []js.Value) any {
+			return jz.Promise(func() (js.Value, js.Value) {
+				<-never
+				return js.Undefined(), js.Undefined()
+			})
+		},
+	}
+	host := js.Global().Get("Object").New()
+	for name, f := range fs {
+		fn := js.FuncOf(f)
+		defer fn.Release()
+		host.Set(name, fn)
+	}
+	js.Global().Set("grpc_wasm_host", host)
+	defer js.Global().Delete("grpc_wasm_host")
+
+	t.Run("result", func(t *testing.T) {
+		x := require.New(t)
+
+		type page struct {
+			URL  string `js:"url"`
+			Data []byte `js:"data"`
+		}
+
+		r, err := grpcwasm.CallHost(t.Context(), "echo", page{URL: "https://example.com/", Data: []byte("foo")})
+		x.NoError(err)
+
+		var v page
+		x.NoError(r.Decode(&v))
+		x.Equal(page{URL: "https://example.com/", Data: []byte("foo")}, v)
+
+		r, err = grpcwasm.CallHost(t.Context(), "answer")
+		x.NoError(err)
+		var n int
+		x.NoError(r.Decode(&n))
+		x.Equal(42, n)
+		x.ErrorContains(r.Decode(&v), "decode host result")
+	})
+	t.Run("error", func(t *testing.T) {
+		x := require.New(t)
+
+		_, err := grpcwasm.CallHost(t.Context(), "fail")
+		x.EqualError(err, `host function "fail": Error: no scripted answer`)
+
+		var host_err *grpcwasm.HostError
+		x.ErrorAs(err, &host_err)
+		x.Equal("no scripted answer", host_err.Message)
+
+		s, ok := status.FromError(err)
+		x.True(ok)
+		x.Equal(codes.NotFound, s.Code())
+
+		_, err = grpcwasm.CallHost(t.Context(), "Lebowski")
+		x.ErrorIs(err, grpcwasm.ErrHostFunctionNotFound)
+
+		_, err = grpcwasm.CallHost(t.Context(), "echo", make(chan int))
+		x.ErrorContains(err, "argument 0")
+	})
+	t.Run("context", func(t *testing.T) {
+		x := require.New(t)
+
+		ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err := grpcwasm.CallHost(ctx, "never")
+		x.ErrorIs(err, context.DeadlineExceeded)
+	})
+}
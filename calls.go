@@ -0,0 +1,147 @@
+package grpcwasm
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// CallInfo describes a call in flight made through the bridge.
+type CallInfo struct {
+	ID     uint32 `js:"id"`
+	ConnID uint32 `js:"conn_id"`
+
+	Method string     `js:"method"`
+	Type   MethodType `js:"type"`
+	// Metadata sent by the client.
+	Meta metadata.MD `js:"meta"`
+
+	DateStarted time.Time `js:"date_started"`
+
+	MsgSent     uint64 `js:"msg_sent"`
+	MsgReceived uint64 `js:"msg_received"`
+}
+
+type activeCall struct {
+	info CallInfo
+
+	msg_sent     atomic.Uint64
+	msg_received atomic.Uint64
+
+	cancel context.CancelCauseFunc
+}
+
+func (c *activeCall) sent() {
+	c.msg_sent.Add(1)
+}
+
+func (c *activeCall) received() {
+	c.msg_received.Add(1)
+}
+
+type callRegistry struct {
+	seq atomic.Uint32
+
+	mu    sync.Mutex
+	calls map[uint32]*activeCall
+}
+
+func newCallRegistry() *callRegistry {
+	return &callRegistry{
+		calls: map[uint32]*activeCall{},
+	}
+}
+
+// add registers a new call and returns a context for the call
+// which is cancelled by [callRegistry.Cancel].
+// The call must be removed by [callRegistry.remove] once it is finished.
+func (r *callRegistry) add(ctx context.Context, conn_id uint32, method string, t MethodType) (context.Context, *activeCall) {
+	ctx, cancel := context.WithCancelCause(ctx)
+
+	md, _ := metadata.FromOutgoingContext(ctx)
+	c := &activeCall{
+		info: CallInfo{
+			ID:     r.seq.Add(1),
+			ConnID: conn_id,
+
+			Method: method,
+			Type:   t,
+			Meta:   md.Copy(),
+
+			DateStarted: time.Now(),
+		},
+		cancel: cancel,
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls[c.info.ID] = c
+
+	return ctx, c
+}
+
+func (r *callRegistry) remove(c *activeCall) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.calls, c.info.ID)
+}
+
+// List returns calls in flight ordered by their ID.
+func (r *callRegistry) List() []CallInfo {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	vs := make([]CallInfo, 0, len(r.calls))
+	for _, c := range r.calls {
+		v := c.info
+		v.Meta = c.info.Meta.Copy()
+		v.MsgSent = c.msg_sent.Load()
+		v.MsgReceived = c.msg_received.Load()
+		vs = append(vs, v)
+	}
+	slices.SortFunc(vs, func(a, b CallInfo) int {
+		return int(a.ID) - int(b.ID)
+	})
+
+	return vs
+}
+
+// Cancel cancels the call of given ID so the client sees given status.
+// The status code must not be OK.
+func (r *callRegistry) Cancel(id uint32, s *status.Status) error {
+	if s.Code() == codes.OK {
+		return errors.New("call cannot be cancelled with OK status")
+	}
+
+	r.mu.Lock()
+	c, ok := r.calls[id]
+	r.mu.Unlock()
+	if !ok {
+		return status.Errorf(codes.NotFound, "call %d not found", id)
+	}
+
+	c.cancel(s.Err())
+	return nil
+}
+
+// cancelCause returns status given to [callRegistry.Cancel] if the context is cancelled by it.
+func cancelCause(ctx context.Context) (*status.Status, bool) {
+	if ctx.Err() == nil {
+		return nil, false
+	}
+
+	cause := context.Cause(ctx)
+	if errors.Is(cause, context.Canceled) || errors.Is(cause, context.DeadlineExceeded) {
+		return nil, false
+	}
+
+	return status.FromError(cause)
+}
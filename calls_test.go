@@ -0,0 +1,106 @@
+package grpcwasm_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	grpcwasm "github.com/lesomnus/grpc-wasm"
+	"github.com/lesomnus/grpc-wasm/internal/echo"
+	"github.com/lesomnus/grpc-wasm/internal/js"
+	"github.com/lesomnus/grpc-wasm/internal/jz"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func waitActiveCalls(x *require.Assertions, l *grpcwasm.Listener, n int) []grpcwasm.CallInfo {
+	for range 100 {
+		vs := l.ActiveCalls()
+		if len(vs) == n {
+			return vs
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	x.FailNow("timeout waiting active calls")
+	return nil
+}
+
+func TestListener_ActiveCalls(t *testing.T) {
+	t.Run("unary", withListener(func(ctx context.Context, x *require.Assertions, l *grpcwasm.Listener, conn *grpcwasm.Conn) {
+		x.Empty(l.ActiveCalls())
+
+		req := echo.EchoRequest{}
+		req.SetOverVoid(true)
+		in, err := protoMarshal(&req)
+		x.NoError(err)
+
+		p := conn.JsInvoke(js.Undefined(), []js.Value{
+			js.ValueOf(echo.EchoService_Once_FullMethodName),
+			in,
+			js.ValueOf(map[string]any{
+				"meta": map[string]any{"foo": []any{"bar"}},
+			}),
+		}).(js.Value)
+
+		vs := waitActiveCalls(x, l, 1)
+		x.Equal(echo.EchoService_Once_FullMethodName, vs[0].Method)
+		x.Equal(grpcwasm.MethodTypeUnary, vs[0].Type)
+		x.Equal([]string{"bar"}, vs[0].Meta.Get("foo"))
+		x.Equal(uint64(1), vs[0].MsgSent)
+
+		err = l.CancelCall(vs[0].ID, status.New(codes.Unavailable, "Donny, you're out of your element!"))
+		x.NoError(err)
+
+		v, err_js := jz.Await(p)
+		x.True(err_js.IsUndefined())
+		x.Equal(int(codes.Unavailable), v.Get("status").Get("code").Int())
+		x.Equal("Donny, you're out of your element!", v.Get("status").Get("message").String())
+		x.Empty(l.ActiveCalls())
+	}))
+	t.Run("stream", withListener(func(ctx context.Context, x *require.Assertions, l *grpcwasm.Listener, conn *grpcwasm.Conn) {
+		stream, err_js := jz.Await(conn.JsOpenBidiStream(js.Undefined(), []js.Value{
+			js.ValueOf(echo.EchoService_Live_FullMethodName),
+			js.ValueOf(map[string]any{}),
+		}).(js.Value))
+		x.True(err_js.IsUndefined())
+
+		req := echo.EchoRequest{}
+		req.SetMessage("Lebowski")
+		in, err := protoMarshal(&req)
+		x.NoError(err)
+
+		_, err_js = jz.Await(stream.Call("send", in))
+		x.True(err_js.IsUndefined())
+		_, err_js = jz.Await(stream.Call("recv"))
+		x.True(err_js.IsUndefined())
+
+		vs := waitActiveCalls(x, l, 1)
+		x.Equal(grpcwasm.MethodTypeBidiStream, vs[0].Type)
+		x.Equal(uint64(1), vs[0].MsgSent)
+		x.Equal(uint64(1), vs[0].MsgReceived)
+
+		v, err_js := jz.Await(l.JsActiveCalls(js.Undefined(), nil).(js.Value))
+		x.True(err_js.IsUndefined())
+		x.Equal(1, v.Length())
+		x.Equal(int(vs[0].ID), v.Index(0).Get("id").Int())
+		x.True(v.Index(0).Get("date_started").InstanceOf(js.Global().Get("Date")))
+
+		_, err_js = jz.Await(l.JsCancelCall(js.Undefined(), []js.Value{
+			js.ValueOf(vs[0].ID),
+			js.ValueOf(map[string]any{"code": int(codes.Aborted), "message": "Shut the f up, Donny"}),
+		}).(js.Value))
+		x.True(err_js.IsUndefined())
+
+		v, err_js = jz.Await(stream.Call("recv"))
+		x.True(err_js.IsUndefined())
+		x.True(v.Get("done").Bool())
+		x.Equal(int(codes.Aborted), v.Get("status").Get("code").Int())
+		waitActiveCalls(x, l, 0)
+	}))
+	t.Run("cancel unknown call", withListener(func(ctx context.Context, x *require.Assertions, l *grpcwasm.Listener, conn *grpcwasm.Conn) {
+		_, err_js := jz.Await(l.JsCancelCall(js.Undefined(), []js.Value{js.ValueOf(42)}).(js.Value))
+		x.False(err_js.IsUndefined())
+	}))
+}
@@ -0,0 +1,384 @@
+package grpcwasm
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+)
+
+// Clock tells the time and schedules timers.
+// Handlers get the clock of the bridge by [ClockFrom] instead of using package time,
+// so the JS side can freeze, set, and advance the time they see.
+type Clock interface {
+	Now() time.Time
+	Since(t time.Time) time.Duration
+	After(d time.Duration) <-chan time.Time
+	Sleep(d time.Duration)
+	NewTimer(d time.Duration) Timer
+	NewTicker(d time.Duration) Ticker
+	// AfterFunc calls f in its own goroutine after the duration.
+	// The returned timer has nil channel.
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is [time.Timer] made by [Clock].
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+// Ticker is [time.Ticker] made by [Clock].
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+	Reset(d time.Duration)
+}
+
+// RealClock returns the clock of package time.
+func RealClock() Clock {
+	return realClock{}
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) Since(t time.Time) time.Duration        { return time.Since(t) }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (realClock) Sleep(d time.Duration)                  { time.Sleep(d) }
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{time.NewTimer(d)}
+}
+
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+func (realClock) AfterFunc(d time.Duration, f func()) Timer {
+	return realTimer{time.AfterFunc(d, f)}
+}
+
+type realTimer struct{ *time.Timer }
+
+func (t realTimer) C() <-chan time.Time { return t.Timer.C }
+
+type realTicker struct{ *time.Ticker }
+
+func (t realTicker) C() <-chan time.Time { return t.Ticker.C }
+
+type clockKey struct{}
+
+// ContextWithClock returns a context whose [ClockFrom] is given clock.
+func ContextWithClock(ctx context.Context, c Clock) context.Context {
+	return context.WithValue(ctx, clockKey{}, c)
+}
+
+// ClockFrom returns the clock set by [ContextWithClock], or the clock of the bridge
+// the call came from if it is a context of a handler.
+// It returns [RealClock] if neither is found.
+func ClockFrom(ctx context.Context) Clock {
+	if c, ok := ctx.Value(clockKey{}).(Clock); ok {
+		return c
+	}
+	if l := listenerFrom(ctx); l != nil {
+		return l.clock
+	}
+	return RealClock()
+}
+
+// LocationFrom returns the location of the bridge the call came from if it is a context of a handler,
+// which the JS side can change unlike [time.Local].
+// It returns [time.Local] otherwise.
+func LocationFrom(ctx context.Context) *time.Location {
+	if l := listenerFrom(ctx); l != nil {
+		if loc := l.location.Load(); loc != nil {
+			return loc
+		}
+	}
+	return time.Local
+}
+
+// FakeClock is a clock controlled by hand.
+// It is either frozen or running along with the real time from where it is set.
+// Timers and tickers fire as the time passes, including when it is set or advanced.
+type FakeClock struct {
+	mu sync.Mutex
+
+	frozen bool
+	// Time the clock is frozen at.
+	at time.Time
+	// Difference from the real time while running.
+	offset time.Duration
+
+	timers fakeTimers
+	// Real timer that wakes the clock up for the earliest timer while running.
+	wake *time.Timer
+}
+
+// NewFakeClock returns a clock frozen at given time.
+func NewFakeClock(t time.Time) *FakeClock {
+	return &FakeClock{frozen: true, at: t}
+}
+
+func (c *FakeClock) now() time.Time {
+	if c.frozen {
+		return c.at
+	}
+	return time.Now().Add(c.offset)
+}
+
+func (c *FakeClock) set(t time.Time) {
+	if c.frozen {
+		c.at = t
+	} else {
+		c.offset = time.Until(t)
+	}
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now()
+}
+
+func (c *FakeClock) Since(t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
+
+// Frozen reports whether the clock is frozen.
+func (c *FakeClock) Frozen() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.frozen
+}
+
+// Freeze stops the clock at the current time.
+func (c *FakeClock) Freeze() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.at = c.now()
+	c.frozen = true
+	c.arm()
+}
+
+// Resume makes the clock run along with the real time from the current time.
+func (c *FakeClock) Resume() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.frozen {
+		return
+	}
+	c.frozen = false
+	c.offset = time.Until(c.at)
+	c.arm()
+}
+
+// Set sets the current time.
+// Timers due by the time fire in order.
+func (c *FakeClock) Set(t time.Time) {
+	c.mu.Lock()
+	fs := []func(){}
+	for len(c.timers) > 0 && !c.timers[0].at.After(t) {
+		timer := c.timers[0]
+		if timer.at.After(c.now()) {
+			c.set(timer.at)
+		}
+		fs = c.fire(timer, t, fs)
+	}
+	c.set(t)
+	c.arm()
+	c.mu.Unlock()
+
+	for _, f := range fs {
+		go f()
+	}
+}
+
+// Advance advances the current time by given duration. See [FakeClock.Set].
+func (c *FakeClock) Advance(d time.Duration) {
+	c.Set(c.Now().Add(d))
+}
+
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	return c.NewTimer(d).C()
+}
+
+func (c *FakeClock) Sleep(d time.Duration) {
+	<-c.After(d)
+}
+
+func (c *FakeClock) NewTimer(d time.Duration) Timer {
+	t := &fakeTimer{c: c, index: -1, ch: make(chan time.Time, 1)}
+	t.Reset(d)
+	return t
+}
+
+func (c *FakeClock) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("non-positive interval for NewTicker")
+	}
+
+	t := &fakeTimer{c: c, index: -1, ch: make(chan time.Time, 1)}
+	c.schedule(t, d, d)
+	return fakeTicker{t}
+}
+
+func (c *FakeClock) AfterFunc(d time.Duration, f func()) Timer {
+	t := &fakeTimer{c: c, index: -1, f: f}
+	t.Reset(d)
+	return t
+}
+
+// schedule schedules the timer and fires it right away if it is due.
+// Period is updated for tickers.
+func (c *FakeClock) schedule(t *fakeTimer, d time.Duration, period time.Duration) bool {
+	c.mu.Lock()
+	active := t.index >= 0
+	if active {
+		heap.Remove(&c.timers, t.index)
+	}
+
+	t.at = c.now().Add(d)
+	t.period = period
+	heap.Push(&c.timers, t)
+
+	fs := []func(){}
+	now := c.now()
+	for len(c.timers) > 0 && !c.timers[0].at.After(now) {
+		fs = c.fire(c.timers[0], now, fs)
+	}
+	c.arm()
+	c.mu.Unlock()
+
+	for _, f := range fs {
+		go f()
+	}
+	return active
+}
+
+func (c *FakeClock) unschedule(t *fakeTimer) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if t.index < 0 {
+		return false
+	}
+	heap.Remove(&c.timers, t.index)
+	c.arm()
+	return true
+}
+
+// fire fires the timer at the top and appends its function if any.
+// Tickers are rescheduled after given time, so they fire once however far the time jumps.
+func (c *FakeClock) fire(t *fakeTimer, until time.Time, fs []func()) []func() {
+	now := c.now()
+	if t.period > 0 {
+		// Ticks are dropped for slow receivers as [time.Ticker] does.
+		n := until.Sub(t.at)/t.period + 1
+		t.at = t.at.Add(n * t.period)
+		heap.Fix(&c.timers, t.index)
+	} else {
+		heap.Remove(&c.timers, t.index)
+	}
+
+	if t.f != nil {
+		return append(fs, t.f)
+	}
+	select {
+	case t.ch <- now:
+	default:
+	}
+	return fs
+}
+
+// arm wakes the clock up when the earliest timer is due while running.
+func (c *FakeClock) arm() {
+	if c.wake != nil {
+		c.wake.Stop()
+		c.wake = nil
+	}
+	if c.frozen || len(c.timers) == 0 {
+		return
+	}
+
+	c.wake = time.AfterFunc(c.timers[0].at.Sub(c.now()), func() {
+		c.Set(c.Now())
+	})
+}
+
+type fakeTimer struct {
+	c *FakeClock
+
+	at     time.Time
+	period time.Duration
+	f      func()
+	ch     chan time.Time
+
+	// Index in the heap or -1 if it is not scheduled.
+	index int
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.ch
+}
+
+func (t *fakeTimer) Stop() bool {
+	return t.c.unschedule(t)
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	return t.c.schedule(t, d, 0)
+}
+
+type fakeTicker struct {
+	t *fakeTimer
+}
+
+func (t fakeTicker) C() <-chan time.Time {
+	return t.t.ch
+}
+
+func (t fakeTicker) Stop() {
+	t.t.Stop()
+}
+
+func (t fakeTicker) Reset(d time.Duration) {
+	if d <= 0 {
+		panic("non-positive interval for Ticker.Reset")
+	}
+
+	t.t.c.schedule(t.t, d, d)
+}
+
+// fakeTimers is a min-heap of timers by their due time.
+type fakeTimers []*fakeTimer
+
+func (h fakeTimers) Len() int           { return len(h) }
+func (h fakeTimers) Less(i, j int) bool { return h[i].at.Before(h[j].at) }
+
+func (h fakeTimers) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *fakeTimers) Push(x any) {
+	t := x.(*fakeTimer)
+	t.index = len(*h)
+	*h = append(*h, t)
+}
+
+func (h *fakeTimers) Pop() any {
+	old := *h
+	t := old[len(old)-1]
+	old[len(old)-1] = nil
+	t.index = -1
+	*h = old[:len(old)-1]
+	return t
+}
@@ -0,0 +1,161 @@
+package grpcwasm_test
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	grpcwasm "github.com/lesomnus/grpc-wasm"
+	"github.com/stretchr/testify/require"
+)
+
+var epoch = time.Date(1998, time.March, 6, 0, 0, 0, 0, time.UTC)
+
+func TestFakeClock(t *testing.T) {
+	t.Run("frozen", func(t *testing.T) {
+		x := require.New(t)
+
+		c := grpcwasm.NewFakeClock(epoch)
+		time.Sleep(10 * time.Millisecond)
+		x.Equal(epoch, c.Now())
+		x.True(c.Frozen())
+
+		c.Advance(time.Hour)
+		x.Equal(epoch.Add(time.Hour), c.Now())
+
+		c.Set(epoch)
+		x.Equal(epoch, c.Now())
+	})
+	t.Run("resume and freeze", func(t *testing.T) {
+		x := require.New(t)
+
+		c := grpcwasm.NewFakeClock(epoch)
+		c.Resume()
+		x.False(c.Frozen())
+		time.Sleep(10 * time.Millisecond)
+		x.True(c.Now().After(epoch))
+		x.Less(c.Since(epoch), time.Second)
+
+		c.Freeze()
+		now := c.Now()
+		time.Sleep(10 * time.Millisecond)
+		x.Equal(now, c.Now())
+	})
+	t.Run("timer", func(t *testing.T) {
+		x := require.New(t)
+
+		c := grpcwasm.NewFakeClock(epoch)
+		timer := c.NewTimer(time.Minute)
+
+		c.Advance(30 * time.Second)
+		x.Empty(timer.C())
+
+		c.Advance(time.Hour)
+		x.Equal(epoch.Add(time.Minute), <-timer.C())
+		x.False(timer.Stop())
+
+		x.False(timer.Reset(time.Minute))
+		x.True(timer.Stop())
+		c.Advance(time.Hour)
+		x.Empty(timer.C())
+	})
+	t.Run("timers fire in order", func(t *testing.T) {
+		x := require.New(t)
+
+		c := grpcwasm.NewFakeClock(epoch)
+		b := c.NewTimer(2 * time.Minute)
+		a := c.NewTimer(time.Minute)
+
+		c.Advance(time.Hour)
+		x.Equal(epoch.Add(time.Minute), <-a.C())
+		x.Equal(epoch.Add(2*time.Minute), <-b.C())
+		x.Equal(epoch.Add(time.Hour), c.Now())
+	})
+	t.Run("ticker", func(t *testing.T) {
+		x := require.New(t)
+
+		c := grpcwasm.NewFakeClock(epoch)
+		ticker := c.NewTicker(time.Second)
+		defer ticker.Stop()
+
+		for i := range 3 {
+			c.Advance(time.Second)
+			x.Equal(epoch.Add(time.Duration(i+1)*time.Second), <-ticker.C())
+		}
+
+		// Fires once however far the time jumps.
+		c.Advance(time.Hour)
+		<-ticker.C()
+		x.Empty(ticker.C())
+
+		c.Advance(time.Second)
+		x.Len(ticker.C(), 1)
+	})
+	t.Run("running clock fires timers", func(t *testing.T) {
+		x := require.New(t)
+
+		c := grpcwasm.NewFakeClock(epoch)
+		c.Resume()
+
+		fired := atomic.Bool{}
+		c.AfterFunc(10*time.Millisecond, func() { fired.Store(true) })
+		x.Eventually(fired.Load, time.Second, time.Millisecond)
+	})
+	t.Run("sleep", func(t *testing.T) {
+		x := require.New(t)
+
+		c := grpcwasm.NewFakeClock(epoch)
+		done := make(chan struct{})
+		go func() {
+			c.Sleep(time.Hour)
+			close(done)
+		}()
+
+		x.Never(func() bool {
+			select {
+			case <-done:
+				return true
+			default:
+				return false
+			}
+		}, 20*time.Millisecond, time.Millisecond)
+
+		for {
+			c.Advance(time.Hour)
+			select {
+			case <-done:
+				return
+			case <-time.After(time.Millisecond):
+			}
+		}
+	})
+}
+
+func TestClockFrom(t *testing.T) {
+	x := require.New(t)
+
+	_, ok := grpcwasm.ClockFrom(context.Background()).(*grpcwasm.FakeClock)
+	x.False(ok)
+
+	c := grpcwasm.NewFakeClock(epoch)
+	ctx := grpcwasm.ContextWithClock(context.Background(), c)
+	x.Equal(epoch, grpcwasm.ClockFrom(ctx).Now())
+}
+
+func TestSeededSource(t *testing.T) {
+	x := require.New(t)
+
+	src := grpcwasm.NewSeededSource(42)
+	r := rand.New(src)
+	a := []uint64{r.Uint64(), r.Uint64()}
+
+	src.Seed(42)
+	b := []uint64{r.Uint64(), r.Uint64()}
+	x.Equal(a, b)
+
+	ctx := grpcwasm.ContextWithRand(context.Background(), r)
+	x.Same(r, grpcwasm.RandFrom(ctx))
+	x.NotNil(grpcwasm.RandFrom(context.Background()))
+}
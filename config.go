@@ -0,0 +1,50 @@
+package grpcwasm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/lesomnus/grpc-wasm/internal/js"
+)
+
+type configKey struct{}
+
+// ContextWithConfig returns a context whose [Config] is given JSON,
+// e.g. to call the handlers natively.
+func ContextWithConfig(ctx context.Context, config json.RawMessage) context.Context {
+	return context.WithValue(ctx, configKey{}, config)
+}
+
+// Config returns the config set by [ContextWithConfig], or the config object
+// given to open() on the JS side, as JSON.
+// It returns nil if neither is given.
+func Config(ctx context.Context) json.RawMessage {
+	if v, ok := ctx.Value(configKey{}).(json.RawMessage); ok {
+		return v
+	}
+	return openConfig()
+}
+
+// LoadConfig decodes the config object given to open() on the JS side into v
+// as [json.Unmarshal] does. It leaves v as is if no config is given.
+// Use it in main to configure the services before serving them.
+func LoadConfig(v any) error {
+	config := openConfig()
+	if config == nil {
+		return nil
+	}
+	if err := json.Unmarshal(config, v); err != nil {
+		return fmt.Errorf("decode config: %w", err)
+	}
+	return nil
+}
+
+// openConfig returns the config object the worker set before running the bridge, as JSON.
+func openConfig() json.RawMessage {
+	v := js.Global().Get("grpc_wasm_config")
+	if v.IsUndefined() || v.IsNull() {
+		return nil
+	}
+	return json.RawMessage(js.Global().Get("JSON").Call("stringify", v).String())
+}
@@ -0,0 +1,50 @@
+package grpcwasm_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	grpcwasm "github.com/lesomnus/grpc-wasm"
+	"github.com/lesomnus/grpc-wasm/internal/js"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig(t *testing.T) {
+	type config struct {
+		Dataset  string          `json:"dataset"`
+		Features map[string]bool `json:"features"`
+		Role     string          `json:"role"`
+	}
+
+	t.Run("not given", func(t *testing.T) {
+		x := require.New(t)
+
+		x.Nil(grpcwasm.Config(context.Background()))
+
+		v := config{Role: "guest"}
+		x.NoError(grpcwasm.LoadConfig(&v))
+		x.Equal(config{Role: "guest"}, v)
+	})
+	t.Run("given to open", func(t *testing.T) {
+		x := require.New(t)
+
+		js.Global().Set("grpc_wasm_config", js.Global().Get("JSON").Call("parse", `{"dataset":"small","features":{"dark":true}}`))
+		defer js.Global().Delete("grpc_wasm_config")
+
+		x.JSONEq(`{"dataset":"small","features":{"dark":true}}`, string(grpcwasm.Config(context.Background())))
+
+		v := config{Role: "guest"}
+		x.NoError(grpcwasm.LoadConfig(&v))
+		x.Equal(config{Dataset: "small", Features: map[string]bool{"dark": true}, Role: "guest"}, v)
+
+		var bad struct{ Dataset int }
+		x.ErrorContains(grpcwasm.LoadConfig(&bad), "decode config")
+	})
+	t.Run("context", func(t *testing.T) {
+		x := require.New(t)
+
+		ctx := grpcwasm.ContextWithConfig(context.Background(), json.RawMessage(`{"role":"admin"}`))
+		x.JSONEq(`{"role":"admin"}`, string(grpcwasm.Config(ctx)))
+	})
+}
@@ -1,11 +1,12 @@
-//go:build js && wasm
-
 package grpcwasm
 
 import (
 	"context"
-	"syscall/js"
+	"sync"
+	"time"
 
+	"github.com/lesomnus/grpc-wasm/inspector"
+	"github.com/lesomnus/grpc-wasm/internal/js"
 	"github.com/lesomnus/grpc-wasm/internal/jz"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
@@ -15,8 +16,92 @@ import (
 type Conn struct {
 	*grpc.ClientConn
 
+	id      uint32
+	calls   *callRegistry
+	handles *handleCounts
+
 	scope *jz.Scope
 	ctx   context.Context
+
+	// Set if the listener is inspected.
+	inspect *inspector.ConnHandler
+	// Set if the connection has its own server.
+	server *serverSlot
+
+	stream_idle_timeout time.Duration
+
+	// Set once the connection is exported to JS.
+	obj          *jz.Object
+	obj_once     sync.Once
+	release_once sync.Once
+}
+
+func (c *Conn) Close() error {
+	err := c.ClientConn.Close()
+	if c.inspect != nil {
+		c.inspect.Close()
+	}
+	if c.server != nil {
+		c.server.close()
+	}
+	c.release_once.Do(func() {
+		if c.obj != nil {
+			c.obj.Release()
+			c.handles.conns.Add(-1)
+		}
+	})
+	return err
+}
+
+// callOption is an option given by JS side for each call.
+type callOption struct {
+	Meta rpcMeta `js:"meta"`
+	// AbortSignal that aborts the call.
+	Signal js.Value `js:"signal"`
+	// Promise<void> that aborts the call when it resolves.
+	AbortRequest js.Value `js:"abort_request"`
+}
+
+var callOptionParams = jz.Params{
+	{Name: "option.signal", Kind: jz.KindAbortSignal, Optional: true},
+	{Name: "option.abort_request", Kind: jz.KindThenable, Optional: true},
+}
+
+func parseCallOption(v js.Value) (callOption, error) {
+	o := callOption{}
+	if err := jz.Unmarshal(v, &o); err != nil {
+		return o, err
+	}
+	if err := callOptionParams.Check([]js.Value{o.Signal, o.AbortRequest}); err != nil {
+		return o, err
+	}
+
+	return o, nil
+}
+
+// context derives a context for the call from given one.
+// The returned cancel function must be called once the call is finished.
+func (o callOption) context(ctx context.Context) (context.Context, context.CancelFunc) {
+	cancels := []context.CancelFunc{}
+	if v := o.Signal; !v.IsUndefined() && !v.IsNull() {
+		ctx_, cancel := jz.ContextFromSignal(ctx, v)
+		ctx = ctx_
+		cancels = append(cancels, cancel)
+	}
+	if v := o.AbortRequest; !v.IsUndefined() && !v.IsNull() {
+		ctx_, cancel := jz.ContextFromPromise(ctx, v)
+		ctx = ctx_
+		cancels = append(cancels, cancel)
+	}
+	if len(o.Meta) > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, metadata.MD(o.Meta))
+	}
+
+	return ctx, func() {
+		for _, cancel := range cancels {
+			cancel()
+		}
+	}
 }
 
 func (c *Conn) JsClose(this js.Value, args []js.Value) any {
@@ -30,6 +115,12 @@ func (c *Conn) JsClose(this js.Value, args []js.Value) any {
 	})
 }
 
+var invokeParams = jz.Params{
+	{Name: "method", Kind: jz.KindString},
+	{Name: "req", Kind: jz.KindUint8Array},
+	{Name: "option", Kind: jz.KindObject, Optional: true},
+}
+
 // JsInvoke send serialized data to the server with given method.
 // It is resolved even if server responded with status.Code > 0 and "response" will be empty.
 //
@@ -40,6 +131,7 @@ func (c *Conn) JsClose(this js.Value, args []js.Value) any {
 //	};
 //	type Option = {
 //		meta?: Metadata
+//		signal?: AbortSignal
 //		abort_request?: Promise<void>
 //	}
 //	type RpcStatus = {
@@ -52,38 +144,26 @@ func (c *Conn) JsClose(this js.Value, args []js.Value) any {
 //		response: Uint8Array
 //		status: RpcStatus
 //	};
-//	function(method: string, req: Uint8Array, option: Option): Promise<RpcResult>;
+//	function(method: string, req: Uint8Array, option?: Option): Promise<RpcResult>;
 func (c *Conn) JsInvoke(this js.Value, args []js.Value) any {
-	return c.scope.Promise(func() (js.Value, js.Value) {
-		if len(args) != 3 {
-			return js.Undefined(), jz.Error("expects 3 arguments: method, serialized message, and option")
-		}
+	if err := invokeParams.Check(args); err != nil {
+		return jz.Reject(jz.ToError(err))
+	}
 
-		method := args[0].String()
-		req := args[1]
-		opt := args[2]
-
-		size := req.Get("byteLength").Int()
-		data := make([]byte, size)
-		js.CopyBytesToGo(data, req)
-
-		ctx := c.ctx
-		if v := opt.Get("abort_request"); !v.IsUndefined() {
-			ctx_, cancel := context.WithCancel(ctx)
-			ctx = ctx_
-			v.Call("then", c.scope.FuncOf(func(this js.Value, args []js.Value) any {
-				cancel()
-				return js.Undefined()
-			}))
-		}
+	method := args[0].String()
+	data := jz.BytesToGo(args[1])
+	opt, err := parseCallOption(jz.Arg(args, 2))
+	if err != nil {
+		return jz.Reject(jz.ToError(err))
+	}
 
-		meta := metadata.MD{}
-		if v := opt.Get("meta"); v.Type() == js.TypeObject {
-			metaToGo(meta, v)
-		}
-		if meta.Len() > 0 {
-			ctx = metadata.NewOutgoingContext(ctx, meta)
-		}
+	return c.scope.Promise(func() (js.Value, js.Value) {
+		ctx, cancel := opt.context(c.ctx)
+		defer cancel()
+
+		ctx, call := c.calls.add(ctx, c.id, method, MethodTypeUnary)
+		defer c.calls.remove(call)
+		defer call.cancel(nil)
 
 		header := metadata.MD{}
 		trailer := metadata.MD{}
@@ -96,28 +176,45 @@ func (c *Conn) JsInvoke(this js.Value, args []js.Value) any {
 			out []byte
 			st  status.Status
 		)
+		call.sent()
 		if err := c.ClientConn.Invoke(ctx, method, data, &out, opts...); err != nil {
 			s, ok := status.FromError(err)
 			if !ok {
 				return js.Undefined(), jz.ToError(err)
 			}
+			if s_, ok := cancelCause(ctx); ok {
+				s = s_
+			}
 			if s != nil {
 				st = *s
 			}
+		} else {
+			call.received()
 		}
 
-		js_out := js.Global().Get("Uint8Array").New(len(out))
-		js.CopyBytesToJS(js_out, out)
+		if out == nil {
+			// Response is always a Uint8Array even if the call failed.
+			out = []byte{}
+		}
+		v, err := jz.Marshal(rpcResult{
+			Header:   rpcMeta(header),
+			Trailer:  rpcMeta(trailer),
+			Response: out,
+			Status:   newRpcStatus(&st),
+		})
+		if err != nil {
+			return js.Undefined(), jz.ToError(err)
+		}
 
-		return js.ValueOf(map[string]any{
-			"header":   metaToJs(header),
-			"trailer":  metaToJs(trailer),
-			"response": js_out,
-			"status":   statusToJs(&st),
-		}), js.Undefined()
+		return v, js.Undefined()
 	})
 }
 
+var openStreamParams = jz.Params{
+	{Name: "method", Kind: jz.KindString},
+	{Name: "option", Kind: jz.KindObject, Optional: true},
+}
+
 // Signature:
 //
 //	type StreamResult =
@@ -135,27 +232,25 @@ func (c *Conn) JsInvoke(this js.Value, args []js.Value) any {
 //		send: (Uint8Array)=>Promise<void>
 //		recv: ()=>Promise<StreamResult>
 //	}
-//	function(method: string, option: {meta?: Metadata}): Promise<Stream>;
+//	function(method: string, option?: Option): Promise<Stream>;
 func (c *Conn) jsOpenStream(desc *grpc.StreamDesc, _ js.Value, args []js.Value) any {
-	return c.scope.Promise(func() (js.Value, js.Value) {
-		if len(args) != 2 {
-			return js.Undefined(), jz.Error("expects 2 arguments: method, and option")
-		}
+	if err := openStreamParams.Check(args); err != nil {
+		return jz.Reject(jz.ToError(err))
+	}
 
-		method := args[0].String()
-		opt := args[1]
+	method := args[0].String()
+	opt, err := parseCallOption(jz.Arg(args, 1))
+	if err != nil {
+		return jz.Reject(jz.ToError(err))
+	}
 
-		ctx := c.ctx
-		meta := metadata.MD{}
-		if v := opt.Get("meta"); v.Type() == js.TypeObject {
-			metaToGo(meta, v)
-		}
-		if meta.Len() > 0 {
-			ctx = metadata.NewOutgoingContext(ctx, meta)
-		}
-
-		stream, err := NewStream(ctx, c, desc, method)
+	return c.scope.Promise(func() (js.Value, js.Value) {
+		ctx, cancel := opt.context(c.ctx)
+		stream, err := NewStream(ctx, c, desc, method, grpc.OnFinish(func(error) {
+			cancel()
+		}))
 		if err != nil {
+			cancel()
 			return js.Undefined(), jz.ToError(err)
 		}
 
@@ -175,13 +270,20 @@ func (c *Conn) JsOpenBidiStream(this js.Value, args []js.Value) any {
 	return c.jsOpenStream(&grpc.StreamDesc{ServerStreams: true, ClientStreams: true}, this, args)
 }
 
-func (c Conn) ToJs() js.Value {
-	return js.ValueOf(map[string]any{
-		"close":  c.scope.FuncOf(c.JsClose),
-		"invoke": c.scope.FuncOf(c.JsInvoke),
-
-		"open_server_stream": c.scope.FuncOf(c.JsOpenServerStream),
-		"open_client_stream": c.scope.FuncOf(c.JsOpenClientStream),
-		"open_bidi_stream":   c.scope.FuncOf(c.JsOpenBidiStream),
+// ToJs exports the connection to JS.
+// Its functions are released when the connection is closed.
+// The connection is closed if JS drops it without closing.
+func (c *Conn) ToJs() js.Value {
+	c.obj_once.Do(func() {
+		c.obj = c.scope.Object().
+			Method("close", c.JsClose).
+			Method("invoke", c.JsInvoke).
+			Method("open_server_stream", c.JsOpenServerStream).
+			Method("open_client_stream", c.JsOpenClientStream).
+			Method("open_bidi_stream", c.JsOpenBidiStream).
+			OnCollected(func() { c.Close() })
+		c.handles.conns.Add(1)
 	})
+
+	return c.obj.Value()
 }
@@ -1,16 +1,15 @@
-//go:build js && wasm
-
 package grpcwasm_test
 
 import (
 	"context"
 	"fmt"
 	"sync"
-	"syscall/js"
 	"testing"
+	"time"
 
 	grpcwasm "github.com/lesomnus/grpc-wasm"
 	"github.com/lesomnus/grpc-wasm/internal/echo"
+	"github.com/lesomnus/grpc-wasm/internal/js"
 	"github.com/lesomnus/grpc-wasm/internal/jz"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
@@ -107,15 +106,69 @@ func TestConn_JsInvoke(t *testing.T) {
 		x.Equal("bar", v.Get("trailer").Get("foo").Index(0).String())
 		x.Equal("trailer", v.Get("trailer").Get("timing").Index(0).String())
 	}))
+	t.Run("wrong arguments", withConn(func(ctx context.Context, x *require.Assertions, conn *grpcwasm.Conn) {
+		p := conn.JsInvoke(js.Undefined(), []js.Value{
+			js.ValueOf(echo.EchoService_Once_FullMethodName),
+			js.ValueOf("Lebowski"),
+		}).(js.Value)
+
+		_, err_js := jz.Await(p)
+		x.True(err_js.InstanceOf(js.Global().Get("TypeError")))
+		x.Contains(err_js.Get("message").String(), "req")
+	}))
+	t.Run("without option", withConn(func(ctx context.Context, x *require.Assertions, conn *grpcwasm.Conn) {
+		in, err := protoMarshal(&echo.EchoRequest{})
+		x.NoError(err)
+
+		p := conn.JsInvoke(js.Undefined(), []js.Value{
+			js.ValueOf(echo.EchoService_Once_FullMethodName),
+			in,
+		}).(js.Value)
+
+		v, err_js := jz.Await(p)
+		x.True(err_js.IsUndefined())
+		x.Equal(int(codes.OK), v.Get("status").Get("code").Int())
+	}))
+	t.Run("aborted by signal", withConn(func(ctx context.Context, x *require.Assertions, conn *grpcwasm.Conn) {
+		req := echo.EchoRequest{}
+		req.SetOverVoid(true)
+
+		ac := js.Global().Get("AbortController").New()
+		time.AfterFunc(10*time.Millisecond, func() {
+			ac.Call("abort")
+		})
+
+		v, err_js := jsInvoke(x, conn, echo.EchoService_Once_FullMethodName, &req, map[string]any{
+			"signal": ac.Get("signal"),
+		})
+		x.True(err_js.IsUndefined())
+		x.Equal(int(codes.Canceled), v.Get("status").Get("code").Int())
+	}))
+	t.Run("aborted by request", withConn(func(ctx context.Context, x *require.Assertions, conn *grpcwasm.Conn) {
+		req := echo.EchoRequest{}
+		req.SetOverVoid(true)
+
+		v, err_js := jsInvoke(x, conn, echo.EchoService_Once_FullMethodName, &req, map[string]any{
+			"abort_request": jz.Resolve(js.Undefined()),
+		})
+		x.True(err_js.IsUndefined())
+		x.Equal(int(codes.Canceled), v.Get("status").Get("code").Int())
+	}))
 }
 
 func withConn(f func(ctx context.Context, x *require.Assertions, conn *grpcwasm.Conn)) func(t *testing.T) {
+	return withListener(func(ctx context.Context, x *require.Assertions, l *grpcwasm.Listener, conn *grpcwasm.Conn) {
+		f(ctx, x, conn)
+	})
+}
+
+func withListener(f func(ctx context.Context, x *require.Assertions, l *grpcwasm.Listener, conn *grpcwasm.Conn), opts ...grpcwasm.ListenOption) func(t *testing.T) {
 	return func(t *testing.T) {
 		t.Helper()
 
 		x := require.New(t)
 
-		l := grpcwasm.NewListener()
+		l := grpcwasm.NewListener(opts...)
 		defer l.Close()
 
 		conn, err := l.Dial()
@@ -132,7 +185,7 @@ func withConn(f func(ctx context.Context, x *require.Assertions, conn *grpcwasm.
 			s.Serve(l)
 		}()
 
-		f(t.Context(), x, conn)
+		f(t.Context(), x, l, conn)
 
 		conn.Close()
 		l.Close()
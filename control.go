@@ -0,0 +1,202 @@
+package grpcwasm
+
+import (
+	"errors"
+	"time"
+
+	"github.com/lesomnus/grpc-wasm/internal/js"
+	"github.com/lesomnus/grpc-wasm/internal/jz"
+)
+
+// fakeClock returns the clock of the listener if it can be controlled.
+func (l *Listener) fakeClock() (*FakeClock, error) {
+	c, ok := l.clock.(*FakeClock)
+	if !ok {
+		return nil, errors.New("clock is not controllable")
+	}
+	return c, nil
+}
+
+// Signature:
+//
+//	type ClockState = {
+//		now: Date
+//		frozen: boolean
+//	}
+//	function(): Promise<ClockState>;
+func (l *Listener) JsClockNow(this js.Value, args []js.Value) any {
+	s := struct {
+		Now    time.Time `js:"now"`
+		Frozen bool      `js:"frozen"`
+	}{Now: l.clock.Now()}
+	if c, ok := l.clock.(*FakeClock); ok {
+		s.Frozen = c.Frozen()
+	}
+
+	v, err := jz.Marshal(s)
+	if err != nil {
+		return jz.Reject(jz.ToError(err))
+	}
+
+	return jz.Resolve(v)
+}
+
+// JsClockFreeze stops the clock of the bridge at the current time.
+//
+// Signature:
+//
+//	function(): Promise<void>;
+func (l *Listener) JsClockFreeze(this js.Value, args []js.Value) any {
+	c, err := l.fakeClock()
+	if err != nil {
+		return jz.Reject(jz.ToError(err))
+	}
+
+	c.Freeze()
+	return jz.Resolve(js.Undefined())
+}
+
+// JsClockResume makes the clock of the bridge run along with the real time from the current time.
+//
+// Signature:
+//
+//	function(): Promise<void>;
+func (l *Listener) JsClockResume(this js.Value, args []js.Value) any {
+	c, err := l.fakeClock()
+	if err != nil {
+		return jz.Reject(jz.ToError(err))
+	}
+
+	c.Resume()
+	return jz.Resolve(js.Undefined())
+}
+
+var clockSetParams = jz.Params{
+	{Name: "date", Kind: jz.KindObject},
+}
+
+// JsClockSet sets the time of the bridge, firing the timers due by the time.
+//
+// Signature:
+//
+//	function(date: Date): Promise<void>;
+func (l *Listener) JsClockSet(this js.Value, args []js.Value) any {
+	if err := clockSetParams.Check(args); err != nil {
+		return jz.Reject(jz.ToError(err))
+	}
+	c, err := l.fakeClock()
+	if err != nil {
+		return jz.Reject(jz.ToError(err))
+	}
+
+	var t time.Time
+	if err := jz.Unmarshal(args[0], &t); err != nil {
+		return jz.Reject(jz.ToError(err))
+	}
+
+	c.Set(t)
+	return jz.Resolve(js.Undefined())
+}
+
+var clockAdvanceParams = jz.Params{
+	{Name: "duration_ms", Kind: jz.KindNumber},
+}
+
+// JsClockAdvance advances the time of the bridge, firing the timers due by the time.
+//
+// Signature:
+//
+//	function(duration_ms: number): Promise<void>;
+func (l *Listener) JsClockAdvance(this js.Value, args []js.Value) any {
+	if err := clockAdvanceParams.Check(args); err != nil {
+		return jz.Reject(jz.ToError(err))
+	}
+	c, err := l.fakeClock()
+	if err != nil {
+		return jz.Reject(jz.ToError(err))
+	}
+
+	c.Advance(time.Duration(args[0].Float() * float64(time.Millisecond)))
+	return jz.Resolve(js.Undefined())
+}
+
+var setSeedParams = jz.Params{
+	{Name: "seed", Kind: jz.KindNumber},
+}
+
+// JsSetSeed re-seeds the generator handlers get by [RandFrom].
+//
+// Signature:
+//
+//	function(seed: number): Promise<void>;
+func (l *Listener) JsSetSeed(this js.Value, args []js.Value) any {
+	if err := setSeedParams.Check(args); err != nil {
+		return jz.Reject(jz.ToError(err))
+	}
+
+	var seed uint64
+	if err := jz.Unmarshal(args[0], &seed); err != nil {
+		return jz.Reject(jz.ToError(err))
+	}
+
+	l.rand_src.Seed(seed)
+	return jz.Resolve(js.Undefined())
+}
+
+var setTimeZoneParams = jz.Params{
+	{Name: "name", Kind: jz.KindString},
+}
+
+// JsSetTimeZone sets the location handlers get by [LocationFrom] to the one of given IANA name,
+// e.g. "Asia/Seoul".
+// [time.Local] is not changed, so neither are [time.Time.Local] and the times made by [time.Now].
+// The bridge needs the time zone database, e.g. by importing time/tzdata.
+//
+// Signature:
+//
+//	function(name: string): Promise<void>;
+func (l *Listener) JsSetTimeZone(this js.Value, args []js.Value) any {
+	if err := setTimeZoneParams.Check(args); err != nil {
+		return jz.Reject(jz.ToError(err))
+	}
+
+	name := args[0].String()
+	return l.scope.Promise(func() (js.Value, js.Value) {
+		// It may read the database from the file system, which waits for JS.
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			return js.Undefined(), jz.ToError(err)
+		}
+
+		l.location.Store(loc)
+		return js.Undefined(), js.Undefined()
+	})
+}
+
+// WithClock sets the clock handlers get by [ClockFrom].
+// The JS side can control the clock only if it is [FakeClock].
+// By default, it is [FakeClock] running along with the real time.
+func WithClock(c Clock) ListenOption {
+	return func(l *Listener) {
+		l.clock = c
+	}
+}
+
+// WithRandSeed seeds the generator handlers get by [RandFrom].
+// By default, it is seeded randomly.
+func WithRandSeed(seed uint64) ListenOption {
+	return func(l *Listener) {
+		l.rand_src.Seed(seed)
+	}
+}
+
+// WithLocation sets the location handlers get by [LocationFrom].
+// By default, it is [time.Local].
+// Only [LocationFrom] sees it; [time.Local], [time.Time.Local], and the times made by [time.Now]
+// stay in the local time zone of the process, so handlers must convert the times
+// with [time.Time.In] before formatting them.
+func WithLocation(loc *time.Location) ListenOption {
+	return func(l *Listener) {
+		l.location.Store(loc)
+	}
+}
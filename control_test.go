@@ -0,0 +1,151 @@
+package grpcwasm_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	grpcwasm "github.com/lesomnus/grpc-wasm"
+	"github.com/lesomnus/grpc-wasm/internal/echo"
+	"github.com/lesomnus/grpc-wasm/internal/js"
+	"github.com/lesomnus/grpc-wasm/internal/jz"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListener_JsClock(t *testing.T) {
+	t.Run("echo stamps the time of the bridge", withListener(func(ctx context.Context, x *require.Assertions, l *grpcwasm.Listener, conn *grpcwasm.Conn) {
+		sock := l.ToJsValue()
+
+		_, err_js := jz.Await(sock.Call("clock_freeze"))
+		x.True(err_js.IsUndefined())
+		_, err_js = jz.Await(sock.Call("clock_set", js.Global().Get("Date").New(epoch.UnixMilli())))
+		x.True(err_js.IsUndefined())
+
+		stamp := func() time.Time {
+			v, err_js := jsInvoke(x, conn, echo.EchoService_Once_FullMethodName, &echo.EchoRequest{}, nil)
+			x.True(err_js.IsUndefined())
+
+			res := echo.EchoResponse{}
+			x.NoError(protoUnmarshal(v.Get("response"), &res))
+			return res.GetDateCreated().AsTime()
+		}
+		x.Equal(epoch, stamp())
+
+		_, err_js = jz.Await(sock.Call("clock_advance", 1500))
+		x.True(err_js.IsUndefined())
+		x.Equal(epoch.Add(1500*time.Millisecond), stamp())
+
+		v, err_js := jz.Await(sock.Call("clock_now"))
+		x.True(err_js.IsUndefined())
+		x.True(v.Get("frozen").Bool())
+		x.Equal(float64(epoch.Add(1500*time.Millisecond).UnixMilli()), v.Get("now").Call("getTime").Float())
+
+		_, err_js = jz.Await(sock.Call("clock_resume"))
+		x.True(err_js.IsUndefined())
+		v, err_js = jz.Await(sock.Call("clock_now"))
+		x.True(err_js.IsUndefined())
+		x.False(v.Get("frozen").Bool())
+	}))
+	t.Run("real clock", withListener(func(ctx context.Context, x *require.Assertions, l *grpcwasm.Listener, conn *grpcwasm.Conn) {
+		_, err_js := jz.Await(l.ToJsValue().Call("clock_freeze"))
+		x.Contains(err_js.Get("message").String(), "clock is not controllable")
+	}, grpcwasm.WithClock(grpcwasm.RealClock())))
+	t.Run("wrong arguments", withListener(func(ctx context.Context, x *require.Assertions, l *grpcwasm.Listener, conn *grpcwasm.Conn) {
+		_, err_js := jz.Await(l.ToJsValue().Call("clock_advance", "Lebowski"))
+		x.Equal("argument", err_js.Get("kind").String())
+	}))
+}
+
+// randEchoServer responds with a random number from the generator of the bridge.
+type randEchoServer struct {
+	echo.UnimplementedEchoServiceServer
+}
+
+func (randEchoServer) Once(ctx context.Context, req *echo.EchoRequest) (*echo.EchoResponse, error) {
+	res := &echo.EchoResponse{}
+	res.SetMessage(fmt.Sprint(grpcwasm.RandFrom(ctx).Uint64()))
+	return res, nil
+}
+
+func TestListener_JsSetSeed(t *testing.T) {
+	x := require.New(t)
+
+	l := grpcwasm.NewListener(grpcwasm.WithRandSeed(42))
+	defer l.Close()
+
+	s := grpcwasm.NewServer()
+	echo.RegisterEchoServiceServer(s, randEchoServer{})
+	go s.Serve(l)
+	defer s.Stop()
+
+	conn, err := l.Dial()
+	x.NoError(err)
+	defer conn.Close()
+
+	roll := func() string {
+		v, err_js := jsInvoke(x, conn, echo.EchoService_Once_FullMethodName, &echo.EchoRequest{}, nil)
+		x.True(err_js.IsUndefined())
+
+		res := echo.EchoResponse{}
+		x.NoError(protoUnmarshal(v.Get("response"), &res))
+		return res.GetMessage()
+	}
+
+	expected := fmt.Sprint(grpcwasm.NewSeededSource(42).Uint64())
+	x.Equal(expected, roll())
+	x.NotEqual(expected, roll())
+
+	_, err_js := jz.Await(l.ToJsValue().Call("set_seed", 42))
+	x.True(err_js.IsUndefined())
+	x.Equal(expected, roll())
+}
+
+// zoneEchoServer responds with the name of the location of the bridge.
+type zoneEchoServer struct {
+	echo.UnimplementedEchoServiceServer
+}
+
+func (zoneEchoServer) Once(ctx context.Context, req *echo.EchoRequest) (*echo.EchoResponse, error) {
+	res := &echo.EchoResponse{}
+	res.SetMessage(grpcwasm.LocationFrom(ctx).String())
+	return res, nil
+}
+
+func TestListener_JsSetTimeZone(t *testing.T) {
+	x := require.New(t)
+
+	l := grpcwasm.NewListener(grpcwasm.WithLocation(time.UTC))
+	defer l.Close()
+
+	s := grpcwasm.NewServer()
+	echo.RegisterEchoServiceServer(s, zoneEchoServer{})
+	go s.Serve(l)
+	defer s.Stop()
+
+	conn, err := l.Dial()
+	x.NoError(err)
+	defer conn.Close()
+
+	zone := func() string {
+		v, err_js := jsInvoke(x, conn, echo.EchoService_Once_FullMethodName, &echo.EchoRequest{}, nil)
+		x.True(err_js.IsUndefined())
+
+		res := echo.EchoResponse{}
+		x.NoError(protoUnmarshal(v.Get("response"), &res))
+		return res.GetMessage()
+	}
+
+	local := time.Local
+	x.Equal("UTC", zone())
+
+	_, err_js := jz.Await(l.ToJsValue().Call("set_timezone", "Asia/Seoul"))
+	x.True(err_js.IsUndefined())
+	x.Equal("Asia/Seoul", zone())
+	x.Same(local, time.Local)
+
+	_, err_js = jz.Await(l.ToJsValue().Call("set_timezone", "Lebowski/Nowhere"))
+	x.False(err_js.IsUndefined())
+	x.Equal("Asia/Seoul", zone())
+}
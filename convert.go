@@ -1,62 +1,153 @@
-//go:build js && wasm
-
 package grpcwasm
 
 import (
-	"syscall/js"
+	"github.com/lesomnus/grpc-wasm/inspector"
+	"github.com/lesomnus/grpc-wasm/internal/js"
+	"github.com/lesomnus/grpc-wasm/internal/jz"
+	"google.golang.org/protobuf/encoding/protojson"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 )
 
-func statusToGo(v js.Value) *status.Status {
-	return status.New(
-		codes.Code(v.Get("code").Int()),
-		v.Get("message").String(),
-	)
+// rpcStatus is JS representation of [status.Status].
+//
+//	type RpcStatus = {
+//		code: number
+//		message: string
+//	}
+type rpcStatus struct {
+	Code    codes.Code `js:"code,required"`
+	Message string     `js:"message"`
 }
 
-func statusToJs(v *status.Status) js.Value {
-	return js.ValueOf(map[string]any{
-		"code":    js.ValueOf(int(v.Code())),
-		"message": js.ValueOf(v.Message()),
-	})
+func newRpcStatus(s *status.Status) rpcStatus {
+	return rpcStatus{
+		Code:    s.Code(),
+		Message: s.Message(),
+	}
+}
+
+func (s rpcStatus) Status() *status.Status {
+	return status.New(s.Code, s.Message)
 }
 
-// MetaFromJS does not check type of [src].
-// The [src] is expected to be:
+// rpcMeta is JS representation of [metadata.MD].
+// It is marshaled into an empty object even if it is nil
+// and its keys are normalized when it is unmarshaled.
 //
 //	type Metadata = {
-//		[key: string]: string[]
+//		[key: string]: string[] | undefined
 //	};
-func metaToGo(dst metadata.MD, src js.Value) {
-	ks := js.Global().Get("Object").Call("keys", src)
-
-	l := ks.Length()
-	for i := range l {
-		k := ks.Index(i).String()
-		a := src.Get(k)
-
-		l := a.Length()
-		vs := make([]string, l)
-		for j := range l {
-			vs[j] = a.Index(j).String()
-		}
+type rpcMeta metadata.MD
 
-		dst.Set(k, vs...)
+func (m rpcMeta) MarshalJS() (js.Value, error) {
+	if m == nil {
+		m = rpcMeta{}
 	}
+	return jz.Marshal(map[string][]string(m))
 }
 
-func metaToJs(v metadata.MD) js.Value {
-	md := js.ValueOf(map[string]any{})
-	for k, vs := range v {
-		a := js.ValueOf([]any{})
-		for i, v := range vs {
-			a.SetIndex(i, v)
+func (m *rpcMeta) UnmarshalJS(v js.Value) error {
+	if v.IsUndefined() || v.IsNull() {
+		return nil
+	}
+
+	vs := map[string][]string{}
+	if err := jz.Unmarshal(v, &vs); err != nil {
+		return err
+	}
+
+	md := metadata.MD{}
+	for k, v := range vs {
+		if v == nil {
+			continue
 		}
+		md.Set(k, v...)
+	}
+
+	*m = rpcMeta(md)
+	return nil
+}
+
+type rpcResult struct {
+	Header   rpcMeta   `js:"header"`
+	Trailer  rpcMeta   `js:"trailer"`
+	Response []byte    `js:"response"`
+	Status   rpcStatus `js:"status"`
+}
+
+type streamResult struct {
+	Done     bool       `js:"done"`
+	Response []byte     `js:"response,omitempty"`
+	Trailer  *rpcMeta   `js:"trailer,omitempty"`
+	Status   *rpcStatus `js:"status,omitempty"`
+}
+
+type histogramBucket struct {
+	Le    float64 `js:"le"`
+	Count uint64  `js:"count"`
+}
 
-		md.Set(k, a)
+type histogramView struct {
+	Buckets []histogramBucket `js:"buckets"`
+	Count   uint64            `js:"count"`
+	Sum     float64           `js:"sum"`
+}
+
+// methodMetricsView replaces the fields of [MethodMetrics]
+// that do not fit in JS as is.
+type methodMetricsView struct {
+	MethodMetrics
+
+	// Keyed by the name of the code.
+	Handled map[string]uint64 `js:"handled"`
+	Latency histogramView     `js:"latency"`
+}
+
+func metricsToJs(vs []MethodMetrics) (js.Value, error) {
+	methods := make([]methodMetricsView, len(vs))
+	for i, v := range vs {
+		handled := map[string]uint64{}
+		for c, n := range v.Handled {
+			handled[c.String()] = n
+		}
+
+		buckets := make([]histogramBucket, len(v.Latency.Buckets))
+		for j, le := range v.Latency.Buckets {
+			buckets[j] = histogramBucket{Le: le, Count: v.Latency.Counts[j]}
+		}
+
+		methods[i] = methodMetricsView{
+			MethodMetrics: v,
+
+			Handled: handled,
+			Latency: histogramView{
+				Buckets: buckets,
+				Count:   v.Latency.Count,
+				Sum:     v.Latency.Sum,
+			},
+		}
 	}
-	return md
+
+	return jz.Marshal(map[string]any{
+		"methods": methods,
+	})
+}
+
+func eventToJs(e *inspector.Event) (js.Value, error) {
+	return messageToJs(e)
+}
+
+// messageToJs converts the message into the object of its protobuf JSON mapping
+// with original field names.
+func messageToJs(m proto.Message) (js.Value, error) {
+	data, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(m)
+	if err != nil {
+		return js.Undefined(), err
+	}
+
+	return js.Global().Get("JSON").Call("parse", string(data)), nil
 }
@@ -0,0 +1,387 @@
+package grpcwasm
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+
+	"github.com/lesomnus/grpc-wasm/internal/js"
+	"github.com/lesomnus/grpc-wasm/internal/jz"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// ServerFactory makes a server to serve the calls from JS side.
+// The context is cancelled once the server is stopped,
+// so the state of the server can be discarded with it.
+// The servers should be made by [NewServer] so panics in the handlers are recovered.
+type ServerFactory func(ctx context.Context) (*grpc.Server, error)
+
+// serverPool runs the servers made by the factory.
+type serverPool struct {
+	l        *Listener
+	factory  ServerFactory
+	isolated bool
+
+	mu     sync.Mutex
+	slots  map[*serverSlot]struct{}
+	closed bool
+
+	// Goroutines serving or stopping the servers.
+	wg sync.WaitGroup
+}
+
+// start makes a server and serves it.
+func (p *serverPool) start() (*serverInstance, error) {
+	ctx, cancel := context.WithCancel(p.l.ctx)
+	s, err := p.factory(ctx)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	warnNotRecovered(s)
+
+	i := &serverInstance{
+		s:      s,
+		conns:  newConnQueue(),
+		cancel: cancel,
+	}
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		s.Serve(i.conns)
+	}()
+
+	return i, nil
+}
+
+func (p *serverPool) add(s *serverSlot) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return false
+	}
+	p.slots[s] = struct{}{}
+	return true
+}
+
+func (p *serverPool) remove(s *serverSlot) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.slots, s)
+}
+
+// isolate makes a server dedicated to a connection.
+func (p *serverPool) isolate() (*serverSlot, error) {
+	s := &serverSlot{p: p, front: bufconn.Listen(p.l.buf_size)}
+	if err := s.reset(context.Background()); err != nil {
+		s.close()
+		return nil, err
+	}
+	if !p.add(s) {
+		s.close()
+		return nil, net.ErrClosed
+	}
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		s.run(func() (net.Conn, error) {
+			return p.l.accept(s.front)
+		})
+	}()
+
+	return s, nil
+}
+
+// run serves until the listener is closed.
+func (p *serverPool) run() error {
+	if p.isolated {
+		// Every connection dials its own server so nothing comes here.
+		for {
+			c, err := p.l.Accept()
+			if err != nil {
+				break
+			}
+			c.Close()
+		}
+	} else {
+		s := &serverSlot{p: p}
+		if err := s.reset(context.Background()); err != nil {
+			return err
+		}
+		if p.add(s) {
+			s.run(p.l.Accept)
+		}
+	}
+
+	p.close()
+	p.wg.Wait()
+	return nil
+}
+
+// reset replaces every server with a new one.
+func (p *serverPool) reset(ctx context.Context) error {
+	p.mu.Lock()
+	slots := make([]*serverSlot, 0, len(p.slots))
+	for s := range p.slots {
+		slots = append(slots, s)
+	}
+	p.mu.Unlock()
+
+	errs := []error{}
+	for _, s := range slots {
+		if err := s.reset(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (p *serverPool) close() {
+	p.mu.Lock()
+	p.closed = true
+	slots := p.slots
+	p.slots = map[*serverSlot]struct{}{}
+	p.mu.Unlock()
+
+	for s := range slots {
+		s.close()
+	}
+}
+
+// serverSlot routes the connections to its current server,
+// which is replaced on reset.
+type serverSlot struct {
+	p *serverPool
+	// Set if the slot has its own listener, i.e. in isolation mode.
+	front *bufconn.Listener
+
+	mu     sync.Mutex
+	cur    *serverInstance
+	closed bool
+}
+
+// run routes the connections accepted to the current server until accept fails.
+func (s *serverSlot) run(accept func() (net.Conn, error)) {
+	for {
+		c, err := accept()
+		if err != nil {
+			return
+		}
+
+		s.route(c)
+	}
+}
+
+func (s *serverSlot) route(c net.Conn) {
+	for {
+		s.mu.Lock()
+		i := s.cur
+		s.mu.Unlock()
+		if i == nil {
+			c.Close()
+			return
+		}
+		if i.conns.push(c) {
+			return
+		}
+
+		// The server is stopped; retry with the one that replaced it, if any.
+		s.mu.Lock()
+		stale := s.cur != i
+		s.mu.Unlock()
+		if !stale {
+			c.Close()
+			return
+		}
+	}
+}
+
+// reset makes a new server and stops the current one after it takes over the new connections.
+// The current server is stopped gracefully until the context ends.
+func (s *serverSlot) reset(ctx context.Context) error {
+	i, err := s.p.start()
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		i.stop(ctx)
+		return net.ErrClosed
+	}
+	prev := s.cur
+	s.cur = i
+	s.mu.Unlock()
+
+	if prev != nil {
+		prev.stop(ctx)
+	}
+	return nil
+}
+
+// close stops the server in background up to the shutdown timeout.
+func (s *serverSlot) close() {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.closed = true
+	i := s.cur
+	s.cur = nil
+	s.mu.Unlock()
+
+	if s.front != nil {
+		s.front.Close()
+	}
+	s.p.remove(s)
+	if i == nil {
+		return
+	}
+
+	s.p.wg.Add(1)
+	go func() {
+		defer s.p.wg.Done()
+
+		ctx, cancel := s.p.l.shutdownContext()
+		defer cancel()
+		i.stop(ctx)
+	}()
+}
+
+type serverInstance struct {
+	s      *grpc.Server
+	conns  *connQueue
+	cancel context.CancelFunc
+}
+
+// stop stops the server gracefully, or forcibly once the context ends.
+func (i *serverInstance) stop(ctx context.Context) {
+	done := make(chan struct{})
+	go func() {
+		i.s.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		i.s.Stop()
+		<-done
+	}
+	i.cancel()
+}
+
+// connQueue is a listener that accepts the connections routed to it.
+type connQueue struct {
+	c    chan net.Conn
+	done chan struct{}
+	once sync.Once
+}
+
+func newConnQueue() *connQueue {
+	return &connQueue{
+		c:    make(chan net.Conn),
+		done: make(chan struct{}),
+	}
+}
+
+func (q *connQueue) Accept() (net.Conn, error) {
+	select {
+	case c := <-q.c:
+		return c, nil
+	case <-q.done:
+		return nil, net.ErrClosed
+	}
+}
+
+func (q *connQueue) Close() error {
+	q.once.Do(func() { close(q.done) })
+	return nil
+}
+
+func (q *connQueue) Addr() net.Addr {
+	return addr{}
+}
+
+// push hands the connection over to the server.
+// It returns false if the queue is closed.
+func (q *connQueue) push(c net.Conn) bool {
+	select {
+	case q.c <- c:
+		return true
+	case <-q.done:
+		return false
+	}
+}
+
+// Run serves the servers made by the factory set by [WithServerFactory] until the listener is closed.
+func (l *Listener) Run() error {
+	if l.servers == nil {
+		return errors.New("server factory is not set")
+	}
+
+	return l.servers.run()
+}
+
+// Reset replaces the servers made by the factory set by [WithServerFactory] with new ones.
+// Calls in flight on the previous servers are drained until the context ends and then cancelled.
+// Connections are kept and their next calls go to the new servers.
+func (l *Listener) Reset(ctx context.Context) error {
+	if l.servers == nil {
+		return errors.New("server factory is not set")
+	}
+
+	return l.servers.reset(ctx)
+}
+
+// JsReset replaces the servers with new ones. See [Listener.Reset].
+// Calls in flight are drained up to the timeout set by [WithShutdownTimeout].
+//
+// Signature:
+//
+//	function(): Promise<void>;
+func (l *Listener) JsReset(this js.Value, args []js.Value) any {
+	if l.servers == nil {
+		return jz.Reject(jz.Error("server factory is not set"))
+	}
+
+	return l.scope.Promise(func() (js.Value, js.Value) {
+		ctx, cancel := l.shutdownContext()
+		defer cancel()
+
+		if err := l.Reset(ctx); err != nil {
+			return js.Undefined(), jz.ToError(err)
+		}
+		return js.Undefined(), js.Undefined()
+	})
+}
+
+// WithServerFactory makes the listener serve the servers made by given factory.
+// Serve them by [Listener.Run], or use [ServeFactory].
+func WithServerFactory(f ServerFactory) ListenOption {
+	return func(l *Listener) {
+		l.servers = &serverPool{
+			l:       l,
+			factory: f,
+			slots:   map[*serverSlot]struct{}{},
+		}
+	}
+}
+
+// WithIsolatedServers makes every connection dialed by the listener have its own server,
+// so the connections do not share the state of the server.
+// The server is stopped when the connection is closed.
+// It has no effect without [WithServerFactory].
+func WithIsolatedServers() ListenOption {
+	return func(l *Listener) {
+		l.isolated_servers = true
+	}
+}
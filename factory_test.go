@@ -0,0 +1,174 @@
+package grpcwasm_test
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	grpcwasm "github.com/lesomnus/grpc-wasm"
+	"github.com/lesomnus/grpc-wasm/internal/echo"
+	"github.com/lesomnus/grpc-wasm/internal/js"
+	"github.com/lesomnus/grpc-wasm/internal/jz"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+// instanceEchoServer responds with the number of the server instance.
+type instanceEchoServer struct {
+	echo.UnimplementedEchoServiceServer
+	n int64
+}
+
+func (s instanceEchoServer) Once(context.Context, *echo.EchoRequest) (*echo.EchoResponse, error) {
+	res := &echo.EchoResponse{}
+	res.SetMessage(fmt.Sprint(s.n))
+	return res, nil
+}
+
+// instanceFactory makes servers numbered from 1.
+// The contexts given to the factory are sent to the channel.
+func instanceFactory() (grpcwasm.ServerFactory, <-chan context.Context) {
+	n := atomic.Int64{}
+	ctxs := make(chan context.Context, 16)
+	return func(ctx context.Context) (*grpc.Server, error) {
+		ctxs <- ctx
+
+		s := grpcwasm.NewServer()
+		echo.RegisterEchoServiceServer(s, instanceEchoServer{n: n.Add(1)})
+		return s, nil
+	}, ctxs
+}
+
+func instanceOf(x *require.Assertions, conn *grpcwasm.Conn) string {
+	v, err_js := jsInvoke(x, conn, echo.EchoService_Once_FullMethodName, &echo.EchoRequest{}, nil)
+	x.True(err_js.IsUndefined())
+	x.Equal(0, v.Get("status").Get("code").Int())
+
+	res := echo.EchoResponse{}
+	x.NoError(protoUnmarshal(v.Get("response"), &res))
+	return res.GetMessage()
+}
+
+func TestListener_Reset(t *testing.T) {
+	x := require.New(t)
+
+	f, ctxs := instanceFactory()
+	l := grpcwasm.NewListener(grpcwasm.WithServerFactory(f))
+
+	done := make(chan error)
+	go func() { done <- l.Run() }()
+	defer func() {
+		l.Close()
+		x.NoError(<-done)
+	}()
+
+	conn, err := l.Dial()
+	x.NoError(err)
+	defer conn.Close()
+
+	x.Equal("1", instanceOf(x, conn))
+	ctx := <-ctxs
+
+	sock := l.ToJsValue()
+	_, err_js := jz.Await(sock.Call("reset"))
+	x.True(err_js.IsUndefined())
+	x.ErrorIs(ctx.Err(), context.Canceled)
+
+	x.Equal("2", instanceOf(x, conn))
+}
+
+func TestListener_JsReset(t *testing.T) {
+	t.Run("without factory", func(t *testing.T) {
+		x := require.New(t)
+
+		l := grpcwasm.NewListener()
+		defer l.Close()
+
+		_, err_js := jz.Await(l.JsReset(js.Undefined(), nil).(js.Value))
+		x.False(err_js.IsUndefined())
+		x.Contains(err_js.Get("message").String(), "server factory is not set")
+	})
+}
+
+func TestWithIsolatedServers(t *testing.T) {
+	x := require.New(t)
+
+	f, ctxs := instanceFactory()
+	l := grpcwasm.NewListener(
+		grpcwasm.WithServerFactory(f),
+		grpcwasm.WithIsolatedServers(),
+	)
+
+	done := make(chan error)
+	go func() { done <- l.Run() }()
+	defer func() {
+		l.Close()
+		x.NoError(<-done)
+	}()
+
+	conn1, err := l.Dial()
+	x.NoError(err)
+	defer conn1.Close()
+	ctx1 := <-ctxs
+
+	conn2, err := l.Dial()
+	x.NoError(err)
+	defer conn2.Close()
+
+	x.Equal("1", instanceOf(x, conn1))
+	x.Equal("2", instanceOf(x, conn2))
+	x.Equal("1", instanceOf(x, conn1))
+
+	// Each connection gets its own new server.
+	x.NoError(l.Reset(t.Context()))
+	n1 := instanceOf(x, conn1)
+	n2 := instanceOf(x, conn2)
+	x.ElementsMatch([]string{"3", "4"}, []string{n1, n2})
+	x.ErrorIs(ctx1.Err(), context.Canceled)
+
+	// Contexts are sent in the order of the instance numbers.
+	<-ctxs
+	ctx3 := <-ctxs
+	ctx4 := <-ctxs
+	if n1 == "4" {
+		ctx3 = ctx4
+	}
+
+	conn1.Close()
+	select {
+	case <-ctx3.Done():
+	case <-time.After(time.Second):
+		x.Fail("server is not stopped on close")
+	}
+	x.Equal(n2, instanceOf(x, conn2))
+}
+
+func TestWithIsolatedServers_BeforeFactory(t *testing.T) {
+	x := require.New(t)
+
+	f, _ := instanceFactory()
+	l := grpcwasm.NewListener(
+		grpcwasm.WithIsolatedServers(),
+		grpcwasm.WithServerFactory(f),
+	)
+
+	done := make(chan error)
+	go func() { done <- l.Run() }()
+	defer func() {
+		l.Close()
+		x.NoError(<-done)
+	}()
+
+	conn1, err := l.Dial()
+	x.NoError(err)
+	defer conn1.Close()
+
+	conn2, err := l.Dial()
+	x.NoError(err)
+	defer conn2.Close()
+
+	x.Equal("1", instanceOf(x, conn1))
+	x.Equal("2", instanceOf(x, conn2))
+}
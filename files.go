@@ -0,0 +1,378 @@
+package grpcwasm
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lesomnus/grpc-wasm/internal/js"
+	"github.com/lesomnus/grpc-wasm/internal/jz"
+)
+
+var (
+	_ fs.ReadFileFS = (*MemFS)(nil)
+	_ fs.ReadDirFS  = (*MemFS)(nil)
+	_ fs.StatFS     = (*MemFS)(nil)
+)
+
+// MemFS is a read-only file system of files held in memory.
+// Its files can be replaced while it is used; files already opened keep their content.
+// Directories are implied by the paths of the files.
+type MemFS struct {
+	mu sync.RWMutex
+	// Keyed by the paths [fs.ValidPath] accepts.
+	files map[string]*memFile
+}
+
+type memFile struct {
+	data     []byte
+	mod_time time.Time
+}
+
+func NewMemFS() *MemFS {
+	return &MemFS{files: map[string]*memFile{}}
+}
+
+// cleanPath returns the path as [fs.ValidPath] wants, accepting a leading slash.
+func cleanPath(name string) string {
+	p := path.Clean("/" + name)[1:]
+	if p == "" {
+		return "."
+	}
+	return p
+}
+
+func (f *MemFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	if file, ok := f.files[name]; ok {
+		return &openMemFile{
+			Reader: bytes.NewReader(file.data),
+			info:   file.info(name),
+		}, nil
+	}
+	entries, ok := f.readDir(name)
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return &openMemDir{info: memDirInfo(name), entries: entries}, nil
+}
+
+func (f *MemFS) ReadFile(name string) ([]byte, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrInvalid}
+	}
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	file, ok := f.files[name]
+	if !ok {
+		if _, ok := f.readDir(name); ok {
+			return nil, &fs.PathError{Op: "read", Path: name, Err: errors.New("is a directory")}
+		}
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrNotExist}
+	}
+	return bytes.Clone(file.data), nil
+}
+
+func (f *MemFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	entries, ok := f.readDir(name)
+	if !ok {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	return entries, nil
+}
+
+func (f *MemFS) Stat(name string) (fs.FileInfo, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrInvalid}
+	}
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	if file, ok := f.files[name]; ok {
+		return file.info(name), nil
+	}
+	if _, ok := f.readDir(name); !ok {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+	}
+	return memDirInfo(name), nil
+}
+
+// readDir returns the entries of the directory in the order of their names,
+// or false if there is no such directory.
+// The root always exists.
+func (f *MemFS) readDir(name string) ([]fs.DirEntry, bool) {
+	prefix := name + "/"
+	if name == "." {
+		prefix = ""
+	}
+
+	infos := map[string]fs.FileInfo{}
+	for k, file := range f.files {
+		rest, ok := strings.CutPrefix(k, prefix)
+		if !ok {
+			continue
+		}
+		if child, _, ok := strings.Cut(rest, "/"); ok {
+			infos[child] = memDirInfo(child)
+		} else {
+			infos[child] = file.info(child)
+		}
+	}
+	if len(infos) == 0 && name != "." {
+		return nil, false
+	}
+
+	entries := make([]fs.DirEntry, 0, len(infos))
+	for _, child := range slices.Sorted(maps.Keys(infos)) {
+		entries = append(entries, fs.FileInfoToDirEntry(infos[child]))
+	}
+	return entries, true
+}
+
+// memFileInfo describes a file or a directory of [MemFS].
+type memFileInfo struct {
+	name     string
+	size     int64
+	mode     fs.FileMode
+	mod_time time.Time
+}
+
+func (file *memFile) info(name string) memFileInfo {
+	return memFileInfo{name: path.Base(name), size: int64(len(file.data)), mode: 0o444, mod_time: file.mod_time}
+}
+
+func memDirInfo(name string) memFileInfo {
+	return memFileInfo{name: path.Base(name), mode: fs.ModeDir | 0o555}
+}
+
+func (i memFileInfo) Name() string       { return i.name }
+func (i memFileInfo) Size() int64        { return i.size }
+func (i memFileInfo) Mode() fs.FileMode  { return i.mode }
+func (i memFileInfo) ModTime() time.Time { return i.mod_time }
+func (i memFileInfo) IsDir() bool        { return i.mode.IsDir() }
+func (i memFileInfo) Sys() any           { return nil }
+
+type openMemFile struct {
+	*bytes.Reader
+	info memFileInfo
+}
+
+func (f *openMemFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *openMemFile) Close() error               { return nil }
+
+type openMemDir struct {
+	info    memFileInfo
+	entries []fs.DirEntry
+	// Entries already read by ReadDir.
+	offset int
+}
+
+func (d *openMemDir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *openMemDir) Close() error               { return nil }
+
+func (d *openMemDir) Read(b []byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.name, Err: errors.New("is a directory")}
+}
+
+func (d *openMemDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	rest := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return rest, nil
+	}
+	if len(rest) == 0 {
+		return nil, io.EOF
+	}
+	rest = rest[:min(n, len(rest))]
+	d.offset += len(rest)
+	return rest, nil
+}
+
+// toMemFiles returns the files keyed by slash-separated paths as [MemFS] holds them.
+func toMemFiles(files map[string][]byte) map[string]*memFile {
+	now := time.Now()
+	m := map[string]*memFile{}
+	for name, data := range files {
+		m[cleanPath(name)] = &memFile{data: data, mod_time: now}
+	}
+	return m
+}
+
+// Put adds the files keyed by slash-separated paths, replacing the ones of the same path.
+// A leading slash of the paths is ignored.
+func (f *MemFS) Put(files map[string][]byte) {
+	next := toMemFiles(files)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	maps.Copy(f.files, next)
+}
+
+// PutZip adds the files in given zip archive as [MemFS.Put] does.
+func (f *MemFS) PutZip(data []byte) error {
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return fmt.Errorf("read zip: %w", err)
+	}
+
+	files := map[string][]byte{}
+	for _, entry := range r.File {
+		if entry.FileInfo().IsDir() {
+			continue
+		}
+
+		rc, err := entry.Open()
+		if err != nil {
+			return fmt.Errorf("open %s: %w", entry.Name, err)
+		}
+		b, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return fmt.Errorf("read %s: %w", entry.Name, err)
+		}
+		files[entry.Name] = b
+	}
+	f.Put(files)
+	return nil
+}
+
+// Remove removes the files of given paths and the files under them.
+func (f *MemFS) Remove(names ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, name := range names {
+		p := cleanPath(name)
+		maps.DeleteFunc(f.files, func(k string, _ *memFile) bool {
+			return p == "." || k == p || strings.HasPrefix(k, p+"/")
+		})
+	}
+}
+
+// Replace replaces all the files with given files as [MemFS.Put] does.
+func (f *MemFS) Replace(files map[string][]byte) {
+	next := toMemFiles(files)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.files = next
+}
+
+var openFiles = sync.OnceValue(func() *MemFS {
+	f := NewMemFS()
+
+	files := map[string][]byte{}
+	if err := jz.Unmarshal(js.Global().Get("grpc_wasm_files"), &files); err != nil {
+		log.Printf("grpcwasm: files given to open(): %v", err)
+	}
+	f.Put(files)
+
+	archives := [][]byte{}
+	if err := jz.Unmarshal(js.Global().Get("grpc_wasm_archives"), &archives); err != nil {
+		log.Printf("grpcwasm: archives given to open(): %v", err)
+	}
+	for _, data := range archives {
+		if err := f.PutZip(data); err != nil {
+			log.Printf("grpcwasm: archives given to open(): %v", err)
+		}
+	}
+
+	return f
+})
+
+// Files returns the file system of the files the JS side gave to open(),
+// including the ones in the zip archives and the ones fetched from URLs.
+// The JS side can replace them while the bridge runs, e.g. between tests.
+// Use it with package io/fs, or mount it by [MountFS] for package os.
+func Files() *MemFS {
+	return openFiles()
+}
+
+// WithFiles sets the file system the JS side replaces the files of.
+// By default, it is [Files].
+func WithFiles(f *MemFS) ListenOption {
+	return func(l *Listener) {
+		l.files = f
+	}
+}
+
+func (l *Listener) memFS() *MemFS {
+	if l.files != nil {
+		return l.files
+	}
+	return Files()
+}
+
+var putFilesParams = jz.Params{
+	{Name: "files", Kind: jz.KindObject},
+}
+
+// JsPutFiles adds the files to the file system set by [WithFiles],
+// replacing the ones of the same path.
+//
+// Signature:
+//
+//	function(files: Record<string, Uint8Array>): Promise<void>;
+func (l *Listener) JsPutFiles(this js.Value, args []js.Value) any {
+	if err := putFilesParams.Check(args); err != nil {
+		return jz.Reject(jz.ToError(err))
+	}
+
+	files := map[string][]byte{}
+	if err := jz.Unmarshal(args[0], &files); err != nil {
+		return jz.Reject(jz.ToError(err))
+	}
+	l.memFS().Put(files)
+	return jz.Resolve(js.Undefined())
+}
+
+var removeFilesParams = jz.Params{
+	{Name: "paths", Kind: jz.KindArray},
+}
+
+// JsRemoveFiles removes the files of given paths and the files under them
+// from the file system set by [WithFiles]. "/" removes every file.
+//
+// Signature:
+//
+//	function(paths: string[]): Promise<void>;
+func (l *Listener) JsRemoveFiles(this js.Value, args []js.Value) any {
+	if err := removeFilesParams.Check(args); err != nil {
+		return jz.Reject(jz.ToError(err))
+	}
+
+	paths := []string{}
+	if err := jz.Unmarshal(args[0], &paths); err != nil {
+		return jz.Reject(jz.ToError(err))
+	}
+	l.memFS().Remove(paths...)
+	return jz.Resolve(js.Undefined())
+}
@@ -0,0 +1,176 @@
+package grpcwasm_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"testing"
+	"testing/fstest"
+
+	grpcwasm "github.com/lesomnus/grpc-wasm"
+	"github.com/lesomnus/grpc-wasm/internal/js"
+	"github.com/lesomnus/grpc-wasm/internal/jz"
+	"github.com/stretchr/testify/require"
+)
+
+func zipOf(x *require.Assertions, files map[string]string) []byte {
+	b := &bytes.Buffer{}
+	w := zip.NewWriter(b)
+	for name, data := range files {
+		f, err := w.Create(name)
+		x.NoError(err)
+		_, err = f.Write([]byte(data))
+		x.NoError(err)
+	}
+	x.NoError(w.Close())
+	return b.Bytes()
+}
+
+func TestMemFS(t *testing.T) {
+	t.Run("fs", func(t *testing.T) {
+		x := require.New(t)
+
+		f := grpcwasm.NewMemFS()
+		f.Put(map[string][]byte{
+			"/seed.json":       []byte(`{}`),
+			"images/a.png":     {0x89, 'P', 'N', 'G'},
+			"images/sub/b.png": {0x89, 'P', 'N', 'G'},
+		})
+		x.NoError(fstest.TestFS(f, "seed.json", "images/a.png", "images/sub/b.png"))
+
+		entries, err := fs.ReadDir(f, "images")
+		x.NoError(err)
+		x.Len(entries, 2)
+		x.Equal("a.png", entries[0].Name())
+		x.True(entries[1].IsDir())
+	})
+	t.Run("replace files", func(t *testing.T) {
+		x := require.New(t)
+
+		f := grpcwasm.NewMemFS()
+		f.Put(map[string][]byte{"a": []byte("foo"), "dir/b": []byte("bar"), "dir/c": []byte("baz")})
+
+		opened, err := f.Open("a")
+		x.NoError(err)
+		defer opened.Close()
+
+		f.Put(map[string][]byte{"a": []byte("qux")})
+		data, err := fs.ReadFile(f, "a")
+		x.NoError(err)
+		x.Equal("qux", string(data))
+
+		// Opened file keeps its content.
+		b := make([]byte, 3)
+		_, err = opened.Read(b)
+		x.NoError(err)
+		x.Equal("foo", string(b))
+
+		f.Remove("dir")
+		_, err = fs.Stat(f, "dir/b")
+		x.ErrorIs(err, fs.ErrNotExist)
+		_, err = fs.Stat(f, "a")
+		x.NoError(err)
+
+		f.Replace(map[string][]byte{"d": []byte("new")})
+		_, err = fs.Stat(f, "a")
+		x.ErrorIs(err, fs.ErrNotExist)
+		_, err = fs.Stat(f, "d")
+		x.NoError(err)
+	})
+	t.Run("zip", func(t *testing.T) {
+		x := require.New(t)
+
+		f := grpcwasm.NewMemFS()
+		x.NoError(f.PutZip(zipOf(x, map[string]string{"seed/users.json": "[]", "seed/posts.json": "{}"})))
+
+		data, err := fs.ReadFile(f, "seed/users.json")
+		x.NoError(err)
+		x.Equal("[]", string(data))
+
+		x.ErrorContains(f.PutZip([]byte("not a zip")), "read zip")
+	})
+}
+
+func TestFiles(t *testing.T) {
+	x := require.New(t)
+
+	files := js.Global().Get("Object").New()
+	files.Set("/seed.json", jz.BytesToJs([]byte(`{"users":[]}`)))
+	archives := js.Global().Get("Array").New()
+	archives.Call("push", jz.BytesToJs(zipOf(x, map[string]string{"images/a.png": "PNG"})))
+
+	js.Global().Set("grpc_wasm_files", files)
+	js.Global().Set("grpc_wasm_archives", archives)
+	defer js.Global().Delete("grpc_wasm_files")
+	defer js.Global().Delete("grpc_wasm_archives")
+
+	data, err := fs.ReadFile(grpcwasm.Files(), "seed.json")
+	x.NoError(err)
+	x.Equal(`{"users":[]}`, string(data))
+
+	data, err = fs.ReadFile(grpcwasm.Files(), "images/a.png")
+	x.NoError(err)
+	x.Equal("PNG", string(data))
+}
+
+func TestListener_JsFiles(t *testing.T) {
+	f := grpcwasm.NewMemFS()
+	withListener(func(ctx context.Context, x *require.Assertions, l *grpcwasm.Listener, conn *grpcwasm.Conn) {
+		sock := l.ToJsValue()
+
+		files := js.Global().Get("Object").New()
+		files.Set("a.txt", jz.BytesToJs([]byte("foo")))
+		files.Set("dir/b.txt", jz.BytesToJs([]byte("bar")))
+		_, err_js := jz.Await(sock.Call("put_files", files))
+		x.True(err_js.IsUndefined())
+
+		data, err := fs.ReadFile(f, "dir/b.txt")
+		x.NoError(err)
+		x.Equal("bar", string(data))
+
+		_, err_js = jz.Await(sock.Call("remove_files", []any{"/dir"}))
+		x.True(err_js.IsUndefined())
+		_, err = fs.Stat(f, "dir/b.txt")
+		x.ErrorIs(err, fs.ErrNotExist)
+		_, err = fs.Stat(f, "a.txt")
+		x.NoError(err)
+
+		_, err_js = jz.Await(sock.Call("put_files", "foo"))
+		x.Equal("argument", err_js.Get("kind").String())
+	}, grpcwasm.WithFiles(f))(t)
+}
+
+func TestMountFS(t *testing.T) {
+	x := require.New(t)
+
+	f := grpcwasm.NewMemFS()
+	f.Put(map[string][]byte{"seed.json": []byte(`{}`), "images/a.png": []byte("PNG")})
+
+	err := grpcwasm.MountFS("/grpc-wasm-fixtures", f)
+	if errors.Is(err, errors.ErrUnsupported) {
+		t.Skip("mount is supported only in js/wasm")
+	}
+	x.NoError(err)
+
+	data, err := os.ReadFile("/grpc-wasm-fixtures/seed.json")
+	x.NoError(err)
+	x.Equal(`{}`, string(data))
+
+	entries, err := os.ReadDir("/grpc-wasm-fixtures/images")
+	x.NoError(err)
+	x.Len(entries, 1)
+	x.Equal("a.png", entries[0].Name())
+
+	_, err = os.Stat("/grpc-wasm-fixtures/none")
+	x.ErrorIs(err, fs.ErrNotExist)
+
+	err = os.WriteFile("/grpc-wasm-fixtures/new.json", nil, 0o644)
+	x.Error(err)
+
+	// Files out of the mount are not affected.
+	_, err = os.Stat(".")
+	x.NoError(err)
+}
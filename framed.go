@@ -0,0 +1,364 @@
+package grpcwasm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/lesomnus/grpc-wasm/framing"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// ServeFramed serves given server to the host speaking the protocol of package [framing],
+// reading frames from r and writing frames to w, until r ends or the context ends.
+// The server is served on a [Listener] made with given options, so the handlers get
+// what the listener provides, e.g. [ClockFrom] and [KVFrom], as they do in JS.
+// Calls in flight are cancelled when it returns, but the server is not stopped.
+func ServeFramed(ctx context.Context, s *grpc.Server, r io.Reader, w io.Writer, opts ...ListenOption) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	l := NewListener(opts...)
+	defer l.Close()
+	go s.Serve(l)
+
+	f := &framedSession{
+		ctx: ctx,
+		l:   l,
+		w:   w,
+
+		conns: map[uint32]*Conn{},
+		calls: map[uint32]*framedCall{},
+	}
+	defer f.close()
+
+	frames := make(chan *framing.Frame)
+	errs := make(chan error, 1)
+	go func() {
+		defer close(frames)
+		for {
+			frame, err := framing.Read(r)
+			if err != nil {
+				errs <- err
+				return
+			}
+			select {
+			case frames <- frame:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case frame, ok := <-frames:
+			if !ok {
+				err := <-errs
+				if errors.Is(err, io.EOF) {
+					return nil
+				}
+				return fmt.Errorf("read frame: %w", err)
+			}
+			if err := f.handle(frame); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+type framedSession struct {
+	ctx context.Context
+	l   *Listener
+
+	w_mu sync.Mutex
+	w    io.Writer
+
+	mu    sync.Mutex
+	conns map[uint32]*Conn
+	calls map[uint32]*framedCall
+
+	wg sync.WaitGroup
+}
+
+// framedCall is a unary call or a stream in flight.
+type framedCall struct {
+	ctx context.Context
+	// Cancels the call with the status given as the cause, if any.
+	cancel context.CancelCauseFunc
+	// Set for streams.
+	// The stream fails once it is full, as the server does not read.
+	sends chan []byte
+	// Closed to close the send direction of the stream.
+	close_send chan struct{}
+	close_once sync.Once
+}
+
+func (f *framedSession) write(frame *framing.Frame) error {
+	f.w_mu.Lock()
+	defer f.w_mu.Unlock()
+
+	return framing.Write(f.w, frame)
+}
+
+func (f *framedSession) fail(id uint32, format string, a ...any) error {
+	return f.write(&framing.Frame{
+		Type:  framing.TypeError,
+		ID:    id,
+		Error: fmt.Sprintf(format, a...),
+	})
+}
+
+func (f *framedSession) handle(frame *framing.Frame) error {
+	switch frame.Type {
+	case framing.TypeDial:
+		return f.dial(frame)
+	case framing.TypeClose:
+		f.mu.Lock()
+		conn, ok := f.conns[frame.ID]
+		delete(f.conns, frame.ID)
+		f.mu.Unlock()
+		if ok {
+			conn.Close()
+		}
+		return nil
+	case framing.TypeInvoke:
+		return f.invoke(frame)
+	case framing.TypeOpen:
+		return f.open(frame)
+	case framing.TypeSend:
+		call, ok := f.call(frame.ID)
+		if !ok || call.sends == nil {
+			return f.fail(frame.ID, "stream %d is not open", frame.ID)
+		}
+		select {
+		case <-call.close_send:
+			return f.fail(frame.ID, "stream %d is closed for sending", frame.ID)
+		case <-call.ctx.Done():
+			return nil
+		default:
+		}
+		// The read loop must not wait for the server to read,
+		// or frames cancelling the stream are not read either.
+		select {
+		case call.sends <- frame.Data:
+		default:
+			// The stream ends with the status.
+			call.cancel(status.Errorf(codes.ResourceExhausted, "stream %d overflowed its send buffer of %d messages", frame.ID, cap(call.sends)))
+		}
+		return nil
+	case framing.TypeCloseSend:
+		call, ok := f.call(frame.ID)
+		if !ok || call.sends == nil {
+			return f.fail(frame.ID, "stream %d is not open", frame.ID)
+		}
+		call.close_once.Do(func() { close(call.close_send) })
+		return nil
+	case framing.TypeCancel:
+		if call, ok := f.call(frame.ID); ok {
+			call.cancel(nil)
+		}
+		return nil
+	}
+
+	return f.fail(frame.ID, "unexpected frame %s", frame.Type)
+}
+
+func (f *framedSession) call(id uint32) (*framedCall, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	call, ok := f.calls[id]
+	return call, ok
+}
+
+func (f *framedSession) dial(frame *framing.Frame) error {
+	conn, err := f.l.Dial()
+	if err != nil {
+		return f.fail(frame.ID, "dial: %v", err)
+	}
+
+	f.mu.Lock()
+	prev, ok := f.conns[frame.ID]
+	f.conns[frame.ID] = conn
+	f.mu.Unlock()
+	if ok {
+		prev.Close()
+	}
+
+	return f.write(&framing.Frame{Type: framing.TypeDialed, ID: frame.ID})
+}
+
+// begin registers a call of given frame.
+func (f *framedSession) begin(frame *framing.Frame, call *framedCall) (*Conn, context.Context, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	conn, ok := f.conns[frame.Conn]
+	if !ok {
+		return nil, nil, fmt.Errorf("connection %d is not dialed", frame.Conn)
+	}
+	if _, ok := f.calls[frame.ID]; ok {
+		return nil, nil, fmt.Errorf("call %d is in flight", frame.ID)
+	}
+
+	ctx, cancel := context.WithCancelCause(f.ctx)
+	ctx = metadata.NewOutgoingContext(ctx, frame.Meta)
+	call.ctx = ctx
+	call.cancel = cancel
+	f.calls[frame.ID] = call
+	return conn, ctx, nil
+}
+
+func (f *framedSession) end(id uint32) {
+	f.mu.Lock()
+	call, ok := f.calls[id]
+	delete(f.calls, id)
+	f.mu.Unlock()
+	if ok {
+		call.cancel(nil)
+	}
+}
+
+func (f *framedSession) invoke(frame *framing.Frame) error {
+	call := &framedCall{}
+	conn, ctx, err := f.begin(frame, call)
+	if err != nil {
+		return f.fail(frame.ID, "%v", err)
+	}
+
+	f.wg.Add(1)
+	go func() {
+		defer f.wg.Done()
+		defer f.end(frame.ID)
+
+		var (
+			header  metadata.MD
+			trailer metadata.MD
+			out     []byte
+		)
+		err := conn.Invoke(ctx, frame.Method, frame.Data, &out, grpc.Header(&header), grpc.Trailer(&trailer))
+		f.write(&framing.Frame{
+			Type:    framing.TypeResult,
+			ID:      frame.ID,
+			Meta:    header,
+			Data:    out,
+			Trailer: trailer,
+			Status:  status.Convert(err),
+		})
+	}()
+
+	return nil
+}
+
+var framedStreamDesc = &grpc.StreamDesc{
+	ClientStreams: true,
+	ServerStreams: true,
+}
+
+func (f *framedSession) open(frame *framing.Frame) error {
+	call := &framedCall{
+		sends:      make(chan []byte, 16),
+		close_send: make(chan struct{}),
+	}
+	conn, ctx, err := f.begin(frame, call)
+	if err != nil {
+		return f.fail(frame.ID, "%v", err)
+	}
+
+	stream, err := conn.NewStream(ctx, framedStreamDesc, frame.Method)
+	if err != nil {
+		f.end(frame.ID)
+		return f.write(&framing.Frame{
+			Type:   framing.TypeEnd,
+			ID:     frame.ID,
+			Status: status.Convert(err),
+		})
+	}
+
+	f.wg.Add(2)
+	go func() {
+		defer f.wg.Done()
+
+		// Errors are reported by RecvMsg.
+		for {
+			select {
+			case data := <-call.sends:
+				if err := stream.SendMsg(data); err != nil {
+					return
+				}
+			case <-call.close_send:
+				// Send the messages queued before closing.
+				for {
+					select {
+					case data := <-call.sends:
+						if err := stream.SendMsg(data); err != nil {
+							return
+						}
+					default:
+						stream.CloseSend()
+						return
+					}
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	go func() {
+		defer f.wg.Done()
+		defer f.end(frame.ID)
+
+		if header, err := stream.Header(); err == nil && header != nil {
+			f.write(&framing.Frame{Type: framing.TypeHeader, ID: frame.ID, Meta: header})
+		}
+
+		var err error
+		for {
+			var data []byte
+			if err = stream.RecvMsg(&data); err != nil {
+				break
+			}
+			f.write(&framing.Frame{Type: framing.TypeMessage, ID: frame.ID, Data: data})
+		}
+		if errors.Is(err, io.EOF) {
+			err = nil
+		}
+		st := status.Convert(err)
+		if s, ok := cancelCause(ctx); ok {
+			st = s
+		}
+
+		f.write(&framing.Frame{
+			Type:    framing.TypeEnd,
+			ID:      frame.ID,
+			Trailer: stream.Trailer(),
+			Status:  st,
+		})
+	}()
+
+	return nil
+}
+
+// close cancels the calls in flight and closes the connections.
+func (f *framedSession) close() {
+	f.mu.Lock()
+	for _, call := range f.calls {
+		call.cancel(nil)
+	}
+	conns := f.conns
+	f.conns = map[uint32]*Conn{}
+	f.mu.Unlock()
+
+	for _, conn := range conns {
+		conn.Close()
+	}
+	f.wg.Wait()
+}
@@ -0,0 +1,220 @@
+package grpcwasm_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	grpcwasm "github.com/lesomnus/grpc-wasm"
+	"github.com/lesomnus/grpc-wasm/framing"
+	"github.com/lesomnus/grpc-wasm/internal/echo"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+// framedHost is the host side of [grpcwasm.ServeFramed].
+type framedHost struct {
+	x    *require.Assertions
+	w    io.WriteCloser
+	r    io.Reader
+	done chan error
+}
+
+func withFramedHost(f func(x *require.Assertions, h *framedHost), opts ...grpcwasm.ListenOption) func(t *testing.T) {
+	return func(t *testing.T) {
+		x := require.New(t)
+
+		s := grpcwasm.NewServer()
+		echo.RegisterEchoServiceServer(s, echo.EchoServer{})
+		defer s.Stop()
+
+		host_r, bridge_w := io.Pipe()
+		bridge_r, host_w := io.Pipe()
+		h := &framedHost{x: x, w: host_w, r: host_r, done: make(chan error, 1)}
+		go func() {
+			h.done <- grpcwasm.ServeFramed(context.Background(), s, bridge_r, bridge_w, opts...)
+			bridge_w.Close()
+		}()
+
+		f(x, h)
+
+		host_w.Close()
+		x.NoError(<-h.done)
+	}
+}
+
+func (h *framedHost) send(f *framing.Frame) {
+	h.x.NoError(framing.Write(h.w, f))
+}
+
+func (h *framedHost) recv() *framing.Frame {
+	f, err := framing.Read(h.r)
+	h.x.NoError(err)
+	return f
+}
+
+func (h *framedHost) dial(id uint32) {
+	h.send(&framing.Frame{Type: framing.TypeDial, ID: id})
+	f := h.recv()
+	h.x.Equal(framing.TypeDialed, f.Type)
+	h.x.Equal(id, f.ID)
+}
+
+func echoRequest(x *require.Assertions, msg string, shift int32) []byte {
+	req := &echo.EchoRequest{}
+	req.SetMessage(msg)
+	req.SetCircularShift(shift)
+	data, err := proto.Marshal(req)
+	x.NoError(err)
+	return data
+}
+
+func echoMessage(x *require.Assertions, data []byte) string {
+	res := &echo.EchoResponse{}
+	x.NoError(proto.Unmarshal(data, res))
+	return res.GetMessage()
+}
+
+func TestServeFramed(t *testing.T) {
+	t.Run("invoke", withFramedHost(func(x *require.Assertions, h *framedHost) {
+		h.dial(1)
+		h.send(&framing.Frame{
+			Type:   framing.TypeInvoke,
+			ID:     1,
+			Conn:   1,
+			Method: echo.EchoService_Once_FullMethodName,
+			Meta:   metadata.Pairs("foo", "bar"),
+			Data:   echoRequest(x, "Lebowski", 3),
+		})
+
+		f := h.recv()
+		x.Equal(framing.TypeResult, f.Type)
+		x.Equal(codes.OK, f.Status.Code())
+		x.Equal("skiLebow", echoMessage(x, f.Data))
+		x.Equal([]string{"header"}, f.Meta.Get("timing"))
+		x.Equal([]string{"trailer"}, f.Trailer.Get("timing"))
+	}))
+	t.Run("clock of the listener", withFramedHost(func(x *require.Assertions, h *framedHost) {
+		h.dial(1)
+		h.send(&framing.Frame{Type: framing.TypeInvoke, ID: 1, Conn: 1, Method: echo.EchoService_Once_FullMethodName, Data: echoRequest(x, "Lebowski", 0)})
+
+		f := h.recv()
+		x.Equal(framing.TypeResult, f.Type)
+		res := &echo.EchoResponse{}
+		x.NoError(proto.Unmarshal(f.Data, res))
+		x.Equal(epoch, res.GetDateCreated().AsTime())
+	}, grpcwasm.WithClock(grpcwasm.NewFakeClock(epoch))))
+	t.Run("invoke with error", withFramedHost(func(x *require.Assertions, h *framedHost) {
+		h.dial(1)
+
+		req := &echo.EchoRequest{}
+		req.SetStatus(echo.Status_builder{Code: int32(codes.NotFound), Message: "where's the money"}.Build())
+		data, err := proto.Marshal(req)
+		x.NoError(err)
+		h.send(&framing.Frame{Type: framing.TypeInvoke, ID: 1, Conn: 1, Method: echo.EchoService_Once_FullMethodName, Data: data})
+
+		f := h.recv()
+		x.Equal(framing.TypeResult, f.Type)
+		x.Equal(codes.NotFound, f.Status.Code())
+		x.Equal("where's the money", f.Status.Message())
+	}))
+	t.Run("stream", withFramedHost(func(x *require.Assertions, h *framedHost) {
+		h.dial(1)
+		h.send(&framing.Frame{Type: framing.TypeOpen, ID: 1, Conn: 1, Method: echo.EchoService_Live_FullMethodName})
+		h.send(&framing.Frame{Type: framing.TypeSend, ID: 1, Data: echoRequest(x, "Lebowski", 3)})
+		h.send(&framing.Frame{Type: framing.TypeSend, ID: 1, Data: echoRequest(x, "Lebowski", 1)})
+		h.send(&framing.Frame{Type: framing.TypeCloseSend, ID: 1})
+
+		msgs := []string{}
+		for {
+			f := h.recv()
+			x.Equal(uint32(1), f.ID)
+			if f.Type == framing.TypeHeader {
+				continue
+			}
+			if f.Type == framing.TypeEnd {
+				x.Equal(codes.OK, f.Status.Code())
+				break
+			}
+
+			x.Equal(framing.TypeMessage, f.Type)
+			msgs = append(msgs, echoMessage(x, f.Data))
+		}
+		x.Equal([]string{"skiLebow", "iLebowsk"}, msgs)
+	}))
+	t.Run("cancel", withFramedHost(func(x *require.Assertions, h *framedHost) {
+		h.dial(1)
+
+		req := &echo.EchoRequest{}
+		req.SetOverVoid(true)
+		data, err := proto.Marshal(req)
+		x.NoError(err)
+		h.send(&framing.Frame{Type: framing.TypeInvoke, ID: 1, Conn: 1, Method: echo.EchoService_Once_FullMethodName, Data: data})
+		h.send(&framing.Frame{Type: framing.TypeCancel, ID: 1})
+
+		f := h.recv()
+		x.Equal(framing.TypeResult, f.Type)
+		x.Equal(codes.Canceled, f.Status.Code())
+	}))
+	t.Run("handler not reading", withFramedHost(func(x *require.Assertions, h *framedHost) {
+		h.dial(1)
+
+		req := &echo.EchoRequest{}
+		req.SetOverVoid(true)
+		data, err := proto.Marshal(req)
+		x.NoError(err)
+		h.send(&framing.Frame{Type: framing.TypeOpen, ID: 1, Conn: 1, Method: echo.EchoService_Many_FullMethodName})
+
+		// Frames are written while the bridge responds, so responses are read concurrently.
+		sent := make(chan struct{})
+		go func() {
+			defer close(sent)
+			h.send(&framing.Frame{Type: framing.TypeSend, ID: 1, Data: data})
+			big := echoRequest(x, strings.Repeat("Lebowski", 1<<15), 0)
+			for range 64 {
+				h.send(&framing.Frame{Type: framing.TypeSend, ID: 1, Data: big})
+			}
+			h.send(&framing.Frame{Type: framing.TypeDial, ID: 2})
+		}()
+
+		// The stream ends with exactly one End frame; sends racing with it
+		// are reported as sends to a stream that is not open.
+		ends := []*framing.Frame{}
+		for {
+			f := h.recv()
+			if f.Type == framing.TypeDialed {
+				break
+			}
+			x.Equal(uint32(1), f.ID)
+			switch f.Type {
+			case framing.TypeEnd:
+				ends = append(ends, f)
+			case framing.TypeError:
+				x.NotEmpty(ends)
+				x.Contains(f.Error, "is not open")
+			}
+		}
+		<-sent
+		x.Len(ends, 1)
+		x.Equal(codes.ResourceExhausted, ends[0].Status.Code())
+		x.Contains(ends[0].Status.Message(), "overflowed")
+	}))
+	t.Run("not dialed", withFramedHost(func(x *require.Assertions, h *framedHost) {
+		h.send(&framing.Frame{Type: framing.TypeInvoke, ID: 1, Conn: 42, Method: echo.EchoService_Once_FullMethodName})
+
+		f := h.recv()
+		x.Equal(framing.TypeError, f.Type)
+		x.Equal(uint32(1), f.ID)
+		x.Contains(f.Error, "connection 42 is not dialed")
+	}))
+	t.Run("stream not open", withFramedHost(func(x *require.Assertions, h *framedHost) {
+		h.send(&framing.Frame{Type: framing.TypeSend, ID: 1})
+
+		f := h.recv()
+		x.Equal(framing.TypeError, f.Type)
+		x.Contains(f.Error, "stream 1 is not open")
+	}))
+}
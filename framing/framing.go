@@ -0,0 +1,361 @@
+// Package framing is the byte-stream protocol through which a host talks to a bridge
+// that has no JS runtime, e.g. the wasip1 build served over stdin and stdout.
+//
+// Every frame is prefixed by its length:
+//
+//	frame  = length(u32) type(u8) id(u32) body
+//	str    = length(u32) utf8
+//	bytes  = length(u32) data
+//	md     = count(u32) { key(str) count(u32) { value(str) }... }...
+//	status = code(u32) message(str) details(bytes)
+//
+// Integers are big-endian.
+// Details of a status are serialized google.rpc.Status which is empty if the status has no details.
+//
+// The host assigns the IDs of connections and calls.
+// Bodies by type are:
+//
+//	Dial       (host)
+//	Close      (host)
+//	Invoke     (host)  conn(u32) method(str) meta(md) request(bytes)
+//	Open       (host)  conn(u32) method(str) meta(md)
+//	Send       (host)  message(bytes)
+//	CloseSend  (host)
+//	Cancel     (host)
+//	Dialed     (bridge)
+//	Result     (bridge) header(md) response(bytes) trailer(md) status
+//	Header     (bridge) header(md)
+//	Message    (bridge) message(bytes)
+//	End        (bridge) trailer(md) status
+//	Error      (bridge) message(str)
+//
+// A unary call is answered by a Result.
+// A stream is answered by a Header if the server sends one, Messages, and an End.
+// Error reports a frame the bridge cannot process, e.g. one for a connection that is not dialed.
+package framing
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// MaxFrameSize is the largest frame [Read] accepts.
+const MaxFrameSize = 64 << 20
+
+type Type uint8
+
+const (
+	TypeDial      Type = 0x01
+	TypeClose     Type = 0x02
+	TypeInvoke    Type = 0x03
+	TypeOpen      Type = 0x04
+	TypeSend      Type = 0x05
+	TypeCloseSend Type = 0x06
+	TypeCancel    Type = 0x07
+
+	TypeDialed  Type = 0x81
+	TypeResult  Type = 0x83
+	TypeHeader  Type = 0x84
+	TypeMessage Type = 0x85
+	TypeEnd     Type = 0x86
+	TypeError   Type = 0x8F
+)
+
+func (t Type) String() string {
+	switch t {
+	case TypeDial:
+		return "Dial"
+	case TypeClose:
+		return "Close"
+	case TypeInvoke:
+		return "Invoke"
+	case TypeOpen:
+		return "Open"
+	case TypeSend:
+		return "Send"
+	case TypeCloseSend:
+		return "CloseSend"
+	case TypeCancel:
+		return "Cancel"
+	case TypeDialed:
+		return "Dialed"
+	case TypeResult:
+		return "Result"
+	case TypeHeader:
+		return "Header"
+	case TypeMessage:
+		return "Message"
+	case TypeEnd:
+		return "End"
+	case TypeError:
+		return "Error"
+	}
+	return fmt.Sprintf("Type(0x%02x)", uint8(t))
+}
+
+// Frame is a frame of any type.
+// Fields not in the body of the type are ignored.
+type Frame struct {
+	Type Type
+	// ID of the connection for Dial, Dialed and Close, otherwise ID of the call.
+	ID uint32
+
+	Conn   uint32
+	Method string
+	// Request metadata for Invoke and Open, header for Result and Header.
+	Meta    metadata.MD
+	Trailer metadata.MD
+	// Request for Invoke, response for Result, and message for Send and Message.
+	Data []byte
+	// Status for Result and End.
+	Status *status.Status
+	// Message for Error.
+	Error string
+}
+
+// Write writes the frame.
+func Write(w io.Writer, f *Frame) error {
+	b := &encoder{}
+	b.u8(uint8(f.Type))
+	b.u32(f.ID)
+
+	switch f.Type {
+	case TypeInvoke:
+		b.u32(f.Conn)
+		b.str(f.Method)
+		b.md(f.Meta)
+		b.bytes(f.Data)
+	case TypeOpen:
+		b.u32(f.Conn)
+		b.str(f.Method)
+		b.md(f.Meta)
+	case TypeSend, TypeMessage:
+		b.bytes(f.Data)
+	case TypeResult:
+		b.md(f.Meta)
+		b.bytes(f.Data)
+		b.md(f.Trailer)
+		if err := b.status(f.Status); err != nil {
+			return err
+		}
+	case TypeHeader:
+		b.md(f.Meta)
+	case TypeEnd:
+		b.md(f.Trailer)
+		if err := b.status(f.Status); err != nil {
+			return err
+		}
+	case TypeError:
+		b.str(f.Error)
+	}
+	if len(b.buf) > MaxFrameSize {
+		return fmt.Errorf("frame too large: %d bytes", len(b.buf))
+	}
+
+	_, err := w.Write(binary.BigEndian.AppendUint32(nil, uint32(len(b.buf))))
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b.buf)
+	return err
+}
+
+// Read reads a frame.
+// It returns [io.EOF] only if the stream ends at the frame boundary.
+func Read(r io.Reader) (*Frame, error) {
+	var size [4]byte
+	if _, err := io.ReadFull(r, size[:]); err != nil {
+		return nil, err
+	}
+	n := binary.BigEndian.Uint32(size[:])
+	if n > MaxFrameSize {
+		return nil, fmt.Errorf("frame too large: %d bytes", n)
+	}
+
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+
+	d := &decoder{buf: buf}
+	f := &Frame{
+		Type: Type(d.u8()),
+		ID:   d.u32(),
+	}
+	switch f.Type {
+	case TypeDial, TypeClose, TypeCloseSend, TypeCancel, TypeDialed:
+	case TypeInvoke:
+		f.Conn = d.u32()
+		f.Method = d.str()
+		f.Meta = d.md()
+		f.Data = d.bytes()
+	case TypeOpen:
+		f.Conn = d.u32()
+		f.Method = d.str()
+		f.Meta = d.md()
+	case TypeSend, TypeMessage:
+		f.Data = d.bytes()
+	case TypeResult:
+		f.Meta = d.md()
+		f.Data = d.bytes()
+		f.Trailer = d.md()
+		f.Status = d.status()
+	case TypeHeader:
+		f.Meta = d.md()
+	case TypeEnd:
+		f.Trailer = d.md()
+		f.Status = d.status()
+	case TypeError:
+		f.Error = d.str()
+	default:
+		return nil, fmt.Errorf("unknown frame type %s", f.Type)
+	}
+	if d.err != nil {
+		return nil, fmt.Errorf("malformed %s frame: %w", f.Type, d.err)
+	}
+	if len(d.buf) > 0 {
+		return nil, fmt.Errorf("malformed %s frame: %d trailing bytes", f.Type, len(d.buf))
+	}
+
+	return f, nil
+}
+
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) u8(v uint8) {
+	e.buf = append(e.buf, v)
+}
+
+func (e *encoder) u32(v uint32) {
+	e.buf = binary.BigEndian.AppendUint32(e.buf, v)
+}
+
+func (e *encoder) bytes(v []byte) {
+	e.u32(uint32(len(v)))
+	e.buf = append(e.buf, v...)
+}
+
+func (e *encoder) str(v string) {
+	e.u32(uint32(len(v)))
+	e.buf = append(e.buf, v...)
+}
+
+func (e *encoder) md(md metadata.MD) {
+	e.u32(uint32(len(md)))
+	for k, vs := range md {
+		e.str(k)
+		e.u32(uint32(len(vs)))
+		for _, v := range vs {
+			e.str(v)
+		}
+	}
+}
+
+func (e *encoder) status(s *status.Status) error {
+	e.u32(uint32(s.Code()))
+	e.str(s.Message())
+
+	var details []byte
+	if p := s.Proto(); len(p.GetDetails()) > 0 {
+		data, err := proto.Marshal(p)
+		if err != nil {
+			return fmt.Errorf("marshal status: %w", err)
+		}
+		details = data
+	}
+	e.bytes(details)
+	return nil
+}
+
+var errShort = errors.New("unexpected end of frame")
+
+type decoder struct {
+	buf []byte
+	err error
+}
+
+func (d *decoder) take(n uint32) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if uint64(len(d.buf)) < uint64(n) {
+		d.err = errShort
+		d.buf = nil
+		return nil
+	}
+
+	v := d.buf[:n]
+	d.buf = d.buf[n:]
+	return v
+}
+
+func (d *decoder) u8() uint8 {
+	v := d.take(1)
+	if v == nil {
+		return 0
+	}
+	return v[0]
+}
+
+func (d *decoder) u32() uint32 {
+	v := d.take(4)
+	if v == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint32(v)
+}
+
+func (d *decoder) bytes() []byte {
+	return d.take(d.u32())
+}
+
+func (d *decoder) str() string {
+	return string(d.bytes())
+}
+
+func (d *decoder) md() metadata.MD {
+	n := d.u32()
+	md := metadata.MD{}
+	for i := uint32(0); i < n && d.err == nil; i++ {
+		k := d.str()
+		m := d.u32()
+		for j := uint32(0); j < m && d.err == nil; j++ {
+			md.Append(k, d.str())
+		}
+	}
+	return md
+}
+
+func (d *decoder) status() *status.Status {
+	code := d.u32()
+	msg := d.str()
+	details := d.bytes()
+	if d.err != nil {
+		return nil
+	}
+	if len(details) == 0 {
+		return status.New(codes.Code(code), msg)
+	}
+
+	p := &spb.Status{}
+	if err := proto.Unmarshal(details, p); err != nil {
+		d.err = fmt.Errorf("unmarshal status: %w", err)
+		return nil
+	}
+	p.Code = int32(code)
+	p.Message = msg
+	return status.FromProto(p)
+}
@@ -0,0 +1,91 @@
+package framing_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/lesomnus/grpc-wasm/framing"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestFrame(t *testing.T) {
+	s, err := status.New(codes.NotFound, "where's the money").WithDetails(&errdetails.DebugInfo{Detail: "Lebowski"})
+	require.NoError(t, err)
+
+	frames := []*framing.Frame{
+		{Type: framing.TypeDial, ID: 1},
+		{Type: framing.TypeInvoke, ID: 2, Conn: 1, Method: "/echo.EchoService/Once", Meta: metadata.Pairs("foo", "bar", "foo", "baz"), Data: []byte("Dude")},
+		{Type: framing.TypeOpen, ID: 3, Conn: 1, Method: "/echo.EchoService/Live", Meta: metadata.MD{}},
+		{Type: framing.TypeSend, ID: 3, Data: []byte("Walter")},
+		{Type: framing.TypeResult, ID: 2, Meta: metadata.Pairs("timing", "header"), Data: []byte("Donny"), Trailer: metadata.Pairs("timing", "trailer"), Status: status.New(codes.OK, "")},
+		{Type: framing.TypeEnd, ID: 3, Trailer: metadata.MD{}, Status: s},
+		{Type: framing.TypeError, ID: 4, Error: "connection 4 is not dialed"},
+	}
+
+	b := &bytes.Buffer{}
+	for _, f := range frames {
+		require.NoError(t, framing.Write(b, f))
+	}
+	for _, expected := range frames {
+		x := require.New(t)
+
+		f, err := framing.Read(b)
+		x.NoError(err)
+		x.Equal(expected.Type, f.Type)
+		x.Equal(expected.ID, f.ID)
+		x.Equal(expected.Conn, f.Conn)
+		x.Equal(expected.Method, f.Method)
+		x.Equal(expected.Error, f.Error)
+		x.Equal(string(expected.Data), string(f.Data))
+		if expected.Meta != nil {
+			x.Equal(expected.Meta, f.Meta)
+		}
+		if expected.Trailer != nil {
+			x.Equal(expected.Trailer, f.Trailer)
+		}
+		if expected.Status != nil {
+			x.True(proto.Equal(expected.Status.Proto(), f.Status.Proto()))
+		}
+	}
+
+	_, err = framing.Read(b)
+	require.ErrorIs(t, err, io.EOF)
+}
+
+func TestRead(t *testing.T) {
+	t.Run("truncated", func(t *testing.T) {
+		x := require.New(t)
+
+		b := &bytes.Buffer{}
+		x.NoError(framing.Write(b, &framing.Frame{Type: framing.TypeSend, ID: 1, Data: []byte("Lebowski")}))
+
+		data := b.Bytes()
+		_, err := framing.Read(bytes.NewReader(data[:len(data)-1]))
+		x.ErrorIs(err, io.ErrUnexpectedEOF)
+	})
+	t.Run("malformed", func(t *testing.T) {
+		x := require.New(t)
+
+		// Send frame whose message is longer than the frame.
+		_, err := framing.Read(bytes.NewReader([]byte{0, 0, 0, 9, 0x05, 0, 0, 0, 1, 0, 0, 0, 9}))
+		x.ErrorContains(err, "malformed Send frame")
+	})
+	t.Run("unknown type", func(t *testing.T) {
+		x := require.New(t)
+
+		_, err := framing.Read(bytes.NewReader([]byte{0, 0, 0, 5, 0x42, 0, 0, 0, 1}))
+		x.ErrorContains(err, "unknown frame type Type(0x42)")
+	})
+	t.Run("too large", func(t *testing.T) {
+		x := require.New(t)
+
+		_, err := framing.Read(bytes.NewReader([]byte{0xff, 0xff, 0xff, 0xff}))
+		x.ErrorContains(err, "frame too large")
+	})
+}
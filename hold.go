@@ -0,0 +1,373 @@
+package grpcwasm
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/lesomnus/grpc-wasm/internal/js"
+	"github.com/lesomnus/grpc-wasm/internal/jz"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// heldCall is a call or a stream message held before it is delivered.
+type heldCall struct {
+	ID     uint32  `js:"id"`
+	Method string  `js:"method"`
+	Kind   string  `js:"kind"`
+	Meta   rpcMeta `js:"meta"`
+	// Decoded message, or undefined for "open".
+	Message js.Value `js:"message"`
+}
+
+type heldItem struct {
+	id     uint32
+	method string
+	kind   string
+	md     metadata.MD
+	msg    proto.Message
+
+	// Receives nil to release, or the error of a status to fail.
+	done chan error
+}
+
+func (i *heldItem) toJs() (heldCall, error) {
+	c := heldCall{
+		ID:      i.id,
+		Method:  i.method,
+		Kind:    i.kind,
+		Meta:    rpcMeta(i.md),
+		Message: js.Undefined(),
+	}
+	if i.msg != nil {
+		v, err := messageToJs(i.msg)
+		if err != nil {
+			return c, err
+		}
+		c.Message = v
+	}
+	return c, nil
+}
+
+// hold holds the calls of the methods matching its pattern until the JS side releases them.
+type hold struct {
+	pattern string
+
+	mu     sync.Mutex
+	items  []*heldItem
+	closed bool
+	// Items still held but not yet received by [hold.recv].
+	queue []*heldItem
+	// Closed when the queue grows or the hold is closed.
+	changed chan struct{}
+}
+
+func (h *hold) push(item *heldItem) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return false
+	}
+	h.items = append(h.items, item)
+	h.queue = append(h.queue, item)
+	close(h.changed)
+	h.changed = make(chan struct{})
+	return true
+}
+
+func (h *hold) take(id uint32) *heldItem {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	i := slices.IndexFunc(h.items, func(v *heldItem) bool { return v.id == id })
+	if i < 0 {
+		return nil
+	}
+	item := h.items[i]
+	h.items = slices.Delete(h.items, i, i+1)
+	h.queue = slices.DeleteFunc(h.queue, func(v *heldItem) bool { return v == item })
+	return item
+}
+
+func (h *hold) list() []*heldItem {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return slices.Clone(h.items)
+}
+
+// release releases the item of given ID with given error, nil to let it go.
+func (h *hold) release(id uint32, err error) error {
+	item := h.take(id)
+	if item == nil {
+		return fmt.Errorf("call %d is not held", id)
+	}
+	item.done <- err
+	return nil
+}
+
+func (h *hold) releaseAll() {
+	h.mu.Lock()
+	items := h.items
+	h.items = nil
+	h.queue = nil
+	h.mu.Unlock()
+
+	for _, item := range items {
+		item.done <- nil
+	}
+}
+
+// recv returns the item held next to the ones returned before,
+// or false if the hold is closed or the context ends.
+func (h *hold) recv(ctx context.Context) (*heldItem, bool) {
+	for {
+		h.mu.Lock()
+		if len(h.queue) > 0 {
+			item := h.queue[0]
+			h.queue = h.queue[1:]
+			h.mu.Unlock()
+			return item, true
+		}
+		closed := h.closed
+		changed := h.changed
+		h.mu.Unlock()
+
+		if closed {
+			return nil, false
+		}
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return nil, false
+		}
+	}
+}
+
+func (h *hold) close() {
+	h.mu.Lock()
+	if !h.closed {
+		h.closed = true
+		close(h.changed)
+	}
+	h.mu.Unlock()
+
+	h.releaseAll()
+}
+
+// holdRegistry is the holds registered by JS, in the order they are registered.
+type holdRegistry struct {
+	seq atomic.Uint32
+
+	mu    sync.Mutex
+	holds []*hold
+}
+
+func (r *holdRegistry) add(pattern string) *hold {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	h := &hold{pattern: pattern, changed: make(chan struct{})}
+	r.holds = append(r.holds, h)
+	return h
+}
+
+func (r *holdRegistry) remove(h *hold) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.holds = slices.DeleteFunc(r.holds, func(v *hold) bool { return v == h })
+}
+
+// match returns the first hold whose pattern matches the method.
+func (r *holdRegistry) match(method string) *hold {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, h := range r.holds {
+		if ok, _ := path.Match(h.pattern, method); ok {
+			return h
+		}
+	}
+	return nil
+}
+
+// wait holds the call until the JS side releases or fails it, if a hold matches the method.
+func (r *holdRegistry) wait(ctx context.Context, method string, kind string, msg proto.Message) error {
+	h := r.match(method)
+	if h == nil {
+		return nil
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	item := &heldItem{
+		id:     r.seq.Add(1),
+		method: method,
+		kind:   kind,
+		md:     md,
+		done:   make(chan error, 1),
+	}
+	if msg != nil {
+		item.msg = proto.Clone(msg)
+	}
+	if !h.push(item) {
+		return nil
+	}
+
+	select {
+	case err := <-item.done:
+		return err
+	case <-ctx.Done():
+		h.take(item.id)
+		return status.FromContextError(ctx.Err()).Err()
+	}
+}
+
+func heldCallsToJs(items []*heldItem) (js.Value, error) {
+	calls := make([]heldCall, len(items))
+	for i, item := range items {
+		c, err := item.toJs()
+		if err != nil {
+			return js.Undefined(), err
+		}
+		calls[i] = c
+	}
+	return jz.Marshal(calls)
+}
+
+var holdParams = jz.Params{
+	{Name: "pattern", Kind: jz.KindString},
+}
+
+var holdIdParams = jz.Params{
+	{Name: "id", Kind: jz.KindNumber},
+}
+
+var holdFailParams = jz.Params{
+	{Name: "id", Kind: jz.KindNumber},
+	{Name: "status", Kind: jz.KindObject},
+}
+
+// JsHold holds the calls of the methods matching the pattern before their handlers run,
+// until they are released or failed through the returned handle.
+// Streaming calls are held when they open and then at every message in both directions,
+// so a server stream can be advanced one message at a time.
+// The pattern is matched with the full method name by [path.Match], e.g. "/echo.EchoService/*".
+// The first hold matching a method holds its calls.
+// Calls are held only if the server has [JsUnaryServerInterceptor] and [JsStreamServerInterceptor].
+// Closing the handle releases the calls it holds.
+//
+// Signature:
+//
+//	type HeldCall = {
+//		id: number
+//		method: string
+//		kind: "open" | "request" | "response"
+//		meta: Metadata
+//		message?: unknown
+//	}
+//	type HoldResult =
+//		| {
+//			done: false
+//			call: HeldCall
+//		}
+//		| {
+//			done: true
+//		}
+//	type Hold = {
+//		held: ()=>Promise<HeldCall[]>
+//		recv: ()=>Promise<HoldResult>
+//		release: (id: number)=>Promise<void>
+//		release_all: ()=>Promise<void>
+//		fail: (id: number, status: RpcStatus)=>Promise<void>
+//		close: ()=>Promise<void>
+//	}
+//	function(pattern: string): Promise<Hold>;
+func (l *Listener) JsHold(this js.Value, args []js.Value) any {
+	if err := holdParams.Check(args); err != nil {
+		return jz.Reject(jz.ToError(err))
+	}
+
+	pattern := args[0].String()
+	if _, err := path.Match(pattern, ""); err != nil {
+		return jz.Reject(jz.ToError(&jz.TypeError{Path: "pattern", Expected: "glob pattern", Actual: pattern}))
+	}
+
+	h := l.holds.add(pattern)
+
+	var obj *jz.Object
+	release := sync.OnceFunc(func() {
+		l.holds.remove(h)
+		h.close()
+		obj.Release()
+	})
+	obj = l.scope.Object().
+		Method("held", func(this js.Value, args []js.Value) any {
+			v, err := heldCallsToJs(h.list())
+			if err != nil {
+				return jz.Reject(jz.ToError(err))
+			}
+			return jz.Resolve(v)
+		}).
+		Method("recv", func(this js.Value, args []js.Value) any {
+			return l.scope.Promise(func() (js.Value, js.Value) {
+				item, ok := h.recv(l.ctx)
+				if !ok {
+					return js.ValueOf(map[string]any{"done": true}), js.Undefined()
+				}
+				c, err := item.toJs()
+				if err != nil {
+					return js.Undefined(), jz.ToError(err)
+				}
+				v, err := jz.Marshal(map[string]any{"done": false, "call": c})
+				if err != nil {
+					return js.Undefined(), jz.ToError(err)
+				}
+				return v, js.Undefined()
+			})
+		}).
+		Method("release", func(this js.Value, args []js.Value) any {
+			if err := holdIdParams.Check(args); err != nil {
+				return jz.Reject(jz.ToError(err))
+			}
+			if err := h.release(uint32(args[0].Int()), nil); err != nil {
+				return jz.Reject(jz.ToError(err))
+			}
+			return jz.Resolve(js.Undefined())
+		}).
+		Method("release_all", func(this js.Value, args []js.Value) any {
+			h.releaseAll()
+			return jz.Resolve(js.Undefined())
+		}).
+		Method("fail", func(this js.Value, args []js.Value) any {
+			if err := holdFailParams.Check(args); err != nil {
+				return jz.Reject(jz.ToError(err))
+			}
+
+			st := newRpcStatus(status.New(codes.Unknown, ""))
+			if err := jz.Unmarshal(args[1], &st); err != nil {
+				return jz.Reject(jz.ToError(err))
+			}
+			if st.Code == codes.OK {
+				return jz.Reject(jz.Error("status must not be OK"))
+			}
+			if err := h.release(uint32(args[0].Int()), st.Status().Err()); err != nil {
+				return jz.Reject(jz.ToError(err))
+			}
+			return jz.Resolve(js.Undefined())
+		}).
+		Method("close", func(this js.Value, args []js.Value) any {
+			release()
+			return jz.Resolve(js.Undefined())
+		}).
+		OnCollected(release)
+
+	return jz.Resolve(obj.Value())
+}
@@ -0,0 +1,248 @@
+package grpcwasm_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	grpcwasm "github.com/lesomnus/grpc-wasm"
+	"github.com/lesomnus/grpc-wasm/internal/echo"
+	"github.com/lesomnus/grpc-wasm/internal/js"
+	"github.com/lesomnus/grpc-wasm/internal/jz"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestListener_JsHold(t *testing.T) {
+	x := require.New(t)
+
+	l := grpcwasm.NewListener()
+	defer l.Close()
+
+	conn, err := grpc.NewClient("passthrough://bufnet",
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(ctx context.Context, s string) (net.Conn, error) {
+			return l.DialContext(ctx)
+		}),
+	)
+	x.NoError(err)
+	defer conn.Close()
+
+	s := grpcwasm.NewServer(
+		grpc.ChainUnaryInterceptor(grpcwasm.JsUnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(grpcwasm.JsStreamServerInterceptor()),
+	)
+	echo.RegisterEchoServiceServer(s, echo.EchoServer{})
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		s.Serve(l)
+	}()
+	defer wg.Wait()
+	defer s.Stop()
+
+	client := echo.NewEchoServiceClient(conn)
+	sock := l.ToJsValue()
+
+	// hold holds the calls matching the pattern until the test ends.
+	hold := func(t *testing.T, pattern string) js.Value {
+		h, err_js := jz.Await(sock.Call("hold", pattern))
+		require.True(t, err_js.IsUndefined())
+		t.Cleanup(func() {
+			jz.Await(h.Call("close"))
+		})
+		return h
+	}
+	recv := func(t *testing.T, h js.Value) js.Value {
+		v, err_js := jz.Await(h.Call("recv"))
+		require.True(t, err_js.IsUndefined())
+		require.False(t, v.Get("done").Bool())
+		return v.Get("call")
+	}
+	release := func(t *testing.T, h js.Value, call js.Value) {
+		_, err_js := jz.Await(h.Call("release", call.Get("id")))
+		require.True(t, err_js.IsUndefined())
+	}
+	once := func(ctx context.Context, msg string) <-chan error {
+		done := make(chan error, 1)
+		go func() {
+			res, err := client.Once(ctx, echo.EchoRequest_builder{Message: msg}.Build())
+			if err == nil && res.GetMessage() != msg {
+				err = io.ErrUnexpectedEOF
+			}
+			done <- err
+		}()
+		return done
+	}
+
+	t.Run("release", func(t *testing.T) {
+		x := require.New(t)
+
+		h := hold(t, "/echo.EchoService/*")
+		done := once(metadata.AppendToOutgoingContext(t.Context(), "foo", "bar"), "Lebowski")
+
+		call := recv(t, h)
+		x.Equal(echo.EchoService_Once_FullMethodName, call.Get("method").String())
+		x.Equal("request", call.Get("kind").String())
+		x.Equal("bar", call.Get("meta").Get("foo").Index(0).String())
+		x.Equal("Lebowski", call.Get("message").Get("message").String())
+
+		held, err_js := jz.Await(h.Call("held"))
+		x.True(err_js.IsUndefined())
+		x.Equal(1, held.Length())
+		x.Equal(call.Get("id").Int(), held.Index(0).Get("id").Int())
+		select {
+		case <-done:
+			x.Fail("call is not held")
+		default:
+		}
+
+		release(t, h, call)
+		x.NoError(<-done)
+
+		held, _ = jz.Await(h.Call("held"))
+		x.Equal(0, held.Length())
+
+		_, err_js = jz.Await(h.Call("release", call.Get("id")))
+		x.False(err_js.IsUndefined())
+	})
+	t.Run("fail", func(t *testing.T) {
+		x := require.New(t)
+
+		h := hold(t, echo.EchoService_Once_FullMethodName)
+		done := once(t.Context(), "Lebowski")
+
+		call := recv(t, h)
+		_, err_js := jz.Await(h.Call("fail", call.Get("id"), map[string]any{
+			"code":    int(codes.Unavailable),
+			"message": "Mark it zero",
+		}))
+		x.True(err_js.IsUndefined())
+
+		s, _ := status.FromError(<-done)
+		x.Equal(codes.Unavailable, s.Code())
+		x.Equal("Mark it zero", s.Message())
+	})
+	t.Run("release all", func(t *testing.T) {
+		x := require.New(t)
+
+		h := hold(t, "/echo.EchoService/*")
+		a := once(t.Context(), "Walter")
+		b := once(t.Context(), "Donny")
+		recv(t, h)
+		recv(t, h)
+
+		_, err_js := jz.Await(h.Call("release_all"))
+		x.True(err_js.IsUndefined())
+		x.NoError(<-a)
+		x.NoError(<-b)
+	})
+	t.Run("stream", func(t *testing.T) {
+		x := require.New(t)
+
+		h := hold(t, echo.EchoService_Many_FullMethodName)
+		stream, err := client.Many(t.Context(), echo.EchoRequest_builder{Message: "Dude", Repeat: proto.Uint32(2)}.Build())
+		x.NoError(err)
+
+		call := recv(t, h)
+		x.Equal("open", call.Get("kind").String())
+		x.True(call.Get("message").IsUndefined())
+		release(t, h, call)
+
+		call = recv(t, h)
+		x.Equal("request", call.Get("kind").String())
+		x.Equal("Dude", call.Get("message").Get("message").String())
+		release(t, h, call)
+
+		// Advance one message at a time.
+		for range 2 {
+			call = recv(t, h)
+			x.Equal("response", call.Get("kind").String())
+			x.Equal("Dude", call.Get("message").Get("message").String())
+			release(t, h, call)
+
+			res, err := stream.Recv()
+			x.NoError(err)
+			x.Equal("Dude", res.GetMessage())
+		}
+		_, err = stream.Recv()
+		x.ErrorIs(err, io.EOF)
+	})
+	t.Run("canceled", func(t *testing.T) {
+		x := require.New(t)
+
+		h := hold(t, "/echo.EchoService/*")
+		ctx, cancel := context.WithCancel(t.Context())
+		done := once(ctx, "Lebowski")
+		recv(t, h)
+
+		cancel()
+		s, _ := status.FromError(<-done)
+		x.Equal(codes.Canceled, s.Code())
+	})
+	t.Run("canceled before recv", func(t *testing.T) {
+		x := require.New(t)
+
+		h := hold(t, "/echo.EchoService/*")
+		held := func() int {
+			v, err_js := jz.Await(h.Call("held"))
+			require.True(t, err_js.IsUndefined())
+			return v.Length()
+		}
+
+		ctx, cancel := context.WithCancel(t.Context())
+		done := once(ctx, "Walter")
+		x.Eventually(func() bool { return held() == 1 }, time.Second, time.Millisecond)
+
+		cancel()
+		s, _ := status.FromError(<-done)
+		x.Equal(codes.Canceled, s.Code())
+		x.Eventually(func() bool { return held() == 0 }, time.Second, time.Millisecond)
+
+		// The canceled call is not received.
+		done = once(t.Context(), "Donny")
+		call := recv(t, h)
+		x.Equal("Donny", call.Get("message").Get("message").String())
+		release(t, h, call)
+		x.NoError(<-done)
+	})
+	t.Run("close", func(t *testing.T) {
+		x := require.New(t)
+
+		h, err_js := jz.Await(sock.Call("hold", "/echo.EchoService/*"))
+		x.True(err_js.IsUndefined())
+		done := once(t.Context(), "Lebowski")
+		recv(t, h)
+		next := h.Call("recv")
+
+		_, err_js = jz.Await(h.Call("close"))
+		x.True(err_js.IsUndefined())
+		x.NoError(<-done)
+
+		v, err_js := jz.Await(next)
+		x.True(err_js.IsUndefined())
+		x.True(v.Get("done").Bool())
+
+		// Not held anymore.
+		x.NoError(<-once(t.Context(), "Lebowski"))
+	})
+	t.Run("invalid pattern", func(t *testing.T) {
+		x := require.New(t)
+
+		_, err_js := jz.Await(sock.Call("hold", "["))
+		x.Equal("argument", err_js.Get("kind").String())
+
+		_, err_js = jz.Await(sock.Call("hold", 42))
+		x.Equal("argument", err_js.Get("kind").String())
+	})
+}
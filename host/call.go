@@ -0,0 +1,274 @@
+package host
+
+import (
+	"context"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"github.com/lesomnus/grpc-wasm/framing"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func marshal(v any) ([]byte, error) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil, status.Errorf(codes.Internal, "expected the message to be proto.Message, got %T", v)
+	}
+
+	data, err := proto.Marshal(m)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "marshal: %v", err)
+	}
+	return data, nil
+}
+
+func unmarshal(data []byte, v any) error {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return status.Errorf(codes.Internal, "expected the message to be proto.Message, got %T", v)
+	}
+
+	if err := proto.Unmarshal(data, m); err != nil {
+		return status.Errorf(codes.Internal, "unmarshal: %v", err)
+	}
+	return nil
+}
+
+// setMetadata fills the header and trailer requested by the call options.
+func setMetadata(opts []grpc.CallOption, header metadata.MD, trailer metadata.MD) {
+	for _, opt := range opts {
+		switch o := opt.(type) {
+		case grpc.HeaderCallOption:
+			*o.HeaderAddr = header
+		case grpc.TrailerCallOption:
+			*o.TrailerAddr = trailer
+		}
+	}
+}
+
+// result returns the error of the frame.
+func result(f *framing.Frame) error {
+	switch f.Type {
+	case framing.TypeResult, framing.TypeEnd:
+		return f.Status.Err()
+	case framing.TypeError:
+		return status.Error(codes.Internal, f.Error)
+	}
+	return status.Errorf(codes.Internal, "unexpected frame %s", f.Type)
+}
+
+// Invoke implements [grpc.ClientConnInterface].
+// Messages must be proto.Message.
+func (c *Conn) Invoke(ctx context.Context, method string, args any, reply any, opts ...grpc.CallOption) error {
+	data, err := marshal(args)
+	if err != nil {
+		return err
+	}
+
+	md, _ := metadata.FromOutgoingContext(ctx)
+	id := c.seq.Add(1) + connID
+	box := c.inbox(id)
+	defer c.release(id)
+
+	if err := c.write(&framing.Frame{
+		Type:   framing.TypeInvoke,
+		ID:     id,
+		Conn:   connID,
+		Method: method,
+		Meta:   md,
+		Data:   data,
+	}); err != nil {
+		return unavailable(err)
+	}
+
+	f, err := box.next(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			c.write(&framing.Frame{Type: framing.TypeCancel, ID: id})
+			return status.FromContextError(ctx.Err()).Err()
+		}
+		return unavailable(err)
+	}
+	setMetadata(opts, f.Meta, f.Trailer)
+	if err := result(f); err != nil {
+		return err
+	}
+	return unmarshal(f.Data, reply)
+}
+
+// NewStream implements [grpc.ClientConnInterface].
+// Messages must be proto.Message.
+func (c *Conn) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	id := c.seq.Add(1) + connID
+	box := c.inbox(id)
+
+	if err := c.write(&framing.Frame{
+		Type:   framing.TypeOpen,
+		ID:     id,
+		Conn:   connID,
+		Method: method,
+		Meta:   md,
+	}); err != nil {
+		c.release(id)
+		return nil, unavailable(err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	s := &clientStream{
+		c:      c,
+		id:     id,
+		box:    box,
+		ctx:    ctx,
+		cancel: cancel,
+		opts:   opts,
+	}
+	go func() {
+		<-ctx.Done()
+		if !s.ended.Load() {
+			c.write(&framing.Frame{Type: framing.TypeCancel, ID: id})
+		}
+	}()
+
+	return s, nil
+}
+
+type clientStream struct {
+	c      *Conn
+	id     uint32
+	box    *inbox
+	ctx    context.Context
+	cancel context.CancelFunc
+	opts   []grpc.CallOption
+
+	// Guards the fields below.
+	// It is held while receiving, so Header and RecvMsg do not race for the frames.
+	mu sync.Mutex
+	// Set once a Header, Message, or End is received.
+	header      metadata.MD
+	header_seen bool
+	// Message received while waiting for the header.
+	pending []byte
+	trailer metadata.MD
+	// Set once the stream ends.
+	err error
+
+	done atomic.Bool
+	// Set once the bridge ends the stream, so it needs no Cancel.
+	ended atomic.Bool
+}
+
+// finish ends the stream. It must be called with the lock held.
+func (s *clientStream) finish(trailer metadata.MD, err error) {
+	s.done.Store(true)
+	s.header_seen = true
+	s.trailer = trailer
+	s.err = err
+
+	s.c.release(s.id)
+	s.cancel()
+	setMetadata(s.opts, s.header, trailer)
+}
+
+// recv receives a frame and returns the message if it is a Message.
+// It must be called with the lock held.
+func (s *clientStream) recv() ([]byte, bool) {
+	f, err := s.box.next(s.ctx)
+	if err != nil {
+		if s.ctx.Err() != nil {
+			err = status.FromContextError(s.ctx.Err()).Err()
+		}
+		s.finish(nil, unavailable(err))
+		return nil, false
+	}
+
+	switch f.Type {
+	case framing.TypeHeader:
+		s.header = f.Meta
+		s.header_seen = true
+	case framing.TypeMessage:
+		s.header_seen = true
+		return f.Data, true
+	case framing.TypeEnd, framing.TypeError:
+		s.ended.Store(true)
+		s.finish(f.Trailer, result(f))
+	default:
+		s.finish(nil, status.Errorf(codes.Internal, "unexpected frame %s", f.Type))
+	}
+	return nil, false
+}
+
+// Header waits for the header. It is nil if the stream ends without one.
+func (s *clientStream) Header() (metadata.MD, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for !s.header_seen {
+		if data, ok := s.recv(); ok {
+			s.pending = data
+		}
+	}
+	if s.header == nil && s.err != nil {
+		return nil, s.err
+	}
+	return s.header, nil
+}
+
+func (s *clientStream) Trailer() metadata.MD {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.trailer
+}
+
+func (s *clientStream) CloseSend() error {
+	if err := s.c.write(&framing.Frame{Type: framing.TypeCloseSend, ID: s.id}); err != nil {
+		return unavailable(err)
+	}
+	return nil
+}
+
+func (s *clientStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *clientStream) SendMsg(m any) error {
+	if s.done.Load() {
+		// As grpc-go does, the status is returned by RecvMsg.
+		return io.EOF
+	}
+
+	data, err := marshal(m)
+	if err != nil {
+		return err
+	}
+	if err := s.c.write(&framing.Frame{Type: framing.TypeSend, ID: s.id, Data: data}); err != nil {
+		return unavailable(err)
+	}
+	return nil
+}
+
+func (s *clientStream) RecvMsg(m any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.pending != nil {
+		data := s.pending
+		s.pending = nil
+		return unmarshal(data, m)
+	}
+	for !s.done.Load() {
+		if data, ok := s.recv(); ok {
+			return unmarshal(data, m)
+		}
+	}
+	if s.err == nil {
+		return io.EOF
+	}
+	return s.err
+}
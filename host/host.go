@@ -0,0 +1,373 @@
+// Package host runs a bridge module built for wasip1 in an embedded pure-Go WASM runtime,
+// so the services inside the module can be called from plain Go, e.g. in tests.
+//
+//	conn, err := host.LoadFile(ctx, "bridge.wasm")
+//	if err != nil { ... }
+//	defer conn.Close()
+//
+//	client := echo.NewEchoServiceClient(conn)
+package host
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/lesomnus/grpc-wasm/framing"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"github.com/tetratelabs/wazero/sys"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// connID is the ID of the only connection a [Conn] dials in the module.
+const connID = 1
+
+var _ grpc.ClientConnInterface = (*Conn)(nil)
+
+// Conn is a connection to the server inside a bridge module.
+type Conn struct {
+	runtime wazero.Runtime
+
+	w_mu sync.Mutex
+	w    *os.File
+
+	seq   atomic.Uint32
+	mu    sync.Mutex
+	calls map[uint32]*inbox
+	// Set once the module stops responding.
+	err error
+
+	// Closed once the module exits.
+	exited   chan struct{}
+	exit_err error
+	// Terminates the module.
+	terminate     context.CancelFunc
+	close_timeout time.Duration
+
+	close_once sync.Once
+}
+
+type config struct {
+	stderr io.Writer
+	args   []string
+	env    map[string]string
+	cache  wazero.CompilationCache
+
+	close_timeout time.Duration
+}
+
+type Option func(c *config)
+
+// WithStderr sets where the logs of the module go. It is [os.Stderr] by default.
+func WithStderr(w io.Writer) Option {
+	return func(c *config) {
+		c.stderr = w
+	}
+}
+
+// WithArgs sets the arguments of the module following its name.
+func WithArgs(args ...string) Option {
+	return func(c *config) {
+		c.args = args
+	}
+}
+
+// WithEnv sets an environment variable of the module.
+func WithEnv(key string, value string) Option {
+	return func(c *config) {
+		c.env[key] = value
+	}
+}
+
+// WithCompilationCache shares compiled modules between the loads using the same cache,
+// which saves compiling the module again for each load.
+func WithCompilationCache(cache wazero.CompilationCache) Option {
+	return func(c *config) {
+		c.cache = cache
+	}
+}
+
+// WithCloseTimeout sets how long [Conn.Close] waits for the module to exit
+// before it terminates the module. It is 5 seconds by default.
+func WithCloseTimeout(d time.Duration) Option {
+	return func(c *config) {
+		c.close_timeout = d
+	}
+}
+
+// LoadFile loads the module in given file. See [Load].
+func LoadFile(ctx context.Context, path string, opts ...Option) (*Conn, error) {
+	wasm, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return Load(ctx, wasm, opts...)
+}
+
+// Load starts a bridge module built for wasip1 and dials the server inside it.
+// The module must serve the server by grpcwasm.Serve.
+// The context is used only while loading.
+func Load(ctx context.Context, wasm []byte, opts ...Option) (*Conn, error) {
+	cfg := &config{
+		stderr: os.Stderr,
+		env:    map[string]string{},
+
+		close_timeout: 5 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	rt_cfg := wazero.NewRuntimeConfig().WithCloseOnContextDone(true)
+	if cfg.cache != nil {
+		rt_cfg = rt_cfg.WithCompilationCache(cfg.cache)
+	}
+
+	r := wazero.NewRuntimeWithConfig(context.Background(), rt_cfg)
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, r); err != nil {
+		r.Close(context.Background())
+		return nil, fmt.Errorf("instantiate WASI: %w", err)
+	}
+
+	mod, err := r.CompileModule(ctx, wasm)
+	if err != nil {
+		r.Close(context.Background())
+		return nil, fmt.Errorf("compile module: %w", err)
+	}
+
+	// Stdin is an OS pipe so the module can read it without blocking, which it needs
+	// to keep serving while waiting for frames.
+	stdin_r, stdin_w, err := os.Pipe()
+	if err != nil {
+		r.Close(context.Background())
+		return nil, fmt.Errorf("pipe: %w", err)
+	}
+	stdout_r, stdout_w := io.Pipe()
+
+	// Cancelling the context terminates the module as the runtime closes on context done.
+	run_ctx, terminate := context.WithCancel(context.Background())
+	mod_cfg := wazero.NewModuleConfig().
+		WithName("bridge").
+		WithArgs(append([]string{"bridge"}, cfg.args...)...).
+		WithStdin(stdin_r).
+		WithStdout(stdout_w).
+		WithStderr(cfg.stderr).
+		WithSysWalltime().
+		WithSysNanotime().
+		// The module sleeps while it is idle, which must not outlast the termination.
+		WithNanosleep(func(ns int64) {
+			t := time.NewTimer(time.Duration(ns))
+			defer t.Stop()
+			select {
+			case <-t.C:
+			case <-run_ctx.Done():
+			}
+		}).
+		WithRandSource(rand.Reader)
+	for k, v := range cfg.env {
+		mod_cfg = mod_cfg.WithEnv(k, v)
+	}
+
+	c := &Conn{
+		runtime: r,
+		w:       stdin_w,
+		calls:   map[uint32]*inbox{},
+		exited:  make(chan struct{}),
+
+		terminate:     terminate,
+		close_timeout: cfg.close_timeout,
+	}
+	go func() {
+		defer close(c.exited)
+		defer stdout_w.Close()
+		defer stdin_r.Close()
+
+		_, err := r.InstantiateModule(run_ctx, mod, mod_cfg)
+		var exit *sys.ExitError
+		if errors.As(err, &exit) && exit.ExitCode() == 0 {
+			err = nil
+		}
+		c.exit_err = err
+	}()
+	go c.dispatch(stdout_r)
+
+	dialed := c.inbox(connID)
+	defer c.release(connID)
+	if err := c.write(&framing.Frame{Type: framing.TypeDial, ID: connID}); err != nil {
+		c.Close()
+		return nil, fmt.Errorf("dial: %w", err)
+	}
+
+	f, err := dialed.next(ctx)
+	if err == nil && f.Type != framing.TypeDialed {
+		err = fmt.Errorf("unexpected frame %s", f.Type)
+	}
+	if err != nil {
+		c.Close()
+		return nil, fmt.Errorf("dial: %w", err)
+	}
+
+	return c, nil
+}
+
+func (c *Conn) write(f *framing.Frame) error {
+	c.w_mu.Lock()
+	defer c.w_mu.Unlock()
+
+	return framing.Write(c.w, f)
+}
+
+// dispatch delivers the frames from the module to the calls until the module exits.
+func (c *Conn) dispatch(r io.Reader) {
+	for {
+		f, err := framing.Read(r)
+		if err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrClosedPipe) {
+				err = errors.New("module exited")
+			}
+			c.fail(err)
+			return
+		}
+
+		c.mu.Lock()
+		box, ok := c.calls[f.ID]
+		c.mu.Unlock()
+		if ok {
+			box.push(f)
+		}
+	}
+}
+
+// fail fails every call in flight and the calls made after.
+func (c *Conn) fail(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err != nil {
+		return
+	}
+	c.err = err
+	for _, box := range c.calls {
+		box.fail(err)
+	}
+}
+
+// inbox registers a call and returns where its frames are delivered.
+func (c *Conn) inbox(id uint32) *inbox {
+	box := newInbox()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err != nil {
+		box.fail(c.err)
+	}
+	c.calls[id] = box
+	return box
+}
+
+func (c *Conn) release(id uint32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.calls, id)
+}
+
+// Close stops the module and releases the runtime.
+// Calls in flight fail.
+// The module is terminated if it does not exit within the timeout set by [WithCloseTimeout].
+func (c *Conn) Close() error {
+	c.close_once.Do(func() {
+		c.write(&framing.Frame{Type: framing.TypeClose, ID: connID})
+
+		// The module stops serving once stdin ends.
+		c.w.Close()
+		select {
+		case <-c.exited:
+		case <-time.After(c.close_timeout):
+			c.terminate()
+			<-c.exited
+		}
+		c.terminate()
+		c.fail(errors.New("connection closed"))
+		c.runtime.Close(context.Background())
+	})
+
+	return c.exit_err
+}
+
+// unavailable converts an error of the connection into status UNAVAILABLE.
+func unavailable(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	return status.Error(codes.Unavailable, err.Error())
+}
+
+// inbox queues the frames of a call.
+type inbox struct {
+	mu     sync.Mutex
+	frames []*framing.Frame
+	err    error
+	signal chan struct{}
+}
+
+func newInbox() *inbox {
+	return &inbox{signal: make(chan struct{}, 1)}
+}
+
+func (b *inbox) notify() {
+	select {
+	case b.signal <- struct{}{}:
+	default:
+	}
+}
+
+func (b *inbox) push(f *framing.Frame) {
+	b.mu.Lock()
+	b.frames = append(b.frames, f)
+	b.mu.Unlock()
+	b.notify()
+}
+
+func (b *inbox) fail(err error) {
+	b.mu.Lock()
+	b.err = err
+	b.mu.Unlock()
+	b.notify()
+}
+
+// next returns the next frame.
+// It returns the error of the connection after the queued frames.
+func (b *inbox) next(ctx context.Context) (*framing.Frame, error) {
+	for {
+		b.mu.Lock()
+		if len(b.frames) > 0 {
+			f := b.frames[0]
+			b.frames = b.frames[1:]
+			b.mu.Unlock()
+			return f, nil
+		}
+		err := b.err
+		b.mu.Unlock()
+		if err != nil {
+			return nil, err
+		}
+
+		select {
+		case <-b.signal:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,237 @@
+//go:build !wasm
+
+package host_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lesomnus/grpc-wasm/host"
+	"github.com/lesomnus/grpc-wasm/internal/echo"
+	"github.com/stretchr/testify/require"
+	"github.com/tetratelabs/wazero"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	// bridge is the path of the echo bridge built for wasip1.
+	bridge string
+	cache  = wazero.NewCompilationCache()
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(func() int {
+		dir, err := os.MkdirTemp("", "grpcwasm-host-")
+		if err != nil {
+			panic(err)
+		}
+		defer os.RemoveAll(dir)
+
+		bridge = filepath.Join(dir, "echo.wasm")
+		cmd := exec.Command("go", "build", "-o", bridge, "../internal/echobridge")
+		cmd.Env = append(os.Environ(), "GOOS=wasip1", "GOARCH=wasm")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			fmt.Fprintf(os.Stderr, "build the echo bridge: %v\n%s", err, out)
+			return 1
+		}
+
+		return m.Run()
+	}())
+}
+
+func request(msg string, shift int32) *echo.EchoRequest {
+	req := &echo.EchoRequest{}
+	req.SetMessage(msg)
+	req.SetCircularShift(shift)
+	return req
+}
+
+func repeat(req *echo.EchoRequest, n uint32) *echo.EchoRequest {
+	req.SetRepeat(n)
+	return req
+}
+
+func overVoid() *echo.EchoRequest {
+	req := &echo.EchoRequest{}
+	req.SetOverVoid(true)
+	return req
+}
+
+func TestConn(t *testing.T) {
+	x := require.New(t)
+
+	ctx := context.Background()
+	conn, err := host.LoadFile(ctx, bridge, host.WithCompilationCache(cache))
+	x.NoError(err)
+	defer conn.Close()
+
+	client := echo.NewEchoServiceClient(conn)
+
+	t.Run("unary", func(t *testing.T) {
+		x := require.New(t)
+
+		ctx := metadata.AppendToOutgoingContext(ctx, "foo", "bar")
+		var header, trailer metadata.MD
+		res, err := client.Once(ctx, request("Lebowski", 3), grpc.Header(&header), grpc.Trailer(&trailer))
+		x.NoError(err)
+		x.Equal("skiLebow", res.GetMessage())
+		x.Equal([]string{"header"}, header.Get("timing"))
+		x.Equal([]string{"trailer"}, trailer.Get("timing"))
+	})
+	t.Run("unary with error", func(t *testing.T) {
+		x := require.New(t)
+
+		_, err := client.Once(ctx, echo.EchoRequest_builder{
+			Status: echo.Status_builder{Code: int32(codes.NotFound), Message: "where's the money"}.Build(),
+		}.Build())
+		s, ok := status.FromError(err)
+		x.True(ok)
+		x.Equal(codes.NotFound, s.Code())
+		x.Equal("where's the money", s.Message())
+	})
+	t.Run("unary cancelled", func(t *testing.T) {
+		x := require.New(t)
+
+		ctx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+		defer cancel()
+
+		_, err := client.Once(ctx, overVoid())
+		x.Equal(codes.DeadlineExceeded, status.Code(err))
+	})
+	t.Run("server stream", func(t *testing.T) {
+		x := require.New(t)
+
+		stream, err := client.Many(ctx, repeat(request("Lebowski", 3), 2))
+		x.NoError(err)
+
+		res, err := stream.Recv()
+		x.NoError(err)
+		x.Equal("skiLebow", res.GetMessage())
+		x.Equal(uint32(0), res.GetSequence())
+
+		res, err = stream.Recv()
+		x.NoError(err)
+		x.Equal("bowskiLe", res.GetMessage())
+		x.Equal(uint32(1), res.GetSequence())
+
+		_, err = stream.Recv()
+		x.ErrorIs(err, io.EOF)
+	})
+	t.Run("client stream", func(t *testing.T) {
+		x := require.New(t)
+
+		stream, err := client.Buff(ctx)
+		x.NoError(err)
+		x.NoError(stream.Send(request("Lebowski", 3)))
+		x.NoError(stream.Send(request("Lebowski", 1)))
+
+		res, err := stream.CloseAndRecv()
+		x.NoError(err)
+		x.Len(res.GetItems(), 2)
+		x.Equal("skiLebow", res.GetItems()[0].GetMessage())
+		x.Equal("iLebowsk", res.GetItems()[1].GetMessage())
+	})
+	t.Run("bidi stream", func(t *testing.T) {
+		x := require.New(t)
+
+		ctx := metadata.AppendToOutgoingContext(ctx, "foo", "bar")
+		stream, err := client.Live(ctx)
+		x.NoError(err)
+
+		header, err := stream.Header()
+		x.NoError(err)
+		x.Equal([]string{"header"}, header.Get("timing"))
+
+		x.NoError(stream.Send(request("Lebowski", 3)))
+		res, err := stream.Recv()
+		x.NoError(err)
+		x.Equal("skiLebow", res.GetMessage())
+
+		x.NoError(stream.Send(request("Lebowski", 1)))
+		res, err = stream.Recv()
+		x.NoError(err)
+		x.Equal("iLebowsk", res.GetMessage())
+
+		x.NoError(stream.CloseSend())
+		_, err = stream.Recv()
+		x.ErrorIs(err, io.EOF)
+		x.Equal([]string{"trailer"}, stream.Trailer().Get("timing"))
+	})
+	t.Run("stream with error", func(t *testing.T) {
+		x := require.New(t)
+
+		stream, err := client.Many(ctx, echo.EchoRequest_builder{
+			Status: echo.Status_builder{Code: int32(codes.NotFound), Message: "where's the money"}.Build(),
+		}.Build())
+		x.NoError(err)
+
+		_, err = stream.Recv()
+		x.Equal(codes.NotFound, status.Code(err))
+	})
+	t.Run("stream cancelled", func(t *testing.T) {
+		x := require.New(t)
+
+		ctx, cancel := context.WithCancel(ctx)
+		stream, err := client.Many(ctx, overVoid())
+		x.NoError(err)
+
+		time.AfterFunc(50*time.Millisecond, cancel)
+		_, err = stream.Recv()
+		x.Equal(codes.Canceled, status.Code(err))
+
+		// The connection is still usable.
+		res, err := client.Once(context.Background(), request("Lebowski", 0))
+		x.NoError(err)
+		x.Equal("Lebowski", res.GetMessage())
+	})
+}
+
+func TestConn_Close(t *testing.T) {
+	x := require.New(t)
+
+	ctx := context.Background()
+	conn, err := host.LoadFile(ctx, bridge, host.WithCompilationCache(cache))
+	x.NoError(err)
+
+	client := echo.NewEchoServiceClient(conn)
+	stream, err := client.Many(ctx, overVoid())
+	x.NoError(err)
+
+	x.NoError(conn.Close())
+
+	_, err = stream.Recv()
+	x.Error(err)
+
+	_, err = client.Once(ctx, request("Lebowski", 0))
+	x.Equal(codes.Unavailable, status.Code(err))
+}
+
+func TestConn_Close_Timeout(t *testing.T) {
+	x := require.New(t)
+
+	ctx := context.Background()
+	conn, err := host.LoadFile(ctx, bridge,
+		host.WithCompilationCache(cache),
+		host.WithEnv("ECHOBRIDGE_LINGER", "1"),
+		host.WithCloseTimeout(100*time.Millisecond),
+	)
+	x.NoError(err)
+
+	closed := make(chan error)
+	go func() { closed <- conn.Close() }()
+	select {
+	case err = <-closed:
+	case <-time.After(10 * time.Second):
+		x.FailNow("module is not terminated")
+	}
+	x.Error(err)
+}
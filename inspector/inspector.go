@@ -0,0 +1,404 @@
+package inspector
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var ErrClosed = errors.New("subscription closed")
+
+// Inspector records the calls made through the connections it is attached to
+// and broadcasts them as [Event]s to its subscribers.
+type Inspector struct {
+	UnimplementedInspectorServiceServer
+
+	conn_seq atomic.Uint32
+	call_seq atomic.Uint32
+
+	// Number of the subscriptions, read without the lock to decide
+	// whether messages are decoded.
+	sub_count atomic.Int32
+
+	mu    sync.Mutex
+	calls map[uint32]*Call
+	subs  map[*Subscription]struct{}
+}
+
+func New() *Inspector {
+	return &Inspector{
+		calls: map[uint32]*Call{},
+		subs:  map[*Subscription]struct{}{},
+	}
+}
+
+func (i *Inspector) Watch(req *WatchRequest, stream grpc.ServerStreamingServer[Event]) error {
+	sub := i.Subscribe()
+	defer sub.Close()
+
+	ctx := stream.Context()
+	for {
+		e, err := sub.Recv(ctx)
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(e); err != nil {
+			return err
+		}
+	}
+}
+
+// Subscribe starts a subscription of the events.
+// The first event of the subscription is the snapshot of the calls currently open.
+// Events are dropped if the subscriber does not keep up with them;
+// the next event it receives is then a new snapshot, and [Subscription.Dropped] counts them.
+func (i *Inspector) Subscribe() *Subscription {
+	s := &Subscription{
+		i:      i,
+		events: make(chan *Event, 1024),
+		done:   make(chan struct{}),
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	s.events <- i.snapshot()
+	i.subs[s] = struct{}{}
+	i.sub_count.Add(1)
+	return s
+}
+
+// snapshot returns an event of the calls currently open.
+// i.mu must be held.
+func (i *Inspector) snapshot() *Event {
+	calls := make([]*Call, 0, len(i.calls))
+	for _, c := range i.calls {
+		calls = append(calls, proto.Clone(c).(*Call))
+	}
+	return Event_builder{
+		Time:     timestamppb.Now(),
+		Snapshot: Snapshot_builder{Calls: calls}.Build(),
+	}.Build()
+}
+
+// publish sends the event to the subscribers.
+// i.mu must be held, and the calls must already be updated by the event
+// as a subscriber that dropped events gets a snapshot in place of it.
+func (i *Inspector) publish(e *Event) {
+	for s := range i.subs {
+		if s.gap {
+			select {
+			case s.events <- i.snapshot():
+				s.gap = false
+			default:
+				s.dropped.Add(1)
+			}
+			continue
+		}
+
+		select {
+		case s.events <- e:
+		default:
+			s.gap = true
+			s.dropped.Add(1)
+		}
+	}
+}
+
+// Dial returns a stats handler to be installed on a new connection.
+// [ConnHandler.Close] must be called when the connection is closed.
+func (i *Inspector) Dial() *ConnHandler {
+	h := &ConnHandler{
+		i:  i,
+		id: i.conn_seq.Add(1),
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.publish(Event_builder{
+		Time:       timestamppb.Now(),
+		ConnId:     h.id,
+		ConnDialed: &ConnDialed{},
+	}.Build())
+
+	return h
+}
+
+type Subscription struct {
+	i      *Inspector
+	events chan *Event
+
+	// Whether events are dropped since the last one sent.
+	// Guarded by i.mu.
+	gap     bool
+	dropped atomic.Uint64
+
+	done chan struct{}
+	once sync.Once
+}
+
+// Dropped returns the number of the events dropped as the subscriber did not keep up with them.
+func (s *Subscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Recv blocks until the next event or [ErrClosed] if the subscription is closed.
+func (s *Subscription) Recv(ctx context.Context) (*Event, error) {
+	select {
+	case <-s.done:
+		// Pending events are discarded once the subscription is closed.
+		return nil, ErrClosed
+	default:
+	}
+
+	select {
+	case e := <-s.events:
+		return e, nil
+	case <-s.done:
+		return nil, ErrClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		s.i.mu.Lock()
+		defer s.i.mu.Unlock()
+
+		delete(s.i.subs, s)
+		s.i.sub_count.Add(-1)
+		close(s.done)
+	})
+}
+
+var _ stats.Handler = (*ConnHandler)(nil)
+
+type ConnHandler struct {
+	i  *Inspector
+	id uint32
+
+	once sync.Once
+}
+
+func (h *ConnHandler) ID() uint32 {
+	return h.id
+}
+
+// Close reports the connection is closed.
+func (h *ConnHandler) Close() {
+	h.once.Do(func() {
+		h.i.mu.Lock()
+		defer h.i.mu.Unlock()
+
+		h.i.publish(Event_builder{
+			Time:       timestamppb.Now(),
+			ConnId:     h.id,
+			ConnClosed: &ConnClosed{},
+		}.Build())
+	})
+}
+
+type callCtxKey struct{}
+
+type callTag struct {
+	id     uint32
+	method string
+
+	// Descriptors of the request and the response.
+	// nil if not available.
+	in  protoreflect.MessageDescriptor
+	out protoreflect.MessageDescriptor
+}
+
+func (h *ConnHandler) TagRPC(ctx context.Context, info *stats.RPCTagInfo) context.Context {
+	if strings.HasPrefix(info.FullMethodName, "/"+InspectorService_ServiceDesc.ServiceName+"/") {
+		// Do not inspect the inspector.
+		return ctx
+	}
+
+	tag := &callTag{
+		id:     h.i.call_seq.Add(1),
+		method: info.FullMethodName,
+	}
+	if d := findMethod(info.FullMethodName); d != nil {
+		tag.in = d.Input()
+		tag.out = d.Output()
+	}
+
+	return context.WithValue(ctx, callCtxKey{}, tag)
+}
+
+func (h *ConnHandler) HandleRPC(ctx context.Context, s stats.RPCStats) {
+	tag, ok := ctx.Value(callCtxKey{}).(*callTag)
+	if !ok {
+		return
+	}
+
+	// Messages are decoded before taking the lock so slow ones do not hold up other calls.
+	var decoded *structpb.Value
+	if h.i.sub_count.Load() > 0 {
+		switch s := s.(type) {
+		case *stats.OutPayload:
+			decoded = decode(tag.in, s.Payload)
+		case *stats.InPayload:
+			decoded = decode(tag.out, s.Payload)
+		}
+	}
+
+	h.i.mu.Lock()
+	defer h.i.mu.Unlock()
+
+	b := Event_builder{
+		Time:   timestamppb.Now(),
+		ConnId: h.id,
+		CallId: tag.id,
+	}
+	switch s := s.(type) {
+	case *stats.Begin:
+		h.i.calls[tag.id] = Call_builder{
+			ConnId:       h.id,
+			CallId:       tag.id,
+			Method:       tag.method,
+			ClientStream: s.IsClientStream,
+			ServerStream: s.IsServerStream,
+			DateStarted:  timestamppb.New(s.BeginTime),
+		}.Build()
+		b.Time = timestamppb.New(s.BeginTime)
+		b.CallStarted = CallStarted_builder{
+			Method:       tag.method,
+			ClientStream: s.IsClientStream,
+			ServerStream: s.IsServerStream,
+		}.Build()
+
+	case *stats.OutHeader:
+		md := metaToProto(s.Header)
+		if c, ok := h.i.calls[tag.id]; ok {
+			c.SetHeader(md)
+		}
+		b.HeaderSent = HeaderSent_builder{Header: md}.Build()
+
+	case *stats.OutPayload:
+		if c, ok := h.i.calls[tag.id]; ok {
+			c.SetMessagesSent(c.GetMessagesSent() + 1)
+		}
+		b.MessageSent = MessageSent_builder{Size: uint32(s.Length), Decoded: decoded}.Build()
+
+	case *stats.InHeader:
+		b.HeaderReceived = HeaderReceived_builder{Header: metaToProto(s.Header)}.Build()
+
+	case *stats.InPayload:
+		if c, ok := h.i.calls[tag.id]; ok {
+			c.SetMessagesReceived(c.GetMessagesReceived() + 1)
+		}
+		b.MessageReceived = MessageReceived_builder{Size: uint32(s.Length), Decoded: decoded}.Build()
+
+	case *stats.InTrailer:
+		b.TrailerReceived = TrailerReceived_builder{Trailer: metaToProto(s.Trailer)}.Build()
+
+	case *stats.End:
+		delete(h.i.calls, tag.id)
+
+		st := status.Convert(s.Error)
+		b.Time = timestamppb.New(s.EndTime)
+		b.CallFinished = CallFinished_builder{
+			Code:     int32(st.Code()),
+			Message:  st.Message(),
+			Duration: durationpb.New(s.EndTime.Sub(s.BeginTime)),
+		}.Build()
+
+	default:
+		return
+	}
+
+	h.i.publish(b.Build())
+}
+
+func (h *ConnHandler) TagConn(ctx context.Context, info *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (h *ConnHandler) HandleConn(ctx context.Context, s stats.ConnStats) {}
+
+// findMethod finds a descriptor of the method in "/package.service/method" form
+// from the global registry.
+func findMethod(name string) protoreflect.MethodDescriptor {
+	name = strings.TrimPrefix(name, "/")
+	i := strings.LastIndex(name, "/")
+	if i < 0 {
+		return nil
+	}
+
+	d, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(name[:i]))
+	if err != nil {
+		return nil
+	}
+	sd, ok := d.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil
+	}
+
+	return sd.Methods().ByName(protoreflect.Name(name[i+1:]))
+}
+
+// decode decodes the payload into JSON value.
+// It returns nil if the message cannot be decoded.
+func decode(d protoreflect.MessageDescriptor, payload any) *structpb.Value {
+	if d == nil {
+		return nil
+	}
+
+	var data []byte
+	switch p := payload.(type) {
+	case []byte:
+		data = p
+	case *[]byte:
+		data = *p
+	case proto.Message:
+		var err error
+		if data, err = proto.Marshal(p); err != nil {
+			return nil
+		}
+	default:
+		return nil
+	}
+
+	m := dynamicpb.NewMessage(d)
+	if err := proto.Unmarshal(data, m); err != nil {
+		return nil
+	}
+	j, err := protojson.Marshal(m)
+	if err != nil {
+		return nil
+	}
+
+	v := &structpb.Value{}
+	if err := protojson.Unmarshal(j, v); err != nil {
+		return nil
+	}
+	return v
+}
+
+func metaToProto(md metadata.MD) *Metadata {
+	entries := make(map[string]*Metadata_Values, len(md))
+	for k, vs := range md {
+		entries[k] = Metadata_Values_builder{Values: vs}.Build()
+	}
+
+	return Metadata_builder{Entries: entries}.Build()
+}
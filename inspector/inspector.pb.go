@@ -0,0 +1,1809 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v5.29.0
+// source: inspector/inspector.proto
+
+package inspector
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type WatchRequest struct {
+	state         protoimpl.MessageState `protogen:"opaque.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchRequest) Reset() {
+	*x = WatchRequest{}
+	mi := &file_inspector_inspector_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchRequest) ProtoMessage() {}
+
+func (x *WatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_inspector_inspector_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+type WatchRequest_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+}
+
+func (b0 WatchRequest_builder) Build() *WatchRequest {
+	m0 := &WatchRequest{}
+	b, x := &b0, m0
+	_, _ = b, x
+	return m0
+}
+
+type Event struct {
+	state             protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Time   *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=time,proto3"`
+	xxx_hidden_ConnId uint32                 `protobuf:"varint,2,opt,name=conn_id,json=connId,proto3"`
+	xxx_hidden_CallId uint32                 `protobuf:"varint,3,opt,name=call_id,json=callId,proto3"`
+	xxx_hidden_Kind   isEvent_Kind           `protobuf_oneof:"kind"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	mi := &file_inspector_inspector_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_inspector_inspector_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *Event) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.xxx_hidden_Time
+	}
+	return nil
+}
+
+func (x *Event) GetConnId() uint32 {
+	if x != nil {
+		return x.xxx_hidden_ConnId
+	}
+	return 0
+}
+
+func (x *Event) GetCallId() uint32 {
+	if x != nil {
+		return x.xxx_hidden_CallId
+	}
+	return 0
+}
+
+func (x *Event) GetSnapshot() *Snapshot {
+	if x != nil {
+		if x, ok := x.xxx_hidden_Kind.(*event_Snapshot); ok {
+			return x.Snapshot
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetConnDialed() *ConnDialed {
+	if x != nil {
+		if x, ok := x.xxx_hidden_Kind.(*event_ConnDialed); ok {
+			return x.ConnDialed
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetConnClosed() *ConnClosed {
+	if x != nil {
+		if x, ok := x.xxx_hidden_Kind.(*event_ConnClosed); ok {
+			return x.ConnClosed
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetCallStarted() *CallStarted {
+	if x != nil {
+		if x, ok := x.xxx_hidden_Kind.(*event_CallStarted); ok {
+			return x.CallStarted
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetHeaderSent() *HeaderSent {
+	if x != nil {
+		if x, ok := x.xxx_hidden_Kind.(*event_HeaderSent); ok {
+			return x.HeaderSent
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetMessageSent() *MessageSent {
+	if x != nil {
+		if x, ok := x.xxx_hidden_Kind.(*event_MessageSent); ok {
+			return x.MessageSent
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetHeaderReceived() *HeaderReceived {
+	if x != nil {
+		if x, ok := x.xxx_hidden_Kind.(*event_HeaderReceived); ok {
+			return x.HeaderReceived
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetMessageReceived() *MessageReceived {
+	if x != nil {
+		if x, ok := x.xxx_hidden_Kind.(*event_MessageReceived); ok {
+			return x.MessageReceived
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetTrailerReceived() *TrailerReceived {
+	if x != nil {
+		if x, ok := x.xxx_hidden_Kind.(*event_TrailerReceived); ok {
+			return x.TrailerReceived
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetCallFinished() *CallFinished {
+	if x != nil {
+		if x, ok := x.xxx_hidden_Kind.(*event_CallFinished); ok {
+			return x.CallFinished
+		}
+	}
+	return nil
+}
+
+func (x *Event) SetTime(v *timestamppb.Timestamp) {
+	x.xxx_hidden_Time = v
+}
+
+func (x *Event) SetConnId(v uint32) {
+	x.xxx_hidden_ConnId = v
+}
+
+func (x *Event) SetCallId(v uint32) {
+	x.xxx_hidden_CallId = v
+}
+
+func (x *Event) SetSnapshot(v *Snapshot) {
+	if v == nil {
+		x.xxx_hidden_Kind = nil
+		return
+	}
+	x.xxx_hidden_Kind = &event_Snapshot{v}
+}
+
+func (x *Event) SetConnDialed(v *ConnDialed) {
+	if v == nil {
+		x.xxx_hidden_Kind = nil
+		return
+	}
+	x.xxx_hidden_Kind = &event_ConnDialed{v}
+}
+
+func (x *Event) SetConnClosed(v *ConnClosed) {
+	if v == nil {
+		x.xxx_hidden_Kind = nil
+		return
+	}
+	x.xxx_hidden_Kind = &event_ConnClosed{v}
+}
+
+func (x *Event) SetCallStarted(v *CallStarted) {
+	if v == nil {
+		x.xxx_hidden_Kind = nil
+		return
+	}
+	x.xxx_hidden_Kind = &event_CallStarted{v}
+}
+
+func (x *Event) SetHeaderSent(v *HeaderSent) {
+	if v == nil {
+		x.xxx_hidden_Kind = nil
+		return
+	}
+	x.xxx_hidden_Kind = &event_HeaderSent{v}
+}
+
+func (x *Event) SetMessageSent(v *MessageSent) {
+	if v == nil {
+		x.xxx_hidden_Kind = nil
+		return
+	}
+	x.xxx_hidden_Kind = &event_MessageSent{v}
+}
+
+func (x *Event) SetHeaderReceived(v *HeaderReceived) {
+	if v == nil {
+		x.xxx_hidden_Kind = nil
+		return
+	}
+	x.xxx_hidden_Kind = &event_HeaderReceived{v}
+}
+
+func (x *Event) SetMessageReceived(v *MessageReceived) {
+	if v == nil {
+		x.xxx_hidden_Kind = nil
+		return
+	}
+	x.xxx_hidden_Kind = &event_MessageReceived{v}
+}
+
+func (x *Event) SetTrailerReceived(v *TrailerReceived) {
+	if v == nil {
+		x.xxx_hidden_Kind = nil
+		return
+	}
+	x.xxx_hidden_Kind = &event_TrailerReceived{v}
+}
+
+func (x *Event) SetCallFinished(v *CallFinished) {
+	if v == nil {
+		x.xxx_hidden_Kind = nil
+		return
+	}
+	x.xxx_hidden_Kind = &event_CallFinished{v}
+}
+
+func (x *Event) HasTime() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_Time != nil
+}
+
+func (x *Event) HasKind() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_Kind != nil
+}
+
+func (x *Event) HasSnapshot() bool {
+	if x == nil {
+		return false
+	}
+	_, ok := x.xxx_hidden_Kind.(*event_Snapshot)
+	return ok
+}
+
+func (x *Event) HasConnDialed() bool {
+	if x == nil {
+		return false
+	}
+	_, ok := x.xxx_hidden_Kind.(*event_ConnDialed)
+	return ok
+}
+
+func (x *Event) HasConnClosed() bool {
+	if x == nil {
+		return false
+	}
+	_, ok := x.xxx_hidden_Kind.(*event_ConnClosed)
+	return ok
+}
+
+func (x *Event) HasCallStarted() bool {
+	if x == nil {
+		return false
+	}
+	_, ok := x.xxx_hidden_Kind.(*event_CallStarted)
+	return ok
+}
+
+func (x *Event) HasHeaderSent() bool {
+	if x == nil {
+		return false
+	}
+	_, ok := x.xxx_hidden_Kind.(*event_HeaderSent)
+	return ok
+}
+
+func (x *Event) HasMessageSent() bool {
+	if x == nil {
+		return false
+	}
+	_, ok := x.xxx_hidden_Kind.(*event_MessageSent)
+	return ok
+}
+
+func (x *Event) HasHeaderReceived() bool {
+	if x == nil {
+		return false
+	}
+	_, ok := x.xxx_hidden_Kind.(*event_HeaderReceived)
+	return ok
+}
+
+func (x *Event) HasMessageReceived() bool {
+	if x == nil {
+		return false
+	}
+	_, ok := x.xxx_hidden_Kind.(*event_MessageReceived)
+	return ok
+}
+
+func (x *Event) HasTrailerReceived() bool {
+	if x == nil {
+		return false
+	}
+	_, ok := x.xxx_hidden_Kind.(*event_TrailerReceived)
+	return ok
+}
+
+func (x *Event) HasCallFinished() bool {
+	if x == nil {
+		return false
+	}
+	_, ok := x.xxx_hidden_Kind.(*event_CallFinished)
+	return ok
+}
+
+func (x *Event) ClearTime() {
+	x.xxx_hidden_Time = nil
+}
+
+func (x *Event) ClearKind() {
+	x.xxx_hidden_Kind = nil
+}
+
+func (x *Event) ClearSnapshot() {
+	if _, ok := x.xxx_hidden_Kind.(*event_Snapshot); ok {
+		x.xxx_hidden_Kind = nil
+	}
+}
+
+func (x *Event) ClearConnDialed() {
+	if _, ok := x.xxx_hidden_Kind.(*event_ConnDialed); ok {
+		x.xxx_hidden_Kind = nil
+	}
+}
+
+func (x *Event) ClearConnClosed() {
+	if _, ok := x.xxx_hidden_Kind.(*event_ConnClosed); ok {
+		x.xxx_hidden_Kind = nil
+	}
+}
+
+func (x *Event) ClearCallStarted() {
+	if _, ok := x.xxx_hidden_Kind.(*event_CallStarted); ok {
+		x.xxx_hidden_Kind = nil
+	}
+}
+
+func (x *Event) ClearHeaderSent() {
+	if _, ok := x.xxx_hidden_Kind.(*event_HeaderSent); ok {
+		x.xxx_hidden_Kind = nil
+	}
+}
+
+func (x *Event) ClearMessageSent() {
+	if _, ok := x.xxx_hidden_Kind.(*event_MessageSent); ok {
+		x.xxx_hidden_Kind = nil
+	}
+}
+
+func (x *Event) ClearHeaderReceived() {
+	if _, ok := x.xxx_hidden_Kind.(*event_HeaderReceived); ok {
+		x.xxx_hidden_Kind = nil
+	}
+}
+
+func (x *Event) ClearMessageReceived() {
+	if _, ok := x.xxx_hidden_Kind.(*event_MessageReceived); ok {
+		x.xxx_hidden_Kind = nil
+	}
+}
+
+func (x *Event) ClearTrailerReceived() {
+	if _, ok := x.xxx_hidden_Kind.(*event_TrailerReceived); ok {
+		x.xxx_hidden_Kind = nil
+	}
+}
+
+func (x *Event) ClearCallFinished() {
+	if _, ok := x.xxx_hidden_Kind.(*event_CallFinished); ok {
+		x.xxx_hidden_Kind = nil
+	}
+}
+
+const Event_Kind_not_set_case case_Event_Kind = 0
+const Event_Snapshot_case case_Event_Kind = 10
+const Event_ConnDialed_case case_Event_Kind = 11
+const Event_ConnClosed_case case_Event_Kind = 12
+const Event_CallStarted_case case_Event_Kind = 13
+const Event_HeaderSent_case case_Event_Kind = 14
+const Event_MessageSent_case case_Event_Kind = 15
+const Event_HeaderReceived_case case_Event_Kind = 16
+const Event_MessageReceived_case case_Event_Kind = 17
+const Event_TrailerReceived_case case_Event_Kind = 18
+const Event_CallFinished_case case_Event_Kind = 19
+
+func (x *Event) WhichKind() case_Event_Kind {
+	if x == nil {
+		return Event_Kind_not_set_case
+	}
+	switch x.xxx_hidden_Kind.(type) {
+	case *event_Snapshot:
+		return Event_Snapshot_case
+	case *event_ConnDialed:
+		return Event_ConnDialed_case
+	case *event_ConnClosed:
+		return Event_ConnClosed_case
+	case *event_CallStarted:
+		return Event_CallStarted_case
+	case *event_HeaderSent:
+		return Event_HeaderSent_case
+	case *event_MessageSent:
+		return Event_MessageSent_case
+	case *event_HeaderReceived:
+		return Event_HeaderReceived_case
+	case *event_MessageReceived:
+		return Event_MessageReceived_case
+	case *event_TrailerReceived:
+		return Event_TrailerReceived_case
+	case *event_CallFinished:
+		return Event_CallFinished_case
+	default:
+		return Event_Kind_not_set_case
+	}
+}
+
+type Event_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Time   *timestamppb.Timestamp
+	ConnId uint32
+	// 0 for the events of the connection.
+	CallId uint32
+	// Fields of oneof xxx_hidden_Kind:
+	Snapshot        *Snapshot
+	ConnDialed      *ConnDialed
+	ConnClosed      *ConnClosed
+	CallStarted     *CallStarted
+	HeaderSent      *HeaderSent
+	MessageSent     *MessageSent
+	HeaderReceived  *HeaderReceived
+	MessageReceived *MessageReceived
+	TrailerReceived *TrailerReceived
+	CallFinished    *CallFinished
+	// -- end of xxx_hidden_Kind
+}
+
+func (b0 Event_builder) Build() *Event {
+	m0 := &Event{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.xxx_hidden_Time = b.Time
+	x.xxx_hidden_ConnId = b.ConnId
+	x.xxx_hidden_CallId = b.CallId
+	if b.Snapshot != nil {
+		x.xxx_hidden_Kind = &event_Snapshot{b.Snapshot}
+	}
+	if b.ConnDialed != nil {
+		x.xxx_hidden_Kind = &event_ConnDialed{b.ConnDialed}
+	}
+	if b.ConnClosed != nil {
+		x.xxx_hidden_Kind = &event_ConnClosed{b.ConnClosed}
+	}
+	if b.CallStarted != nil {
+		x.xxx_hidden_Kind = &event_CallStarted{b.CallStarted}
+	}
+	if b.HeaderSent != nil {
+		x.xxx_hidden_Kind = &event_HeaderSent{b.HeaderSent}
+	}
+	if b.MessageSent != nil {
+		x.xxx_hidden_Kind = &event_MessageSent{b.MessageSent}
+	}
+	if b.HeaderReceived != nil {
+		x.xxx_hidden_Kind = &event_HeaderReceived{b.HeaderReceived}
+	}
+	if b.MessageReceived != nil {
+		x.xxx_hidden_Kind = &event_MessageReceived{b.MessageReceived}
+	}
+	if b.TrailerReceived != nil {
+		x.xxx_hidden_Kind = &event_TrailerReceived{b.TrailerReceived}
+	}
+	if b.CallFinished != nil {
+		x.xxx_hidden_Kind = &event_CallFinished{b.CallFinished}
+	}
+	return m0
+}
+
+type case_Event_Kind protoreflect.FieldNumber
+
+func (x case_Event_Kind) String() string {
+	md := file_inspector_inspector_proto_msgTypes[1].Descriptor()
+	if x == 0 {
+		return "not set"
+	}
+	return protoimpl.X.MessageFieldStringOf(md, protoreflect.FieldNumber(x))
+}
+
+type isEvent_Kind interface {
+	isEvent_Kind()
+}
+
+type event_Snapshot struct {
+	Snapshot *Snapshot `protobuf:"bytes,10,opt,name=snapshot,proto3,oneof"`
+}
+
+type event_ConnDialed struct {
+	ConnDialed *ConnDialed `protobuf:"bytes,11,opt,name=conn_dialed,json=connDialed,proto3,oneof"`
+}
+
+type event_ConnClosed struct {
+	ConnClosed *ConnClosed `protobuf:"bytes,12,opt,name=conn_closed,json=connClosed,proto3,oneof"`
+}
+
+type event_CallStarted struct {
+	CallStarted *CallStarted `protobuf:"bytes,13,opt,name=call_started,json=callStarted,proto3,oneof"`
+}
+
+type event_HeaderSent struct {
+	HeaderSent *HeaderSent `protobuf:"bytes,14,opt,name=header_sent,json=headerSent,proto3,oneof"`
+}
+
+type event_MessageSent struct {
+	MessageSent *MessageSent `protobuf:"bytes,15,opt,name=message_sent,json=messageSent,proto3,oneof"`
+}
+
+type event_HeaderReceived struct {
+	HeaderReceived *HeaderReceived `protobuf:"bytes,16,opt,name=header_received,json=headerReceived,proto3,oneof"`
+}
+
+type event_MessageReceived struct {
+	MessageReceived *MessageReceived `protobuf:"bytes,17,opt,name=message_received,json=messageReceived,proto3,oneof"`
+}
+
+type event_TrailerReceived struct {
+	TrailerReceived *TrailerReceived `protobuf:"bytes,18,opt,name=trailer_received,json=trailerReceived,proto3,oneof"`
+}
+
+type event_CallFinished struct {
+	CallFinished *CallFinished `protobuf:"bytes,19,opt,name=call_finished,json=callFinished,proto3,oneof"`
+}
+
+func (*event_Snapshot) isEvent_Kind() {}
+
+func (*event_ConnDialed) isEvent_Kind() {}
+
+func (*event_ConnClosed) isEvent_Kind() {}
+
+func (*event_CallStarted) isEvent_Kind() {}
+
+func (*event_HeaderSent) isEvent_Kind() {}
+
+func (*event_MessageSent) isEvent_Kind() {}
+
+func (*event_HeaderReceived) isEvent_Kind() {}
+
+func (*event_MessageReceived) isEvent_Kind() {}
+
+func (*event_TrailerReceived) isEvent_Kind() {}
+
+func (*event_CallFinished) isEvent_Kind() {}
+
+type Metadata struct {
+	state              protoimpl.MessageState      `protogen:"opaque.v1"`
+	xxx_hidden_Entries map[string]*Metadata_Values `protobuf:"bytes,1,rep,name=entries,proto3" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *Metadata) Reset() {
+	*x = Metadata{}
+	mi := &file_inspector_inspector_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Metadata) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Metadata) ProtoMessage() {}
+
+func (x *Metadata) ProtoReflect() protoreflect.Message {
+	mi := &file_inspector_inspector_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *Metadata) GetEntries() map[string]*Metadata_Values {
+	if x != nil {
+		return x.xxx_hidden_Entries
+	}
+	return nil
+}
+
+func (x *Metadata) SetEntries(v map[string]*Metadata_Values) {
+	x.xxx_hidden_Entries = v
+}
+
+type Metadata_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Entries map[string]*Metadata_Values
+}
+
+func (b0 Metadata_builder) Build() *Metadata {
+	m0 := &Metadata{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.xxx_hidden_Entries = b.Entries
+	return m0
+}
+
+type Call struct {
+	state                       protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_ConnId           uint32                 `protobuf:"varint,1,opt,name=conn_id,json=connId,proto3"`
+	xxx_hidden_CallId           uint32                 `protobuf:"varint,2,opt,name=call_id,json=callId,proto3"`
+	xxx_hidden_Method           string                 `protobuf:"bytes,3,opt,name=method,proto3"`
+	xxx_hidden_ClientStream     bool                   `protobuf:"varint,4,opt,name=client_stream,json=clientStream,proto3"`
+	xxx_hidden_ServerStream     bool                   `protobuf:"varint,5,opt,name=server_stream,json=serverStream,proto3"`
+	xxx_hidden_DateStarted      *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=date_started,json=dateStarted,proto3"`
+	xxx_hidden_Header           *Metadata              `protobuf:"bytes,7,opt,name=header,proto3"`
+	xxx_hidden_MessagesSent     uint32                 `protobuf:"varint,8,opt,name=messages_sent,json=messagesSent,proto3"`
+	xxx_hidden_MessagesReceived uint32                 `protobuf:"varint,9,opt,name=messages_received,json=messagesReceived,proto3"`
+	unknownFields               protoimpl.UnknownFields
+	sizeCache                   protoimpl.SizeCache
+}
+
+func (x *Call) Reset() {
+	*x = Call{}
+	mi := &file_inspector_inspector_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Call) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Call) ProtoMessage() {}
+
+func (x *Call) ProtoReflect() protoreflect.Message {
+	mi := &file_inspector_inspector_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *Call) GetConnId() uint32 {
+	if x != nil {
+		return x.xxx_hidden_ConnId
+	}
+	return 0
+}
+
+func (x *Call) GetCallId() uint32 {
+	if x != nil {
+		return x.xxx_hidden_CallId
+	}
+	return 0
+}
+
+func (x *Call) GetMethod() string {
+	if x != nil {
+		return x.xxx_hidden_Method
+	}
+	return ""
+}
+
+func (x *Call) GetClientStream() bool {
+	if x != nil {
+		return x.xxx_hidden_ClientStream
+	}
+	return false
+}
+
+func (x *Call) GetServerStream() bool {
+	if x != nil {
+		return x.xxx_hidden_ServerStream
+	}
+	return false
+}
+
+func (x *Call) GetDateStarted() *timestamppb.Timestamp {
+	if x != nil {
+		return x.xxx_hidden_DateStarted
+	}
+	return nil
+}
+
+func (x *Call) GetHeader() *Metadata {
+	if x != nil {
+		return x.xxx_hidden_Header
+	}
+	return nil
+}
+
+func (x *Call) GetMessagesSent() uint32 {
+	if x != nil {
+		return x.xxx_hidden_MessagesSent
+	}
+	return 0
+}
+
+func (x *Call) GetMessagesReceived() uint32 {
+	if x != nil {
+		return x.xxx_hidden_MessagesReceived
+	}
+	return 0
+}
+
+func (x *Call) SetConnId(v uint32) {
+	x.xxx_hidden_ConnId = v
+}
+
+func (x *Call) SetCallId(v uint32) {
+	x.xxx_hidden_CallId = v
+}
+
+func (x *Call) SetMethod(v string) {
+	x.xxx_hidden_Method = v
+}
+
+func (x *Call) SetClientStream(v bool) {
+	x.xxx_hidden_ClientStream = v
+}
+
+func (x *Call) SetServerStream(v bool) {
+	x.xxx_hidden_ServerStream = v
+}
+
+func (x *Call) SetDateStarted(v *timestamppb.Timestamp) {
+	x.xxx_hidden_DateStarted = v
+}
+
+func (x *Call) SetHeader(v *Metadata) {
+	x.xxx_hidden_Header = v
+}
+
+func (x *Call) SetMessagesSent(v uint32) {
+	x.xxx_hidden_MessagesSent = v
+}
+
+func (x *Call) SetMessagesReceived(v uint32) {
+	x.xxx_hidden_MessagesReceived = v
+}
+
+func (x *Call) HasDateStarted() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_DateStarted != nil
+}
+
+func (x *Call) HasHeader() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_Header != nil
+}
+
+func (x *Call) ClearDateStarted() {
+	x.xxx_hidden_DateStarted = nil
+}
+
+func (x *Call) ClearHeader() {
+	x.xxx_hidden_Header = nil
+}
+
+type Call_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	ConnId           uint32
+	CallId           uint32
+	Method           string
+	ClientStream     bool
+	ServerStream     bool
+	DateStarted      *timestamppb.Timestamp
+	Header           *Metadata
+	MessagesSent     uint32
+	MessagesReceived uint32
+}
+
+func (b0 Call_builder) Build() *Call {
+	m0 := &Call{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.xxx_hidden_ConnId = b.ConnId
+	x.xxx_hidden_CallId = b.CallId
+	x.xxx_hidden_Method = b.Method
+	x.xxx_hidden_ClientStream = b.ClientStream
+	x.xxx_hidden_ServerStream = b.ServerStream
+	x.xxx_hidden_DateStarted = b.DateStarted
+	x.xxx_hidden_Header = b.Header
+	x.xxx_hidden_MessagesSent = b.MessagesSent
+	x.xxx_hidden_MessagesReceived = b.MessagesReceived
+	return m0
+}
+
+type Snapshot struct {
+	state            protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Calls *[]*Call               `protobuf:"bytes,1,rep,name=calls,proto3"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *Snapshot) Reset() {
+	*x = Snapshot{}
+	mi := &file_inspector_inspector_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Snapshot) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Snapshot) ProtoMessage() {}
+
+func (x *Snapshot) ProtoReflect() protoreflect.Message {
+	mi := &file_inspector_inspector_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *Snapshot) GetCalls() []*Call {
+	if x != nil {
+		if x.xxx_hidden_Calls != nil {
+			return *x.xxx_hidden_Calls
+		}
+	}
+	return nil
+}
+
+func (x *Snapshot) SetCalls(v []*Call) {
+	x.xxx_hidden_Calls = &v
+}
+
+type Snapshot_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Calls []*Call
+}
+
+func (b0 Snapshot_builder) Build() *Snapshot {
+	m0 := &Snapshot{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.xxx_hidden_Calls = &b.Calls
+	return m0
+}
+
+type ConnDialed struct {
+	state         protoimpl.MessageState `protogen:"opaque.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConnDialed) Reset() {
+	*x = ConnDialed{}
+	mi := &file_inspector_inspector_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConnDialed) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConnDialed) ProtoMessage() {}
+
+func (x *ConnDialed) ProtoReflect() protoreflect.Message {
+	mi := &file_inspector_inspector_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+type ConnDialed_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+}
+
+func (b0 ConnDialed_builder) Build() *ConnDialed {
+	m0 := &ConnDialed{}
+	b, x := &b0, m0
+	_, _ = b, x
+	return m0
+}
+
+type ConnClosed struct {
+	state         protoimpl.MessageState `protogen:"opaque.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConnClosed) Reset() {
+	*x = ConnClosed{}
+	mi := &file_inspector_inspector_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConnClosed) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConnClosed) ProtoMessage() {}
+
+func (x *ConnClosed) ProtoReflect() protoreflect.Message {
+	mi := &file_inspector_inspector_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+type ConnClosed_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+}
+
+func (b0 ConnClosed_builder) Build() *ConnClosed {
+	m0 := &ConnClosed{}
+	b, x := &b0, m0
+	_, _ = b, x
+	return m0
+}
+
+type CallStarted struct {
+	state                   protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Method       string                 `protobuf:"bytes,1,opt,name=method,proto3"`
+	xxx_hidden_ClientStream bool                   `protobuf:"varint,2,opt,name=client_stream,json=clientStream,proto3"`
+	xxx_hidden_ServerStream bool                   `protobuf:"varint,3,opt,name=server_stream,json=serverStream,proto3"`
+	unknownFields           protoimpl.UnknownFields
+	sizeCache               protoimpl.SizeCache
+}
+
+func (x *CallStarted) Reset() {
+	*x = CallStarted{}
+	mi := &file_inspector_inspector_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CallStarted) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CallStarted) ProtoMessage() {}
+
+func (x *CallStarted) ProtoReflect() protoreflect.Message {
+	mi := &file_inspector_inspector_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *CallStarted) GetMethod() string {
+	if x != nil {
+		return x.xxx_hidden_Method
+	}
+	return ""
+}
+
+func (x *CallStarted) GetClientStream() bool {
+	if x != nil {
+		return x.xxx_hidden_ClientStream
+	}
+	return false
+}
+
+func (x *CallStarted) GetServerStream() bool {
+	if x != nil {
+		return x.xxx_hidden_ServerStream
+	}
+	return false
+}
+
+func (x *CallStarted) SetMethod(v string) {
+	x.xxx_hidden_Method = v
+}
+
+func (x *CallStarted) SetClientStream(v bool) {
+	x.xxx_hidden_ClientStream = v
+}
+
+func (x *CallStarted) SetServerStream(v bool) {
+	x.xxx_hidden_ServerStream = v
+}
+
+type CallStarted_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Method       string
+	ClientStream bool
+	ServerStream bool
+}
+
+func (b0 CallStarted_builder) Build() *CallStarted {
+	m0 := &CallStarted{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.xxx_hidden_Method = b.Method
+	x.xxx_hidden_ClientStream = b.ClientStream
+	x.xxx_hidden_ServerStream = b.ServerStream
+	return m0
+}
+
+type HeaderSent struct {
+	state             protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Header *Metadata              `protobuf:"bytes,1,opt,name=header,proto3"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *HeaderSent) Reset() {
+	*x = HeaderSent{}
+	mi := &file_inspector_inspector_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HeaderSent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeaderSent) ProtoMessage() {}
+
+func (x *HeaderSent) ProtoReflect() protoreflect.Message {
+	mi := &file_inspector_inspector_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *HeaderSent) GetHeader() *Metadata {
+	if x != nil {
+		return x.xxx_hidden_Header
+	}
+	return nil
+}
+
+func (x *HeaderSent) SetHeader(v *Metadata) {
+	x.xxx_hidden_Header = v
+}
+
+func (x *HeaderSent) HasHeader() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_Header != nil
+}
+
+func (x *HeaderSent) ClearHeader() {
+	x.xxx_hidden_Header = nil
+}
+
+type HeaderSent_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Header *Metadata
+}
+
+func (b0 HeaderSent_builder) Build() *HeaderSent {
+	m0 := &HeaderSent{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.xxx_hidden_Header = b.Header
+	return m0
+}
+
+type MessageSent struct {
+	state              protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Size    uint32                 `protobuf:"varint,1,opt,name=size,proto3"`
+	xxx_hidden_Decoded *structpb.Value        `protobuf:"bytes,2,opt,name=decoded,proto3"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *MessageSent) Reset() {
+	*x = MessageSent{}
+	mi := &file_inspector_inspector_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MessageSent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MessageSent) ProtoMessage() {}
+
+func (x *MessageSent) ProtoReflect() protoreflect.Message {
+	mi := &file_inspector_inspector_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *MessageSent) GetSize() uint32 {
+	if x != nil {
+		return x.xxx_hidden_Size
+	}
+	return 0
+}
+
+func (x *MessageSent) GetDecoded() *structpb.Value {
+	if x != nil {
+		return x.xxx_hidden_Decoded
+	}
+	return nil
+}
+
+func (x *MessageSent) SetSize(v uint32) {
+	x.xxx_hidden_Size = v
+}
+
+func (x *MessageSent) SetDecoded(v *structpb.Value) {
+	x.xxx_hidden_Decoded = v
+}
+
+func (x *MessageSent) HasDecoded() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_Decoded != nil
+}
+
+func (x *MessageSent) ClearDecoded() {
+	x.xxx_hidden_Decoded = nil
+}
+
+type MessageSent_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Size uint32
+	// Message decoded in protobuf JSON mapping.
+	// Empty if descriptor of the message is not available.
+	Decoded *structpb.Value
+}
+
+func (b0 MessageSent_builder) Build() *MessageSent {
+	m0 := &MessageSent{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.xxx_hidden_Size = b.Size
+	x.xxx_hidden_Decoded = b.Decoded
+	return m0
+}
+
+type HeaderReceived struct {
+	state             protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Header *Metadata              `protobuf:"bytes,1,opt,name=header,proto3"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *HeaderReceived) Reset() {
+	*x = HeaderReceived{}
+	mi := &file_inspector_inspector_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HeaderReceived) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeaderReceived) ProtoMessage() {}
+
+func (x *HeaderReceived) ProtoReflect() protoreflect.Message {
+	mi := &file_inspector_inspector_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *HeaderReceived) GetHeader() *Metadata {
+	if x != nil {
+		return x.xxx_hidden_Header
+	}
+	return nil
+}
+
+func (x *HeaderReceived) SetHeader(v *Metadata) {
+	x.xxx_hidden_Header = v
+}
+
+func (x *HeaderReceived) HasHeader() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_Header != nil
+}
+
+func (x *HeaderReceived) ClearHeader() {
+	x.xxx_hidden_Header = nil
+}
+
+type HeaderReceived_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Header *Metadata
+}
+
+func (b0 HeaderReceived_builder) Build() *HeaderReceived {
+	m0 := &HeaderReceived{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.xxx_hidden_Header = b.Header
+	return m0
+}
+
+type MessageReceived struct {
+	state              protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Size    uint32                 `protobuf:"varint,1,opt,name=size,proto3"`
+	xxx_hidden_Decoded *structpb.Value        `protobuf:"bytes,2,opt,name=decoded,proto3"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *MessageReceived) Reset() {
+	*x = MessageReceived{}
+	mi := &file_inspector_inspector_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MessageReceived) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MessageReceived) ProtoMessage() {}
+
+func (x *MessageReceived) ProtoReflect() protoreflect.Message {
+	mi := &file_inspector_inspector_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *MessageReceived) GetSize() uint32 {
+	if x != nil {
+		return x.xxx_hidden_Size
+	}
+	return 0
+}
+
+func (x *MessageReceived) GetDecoded() *structpb.Value {
+	if x != nil {
+		return x.xxx_hidden_Decoded
+	}
+	return nil
+}
+
+func (x *MessageReceived) SetSize(v uint32) {
+	x.xxx_hidden_Size = v
+}
+
+func (x *MessageReceived) SetDecoded(v *structpb.Value) {
+	x.xxx_hidden_Decoded = v
+}
+
+func (x *MessageReceived) HasDecoded() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_Decoded != nil
+}
+
+func (x *MessageReceived) ClearDecoded() {
+	x.xxx_hidden_Decoded = nil
+}
+
+type MessageReceived_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Size uint32
+	// Message decoded in protobuf JSON mapping.
+	// Empty if descriptor of the message is not available.
+	Decoded *structpb.Value
+}
+
+func (b0 MessageReceived_builder) Build() *MessageReceived {
+	m0 := &MessageReceived{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.xxx_hidden_Size = b.Size
+	x.xxx_hidden_Decoded = b.Decoded
+	return m0
+}
+
+type TrailerReceived struct {
+	state              protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Trailer *Metadata              `protobuf:"bytes,1,opt,name=trailer,proto3"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *TrailerReceived) Reset() {
+	*x = TrailerReceived{}
+	mi := &file_inspector_inspector_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TrailerReceived) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrailerReceived) ProtoMessage() {}
+
+func (x *TrailerReceived) ProtoReflect() protoreflect.Message {
+	mi := &file_inspector_inspector_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *TrailerReceived) GetTrailer() *Metadata {
+	if x != nil {
+		return x.xxx_hidden_Trailer
+	}
+	return nil
+}
+
+func (x *TrailerReceived) SetTrailer(v *Metadata) {
+	x.xxx_hidden_Trailer = v
+}
+
+func (x *TrailerReceived) HasTrailer() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_Trailer != nil
+}
+
+func (x *TrailerReceived) ClearTrailer() {
+	x.xxx_hidden_Trailer = nil
+}
+
+type TrailerReceived_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Trailer *Metadata
+}
+
+func (b0 TrailerReceived_builder) Build() *TrailerReceived {
+	m0 := &TrailerReceived{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.xxx_hidden_Trailer = b.Trailer
+	return m0
+}
+
+type CallFinished struct {
+	state               protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Code     int32                  `protobuf:"varint,1,opt,name=code,proto3"`
+	xxx_hidden_Message  string                 `protobuf:"bytes,2,opt,name=message,proto3"`
+	xxx_hidden_Duration *durationpb.Duration   `protobuf:"bytes,3,opt,name=duration,proto3"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *CallFinished) Reset() {
+	*x = CallFinished{}
+	mi := &file_inspector_inspector_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CallFinished) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CallFinished) ProtoMessage() {}
+
+func (x *CallFinished) ProtoReflect() protoreflect.Message {
+	mi := &file_inspector_inspector_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *CallFinished) GetCode() int32 {
+	if x != nil {
+		return x.xxx_hidden_Code
+	}
+	return 0
+}
+
+func (x *CallFinished) GetMessage() string {
+	if x != nil {
+		return x.xxx_hidden_Message
+	}
+	return ""
+}
+
+func (x *CallFinished) GetDuration() *durationpb.Duration {
+	if x != nil {
+		return x.xxx_hidden_Duration
+	}
+	return nil
+}
+
+func (x *CallFinished) SetCode(v int32) {
+	x.xxx_hidden_Code = v
+}
+
+func (x *CallFinished) SetMessage(v string) {
+	x.xxx_hidden_Message = v
+}
+
+func (x *CallFinished) SetDuration(v *durationpb.Duration) {
+	x.xxx_hidden_Duration = v
+}
+
+func (x *CallFinished) HasDuration() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_Duration != nil
+}
+
+func (x *CallFinished) ClearDuration() {
+	x.xxx_hidden_Duration = nil
+}
+
+type CallFinished_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Code     int32
+	Message  string
+	Duration *durationpb.Duration
+}
+
+func (b0 CallFinished_builder) Build() *CallFinished {
+	m0 := &CallFinished{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.xxx_hidden_Code = b.Code
+	x.xxx_hidden_Message = b.Message
+	x.xxx_hidden_Duration = b.Duration
+	return m0
+}
+
+type Metadata_Values struct {
+	state             protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Values []string               `protobuf:"bytes,1,rep,name=values,proto3"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *Metadata_Values) Reset() {
+	*x = Metadata_Values{}
+	mi := &file_inspector_inspector_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Metadata_Values) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Metadata_Values) ProtoMessage() {}
+
+func (x *Metadata_Values) ProtoReflect() protoreflect.Message {
+	mi := &file_inspector_inspector_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *Metadata_Values) GetValues() []string {
+	if x != nil {
+		return x.xxx_hidden_Values
+	}
+	return nil
+}
+
+func (x *Metadata_Values) SetValues(v []string) {
+	x.xxx_hidden_Values = v
+}
+
+type Metadata_Values_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Values []string
+}
+
+func (b0 Metadata_Values_builder) Build() *Metadata_Values {
+	m0 := &Metadata_Values{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.xxx_hidden_Values = b.Values
+	return m0
+}
+
+var File_inspector_inspector_proto protoreflect.FileDescriptor
+
+const file_inspector_inspector_proto_rawDesc = "" +
+	"\n" +
+	"\x19inspector/inspector.proto\x12\x12grpcwasm.inspector\x1a\x1egoogle/protobuf/duration.proto\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\x0e\n" +
+	"\fWatchRequest\"\xbe\x06\n" +
+	"\x05Event\x12.\n" +
+	"\x04time\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x12\x17\n" +
+	"\aconn_id\x18\x02 \x01(\rR\x06connId\x12\x17\n" +
+	"\acall_id\x18\x03 \x01(\rR\x06callId\x12:\n" +
+	"\bsnapshot\x18\n" +
+	" \x01(\v2\x1c.grpcwasm.inspector.SnapshotH\x00R\bsnapshot\x12A\n" +
+	"\vconn_dialed\x18\v \x01(\v2\x1e.grpcwasm.inspector.ConnDialedH\x00R\n" +
+	"connDialed\x12A\n" +
+	"\vconn_closed\x18\f \x01(\v2\x1e.grpcwasm.inspector.ConnClosedH\x00R\n" +
+	"connClosed\x12D\n" +
+	"\fcall_started\x18\r \x01(\v2\x1f.grpcwasm.inspector.CallStartedH\x00R\vcallStarted\x12A\n" +
+	"\vheader_sent\x18\x0e \x01(\v2\x1e.grpcwasm.inspector.HeaderSentH\x00R\n" +
+	"headerSent\x12D\n" +
+	"\fmessage_sent\x18\x0f \x01(\v2\x1f.grpcwasm.inspector.MessageSentH\x00R\vmessageSent\x12M\n" +
+	"\x0fheader_received\x18\x10 \x01(\v2\".grpcwasm.inspector.HeaderReceivedH\x00R\x0eheaderReceived\x12P\n" +
+	"\x10message_received\x18\x11 \x01(\v2#.grpcwasm.inspector.MessageReceivedH\x00R\x0fmessageReceived\x12P\n" +
+	"\x10trailer_received\x18\x12 \x01(\v2#.grpcwasm.inspector.TrailerReceivedH\x00R\x0ftrailerReceived\x12G\n" +
+	"\rcall_finished\x18\x13 \x01(\v2 .grpcwasm.inspector.CallFinishedH\x00R\fcallFinishedB\x06\n" +
+	"\x04kind\"\xd2\x01\n" +
+	"\bMetadata\x12C\n" +
+	"\aentries\x18\x01 \x03(\v2).grpcwasm.inspector.Metadata.EntriesEntryR\aentries\x1a \n" +
+	"\x06Values\x12\x16\n" +
+	"\x06values\x18\x01 \x03(\tR\x06values\x1a_\n" +
+	"\fEntriesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x129\n" +
+	"\x05value\x18\x02 \x01(\v2#.grpcwasm.inspector.Metadata.ValuesR\x05value:\x028\x01\"\xe1\x02\n" +
+	"\x04Call\x12\x17\n" +
+	"\aconn_id\x18\x01 \x01(\rR\x06connId\x12\x17\n" +
+	"\acall_id\x18\x02 \x01(\rR\x06callId\x12\x16\n" +
+	"\x06method\x18\x03 \x01(\tR\x06method\x12#\n" +
+	"\rclient_stream\x18\x04 \x01(\bR\fclientStream\x12#\n" +
+	"\rserver_stream\x18\x05 \x01(\bR\fserverStream\x12=\n" +
+	"\fdate_started\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\vdateStarted\x124\n" +
+	"\x06header\x18\a \x01(\v2\x1c.grpcwasm.inspector.MetadataR\x06header\x12#\n" +
+	"\rmessages_sent\x18\b \x01(\rR\fmessagesSent\x12+\n" +
+	"\x11messages_received\x18\t \x01(\rR\x10messagesReceived\":\n" +
+	"\bSnapshot\x12.\n" +
+	"\x05calls\x18\x01 \x03(\v2\x18.grpcwasm.inspector.CallR\x05calls\"\f\n" +
+	"\n" +
+	"ConnDialed\"\f\n" +
+	"\n" +
+	"ConnClosed\"o\n" +
+	"\vCallStarted\x12\x16\n" +
+	"\x06method\x18\x01 \x01(\tR\x06method\x12#\n" +
+	"\rclient_stream\x18\x02 \x01(\bR\fclientStream\x12#\n" +
+	"\rserver_stream\x18\x03 \x01(\bR\fserverStream\"B\n" +
+	"\n" +
+	"HeaderSent\x124\n" +
+	"\x06header\x18\x01 \x01(\v2\x1c.grpcwasm.inspector.MetadataR\x06header\"S\n" +
+	"\vMessageSent\x12\x12\n" +
+	"\x04size\x18\x01 \x01(\rR\x04size\x120\n" +
+	"\adecoded\x18\x02 \x01(\v2\x16.google.protobuf.ValueR\adecoded\"F\n" +
+	"\x0eHeaderReceived\x124\n" +
+	"\x06header\x18\x01 \x01(\v2\x1c.grpcwasm.inspector.MetadataR\x06header\"W\n" +
+	"\x0fMessageReceived\x12\x12\n" +
+	"\x04size\x18\x01 \x01(\rR\x04size\x120\n" +
+	"\adecoded\x18\x02 \x01(\v2\x16.google.protobuf.ValueR\adecoded\"I\n" +
+	"\x0fTrailerReceived\x126\n" +
+	"\atrailer\x18\x01 \x01(\v2\x1c.grpcwasm.inspector.MetadataR\atrailer\"s\n" +
+	"\fCallFinished\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x125\n" +
+	"\bduration\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\bduration2\\\n" +
+	"\x10InspectorService\x12H\n" +
+	"\x05Watch\x12 .grpcwasm.inspector.WatchRequest\x1a\x19.grpcwasm.inspector.Event\"\x000\x01B)Z'github.com/lesomnus/grpc-wasm/inspectorb\x06proto3"
+
+var file_inspector_inspector_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
+var file_inspector_inspector_proto_goTypes = []any{
+	(*WatchRequest)(nil),          // 0: grpcwasm.inspector.WatchRequest
+	(*Event)(nil),                 // 1: grpcwasm.inspector.Event
+	(*Metadata)(nil),              // 2: grpcwasm.inspector.Metadata
+	(*Call)(nil),                  // 3: grpcwasm.inspector.Call
+	(*Snapshot)(nil),              // 4: grpcwasm.inspector.Snapshot
+	(*ConnDialed)(nil),            // 5: grpcwasm.inspector.ConnDialed
+	(*ConnClosed)(nil),            // 6: grpcwasm.inspector.ConnClosed
+	(*CallStarted)(nil),           // 7: grpcwasm.inspector.CallStarted
+	(*HeaderSent)(nil),            // 8: grpcwasm.inspector.HeaderSent
+	(*MessageSent)(nil),           // 9: grpcwasm.inspector.MessageSent
+	(*HeaderReceived)(nil),        // 10: grpcwasm.inspector.HeaderReceived
+	(*MessageReceived)(nil),       // 11: grpcwasm.inspector.MessageReceived
+	(*TrailerReceived)(nil),       // 12: grpcwasm.inspector.TrailerReceived
+	(*CallFinished)(nil),          // 13: grpcwasm.inspector.CallFinished
+	(*Metadata_Values)(nil),       // 14: grpcwasm.inspector.Metadata.Values
+	nil,                           // 15: grpcwasm.inspector.Metadata.EntriesEntry
+	(*timestamppb.Timestamp)(nil), // 16: google.protobuf.Timestamp
+	(*structpb.Value)(nil),        // 17: google.protobuf.Value
+	(*durationpb.Duration)(nil),   // 18: google.protobuf.Duration
+}
+var file_inspector_inspector_proto_depIdxs = []int32{
+	16, // 0: grpcwasm.inspector.Event.time:type_name -> google.protobuf.Timestamp
+	4,  // 1: grpcwasm.inspector.Event.snapshot:type_name -> grpcwasm.inspector.Snapshot
+	5,  // 2: grpcwasm.inspector.Event.conn_dialed:type_name -> grpcwasm.inspector.ConnDialed
+	6,  // 3: grpcwasm.inspector.Event.conn_closed:type_name -> grpcwasm.inspector.ConnClosed
+	7,  // 4: grpcwasm.inspector.Event.call_started:type_name -> grpcwasm.inspector.CallStarted
+	8,  // 5: grpcwasm.inspector.Event.header_sent:type_name -> grpcwasm.inspector.HeaderSent
+	9,  // 6: grpcwasm.inspector.Event.message_sent:type_name -> grpcwasm.inspector.MessageSent
+	10, // 7: grpcwasm.inspector.Event.header_received:type_name -> grpcwasm.inspector.HeaderReceived
+	11, // 8: grpcwasm.inspector.Event.message_received:type_name -> grpcwasm.inspector.MessageReceived
+	12, // 9: grpcwasm.inspector.Event.trailer_received:type_name -> grpcwasm.inspector.TrailerReceived
+	13, // 10: grpcwasm.inspector.Event.call_finished:type_name -> grpcwasm.inspector.CallFinished
+	15, // 11: grpcwasm.inspector.Metadata.entries:type_name -> grpcwasm.inspector.Metadata.EntriesEntry
+	16, // 12: grpcwasm.inspector.Call.date_started:type_name -> google.protobuf.Timestamp
+	2,  // 13: grpcwasm.inspector.Call.header:type_name -> grpcwasm.inspector.Metadata
+	3,  // 14: grpcwasm.inspector.Snapshot.calls:type_name -> grpcwasm.inspector.Call
+	2,  // 15: grpcwasm.inspector.HeaderSent.header:type_name -> grpcwasm.inspector.Metadata
+	17, // 16: grpcwasm.inspector.MessageSent.decoded:type_name -> google.protobuf.Value
+	2,  // 17: grpcwasm.inspector.HeaderReceived.header:type_name -> grpcwasm.inspector.Metadata
+	17, // 18: grpcwasm.inspector.MessageReceived.decoded:type_name -> google.protobuf.Value
+	2,  // 19: grpcwasm.inspector.TrailerReceived.trailer:type_name -> grpcwasm.inspector.Metadata
+	18, // 20: grpcwasm.inspector.CallFinished.duration:type_name -> google.protobuf.Duration
+	14, // 21: grpcwasm.inspector.Metadata.EntriesEntry.value:type_name -> grpcwasm.inspector.Metadata.Values
+	0,  // 22: grpcwasm.inspector.InspectorService.Watch:input_type -> grpcwasm.inspector.WatchRequest
+	1,  // 23: grpcwasm.inspector.InspectorService.Watch:output_type -> grpcwasm.inspector.Event
+	23, // [23:24] is the sub-list for method output_type
+	22, // [22:23] is the sub-list for method input_type
+	22, // [22:22] is the sub-list for extension type_name
+	22, // [22:22] is the sub-list for extension extendee
+	0,  // [0:22] is the sub-list for field type_name
+}
+
+func init() { file_inspector_inspector_proto_init() }
+func file_inspector_inspector_proto_init() {
+	if File_inspector_inspector_proto != nil {
+		return
+	}
+	file_inspector_inspector_proto_msgTypes[1].OneofWrappers = []any{
+		(*event_Snapshot)(nil),
+		(*event_ConnDialed)(nil),
+		(*event_ConnClosed)(nil),
+		(*event_CallStarted)(nil),
+		(*event_HeaderSent)(nil),
+		(*event_MessageSent)(nil),
+		(*event_HeaderReceived)(nil),
+		(*event_MessageReceived)(nil),
+		(*event_TrailerReceived)(nil),
+		(*event_CallFinished)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_inspector_inspector_proto_rawDesc), len(file_inspector_inspector_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   16,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_inspector_inspector_proto_goTypes,
+		DependencyIndexes: file_inspector_inspector_proto_depIdxs,
+		MessageInfos:      file_inspector_inspector_proto_msgTypes,
+	}.Build()
+	File_inspector_inspector_proto = out.File
+	file_inspector_inspector_proto_goTypes = nil
+	file_inspector_inspector_proto_depIdxs = nil
+}
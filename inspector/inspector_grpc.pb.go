@@ -0,0 +1,136 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.0
+// source: inspector/inspector.proto
+
+package inspector
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	InspectorService_Watch_FullMethodName = "/grpcwasm.inspector.InspectorService/Watch"
+)
+
+// InspectorServiceClient is the client API for InspectorService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// InspectorService reports the calls going through the bridge.
+// Events are described from the point of view of the client,
+// e.g. "sent" is a message sent by the client to the server.
+type InspectorServiceClient interface {
+	// Watch streams events of the bridge.
+	// The first event is always a snapshot of the calls currently open.
+	Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error)
+}
+
+type inspectorServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewInspectorServiceClient(cc grpc.ClientConnInterface) InspectorServiceClient {
+	return &inspectorServiceClient{cc}
+}
+
+func (c *inspectorServiceClient) Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &InspectorService_ServiceDesc.Streams[0], InspectorService_Watch_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchRequest, Event]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type InspectorService_WatchClient = grpc.ServerStreamingClient[Event]
+
+// InspectorServiceServer is the server API for InspectorService service.
+// All implementations must embed UnimplementedInspectorServiceServer
+// for forward compatibility.
+//
+// InspectorService reports the calls going through the bridge.
+// Events are described from the point of view of the client,
+// e.g. "sent" is a message sent by the client to the server.
+type InspectorServiceServer interface {
+	// Watch streams events of the bridge.
+	// The first event is always a snapshot of the calls currently open.
+	Watch(*WatchRequest, grpc.ServerStreamingServer[Event]) error
+	mustEmbedUnimplementedInspectorServiceServer()
+}
+
+// UnimplementedInspectorServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedInspectorServiceServer struct{}
+
+func (UnimplementedInspectorServiceServer) Watch(*WatchRequest, grpc.ServerStreamingServer[Event]) error {
+	return status.Errorf(codes.Unimplemented, "method Watch not implemented")
+}
+func (UnimplementedInspectorServiceServer) mustEmbedUnimplementedInspectorServiceServer() {}
+func (UnimplementedInspectorServiceServer) testEmbeddedByValue()                          {}
+
+// UnsafeInspectorServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to InspectorServiceServer will
+// result in compilation errors.
+type UnsafeInspectorServiceServer interface {
+	mustEmbedUnimplementedInspectorServiceServer()
+}
+
+func RegisterInspectorServiceServer(s grpc.ServiceRegistrar, srv InspectorServiceServer) {
+	// If the following call pancis, it indicates UnimplementedInspectorServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&InspectorService_ServiceDesc, srv)
+}
+
+func _InspectorService_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(InspectorServiceServer).Watch(m, &grpc.GenericServerStream[WatchRequest, Event]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type InspectorService_WatchServer = grpc.ServerStreamingServer[Event]
+
+// InspectorService_ServiceDesc is the grpc.ServiceDesc for InspectorService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var InspectorService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "grpcwasm.inspector.InspectorService",
+	HandlerType: (*InspectorServiceServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Watch",
+			Handler:       _InspectorService_Watch_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "inspector/inspector.proto",
+}
@@ -0,0 +1,218 @@
+package inspector_test
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/lesomnus/grpc-wasm/inspector"
+	"github.com/lesomnus/grpc-wasm/internal/echo"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type env struct {
+	insp   *inspector.Inspector
+	conn   *inspector.ConnHandler
+	echo   echo.EchoServiceClient
+	client inspector.InspectorServiceClient
+}
+
+func withInspector(f func(ctx context.Context, x *require.Assertions, env env)) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Helper()
+
+		x := require.New(t)
+
+		l := bufconn.Listen(1 << 20)
+		defer l.Close()
+
+		insp := inspector.New()
+		h := insp.Dial()
+		conn, err := grpc.NewClient("passthrough://bufnet",
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			grpc.WithContextDialer(func(ctx context.Context, s string) (net.Conn, error) {
+				return l.DialContext(ctx)
+			}),
+			grpc.WithStatsHandler(h),
+		)
+		x.NoError(err)
+		defer conn.Close()
+
+		s := grpc.NewServer()
+		echo.RegisterEchoServiceServer(s, echo.EchoServer{})
+		inspector.RegisterInspectorServiceServer(s, insp)
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.Serve(l)
+		}()
+
+		f(t.Context(), x, env{
+			insp:   insp,
+			conn:   h,
+			echo:   echo.NewEchoServiceClient(conn),
+			client: inspector.NewInspectorServiceClient(conn),
+		})
+
+		conn.Close()
+		s.Stop()
+		wg.Wait()
+	}
+}
+
+func TestInspector(t *testing.T) {
+	t.Run("snapshot first", withInspector(func(ctx context.Context, x *require.Assertions, env env) {
+		ctx_, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		stream, err := env.echo.Live(ctx_)
+		x.NoError(err)
+		err = stream.Send(echo.EchoRequest_builder{Message: "Lebowski"}.Build())
+		x.NoError(err)
+		_, err = stream.Recv()
+		x.NoError(err)
+
+		sub := env.insp.Subscribe()
+		defer sub.Close()
+
+		e, err := sub.Recv(ctx)
+		x.NoError(err)
+		x.True(e.HasSnapshot())
+
+		calls := e.GetSnapshot().GetCalls()
+		x.Len(calls, 1)
+		x.Equal(echo.EchoService_Live_FullMethodName, calls[0].GetMethod())
+		x.Equal(env.conn.ID(), calls[0].GetConnId())
+		x.True(calls[0].GetClientStream())
+		x.True(calls[0].GetServerStream())
+		x.Equal(uint32(1), calls[0].GetMessagesSent())
+		x.Equal(uint32(1), calls[0].GetMessagesReceived())
+	}))
+	t.Run("unary", withInspector(func(ctx context.Context, x *require.Assertions, env env) {
+		sub := env.insp.Subscribe()
+		defer sub.Close()
+
+		e, err := sub.Recv(ctx)
+		x.NoError(err)
+		x.True(e.HasSnapshot())
+		x.Empty(e.GetSnapshot().GetCalls())
+
+		ctx_ := metadata.AppendToOutgoingContext(ctx, "foo", "bar")
+		_, err = env.echo.Once(ctx_, echo.EchoRequest_builder{
+			Message: "Lebowski",
+			Status:  echo.Status_builder{Code: int32(codes.NotFound)}.Build(),
+		}.Build())
+		x.Error(err)
+
+		es := []*inspector.Event{}
+		for {
+			e, err := sub.Recv(ctx)
+			x.NoError(err)
+			es = append(es, e)
+			if e.HasCallFinished() {
+				break
+			}
+		}
+
+		x.True(es[0].HasCallStarted())
+		x.Equal(echo.EchoService_Once_FullMethodName, es[0].GetCallStarted().GetMethod())
+		for _, e := range es {
+			x.Equal(es[0].GetCallId(), e.GetCallId())
+			x.Equal(env.conn.ID(), e.GetConnId())
+		}
+
+		var sent *inspector.MessageSent
+		for _, e := range es {
+			if e.HasHeaderSent() {
+				x.Equal([]string{"bar"}, e.GetHeaderSent().GetHeader().GetEntries()["foo"].GetValues())
+			}
+			if e.HasMessageSent() {
+				sent = e.GetMessageSent()
+			}
+		}
+		x.NotNil(sent)
+		x.NotZero(sent.GetSize())
+		x.Equal("Lebowski", sent.GetDecoded().GetStructValue().GetFields()["message"].GetStringValue())
+
+		last := es[len(es)-1].GetCallFinished()
+		x.Equal(int32(codes.NotFound), last.GetCode())
+	}))
+	t.Run("conn closed", withInspector(func(ctx context.Context, x *require.Assertions, env env) {
+		sub := env.insp.Subscribe()
+		defer sub.Close()
+
+		_, err := sub.Recv(ctx)
+		x.NoError(err)
+
+		env.conn.Close()
+		e, err := sub.Recv(ctx)
+		x.NoError(err)
+		x.True(e.HasConnClosed())
+		x.Equal(env.conn.ID(), e.GetConnId())
+	}))
+	t.Run("snapshot after dropped events", withInspector(func(ctx context.Context, x *require.Assertions, env env) {
+		sub := env.insp.Subscribe()
+		defer sub.Close()
+
+		// Each call makes several events, so these overflow the buffer.
+		for range 1024 {
+			_, err := env.echo.Once(ctx, echo.EchoRequest_builder{Message: "Lebowski"}.Build())
+			x.NoError(err)
+		}
+		x.NotZero(sub.Dropped())
+
+		for range 1024 {
+			_, err := sub.Recv(ctx)
+			x.NoError(err)
+		}
+
+		_, err := env.echo.Once(ctx, echo.EchoRequest_builder{Message: "Lebowski"}.Build())
+		x.NoError(err)
+		e, err := sub.Recv(ctx)
+		x.NoError(err)
+		// The snapshot is in place of the start of the call.
+		x.True(e.HasSnapshot())
+		x.Len(e.GetSnapshot().GetCalls(), 1)
+		id := e.GetSnapshot().GetCalls()[0].GetCallId()
+
+		e, err = sub.Recv(ctx)
+		x.NoError(err)
+		x.True(e.HasHeaderSent())
+		x.Equal(id, e.GetCallId())
+	}))
+	t.Run("watch through gRPC", withInspector(func(ctx context.Context, x *require.Assertions, env env) {
+		ctx_, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		stream, err := env.client.Watch(ctx_, &inspector.WatchRequest{})
+		x.NoError(err)
+
+		e, err := stream.Recv()
+		x.NoError(err)
+		x.True(e.HasSnapshot())
+		// Call to the inspector itself is not inspected.
+		x.Empty(e.GetSnapshot().GetCalls())
+
+		_, err = env.echo.Once(ctx, echo.EchoRequest_builder{Message: "Lebowski"}.Build())
+		x.NoError(err)
+
+		e, err = stream.Recv()
+		x.NoError(err)
+		x.True(e.HasCallStarted())
+	}))
+	t.Run("closed subscription", func(t *testing.T) {
+		sub := inspector.New().Subscribe()
+		sub.Close()
+
+		_, err := sub.Recv(t.Context())
+		require.ErrorIs(t, err, inspector.ErrClosed)
+	})
+}
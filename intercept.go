@@ -0,0 +1,357 @@
+package grpcwasm
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/lesomnus/grpc-wasm/internal/js"
+	"github.com/lesomnus/grpc-wasm/internal/jz"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// Kinds of the events JS interceptors get.
+const (
+	// Start of a streaming call, without payload.
+	interceptOpen = "open"
+	// Message from the client.
+	interceptRequest = "request"
+	// Message to the client.
+	interceptResponse = "response"
+)
+
+type interceptEvent struct {
+	Method  string  `js:"method"`
+	Kind    string  `js:"kind"`
+	Meta    rpcMeta `js:"meta"`
+	Payload []byte  `js:"payload"`
+}
+
+// interceptResult is what a JS interceptor returns.
+// Nothing is replaced by undefined.
+type interceptResult struct {
+	Payload *[]byte    `js:"payload"`
+	Meta    *rpcMeta   `js:"meta"`
+	Status  *rpcStatus `js:"status"`
+}
+
+// jsInterceptors is the chain of the interceptors registered by JS, in the order they are registered.
+type jsInterceptors struct {
+	mu    sync.Mutex
+	chain []*js.Value
+}
+
+func (c *jsInterceptors) add(fn js.Value) (remove func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	p := &fn
+	c.chain = append(c.chain, p)
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		c.chain = slices.DeleteFunc(c.chain, func(v *js.Value) bool { return v == p })
+	}
+}
+
+func (c *jsInterceptors) list() []*js.Value {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return slices.Clone(c.chain)
+}
+
+// run calls the interceptors in order, each with the event replaced by the ones before.
+// It returns the error of the status an interceptor returned, which ends the chain.
+func (c *jsInterceptors) run(ctx context.Context, e *interceptEvent) error {
+	for _, fn := range c.list() {
+		arg, err := jz.Marshal(e)
+		if err != nil {
+			return status.Errorf(codes.Internal, "JS interceptor: %v", err)
+		}
+
+		v, reason, err := awaitCall(ctx, func() js.Value {
+			return fn.Invoke(arg)
+		})
+		if err != nil {
+			return status.FromContextError(err).Err()
+		}
+		if !reason.IsUndefined() {
+			return status.Errorf(codes.Internal, "JS interceptor: %v", jz.FromJs(reason))
+		}
+
+		var r interceptResult
+		if err := jz.Unmarshal(v, &r); err != nil {
+			return status.Errorf(codes.Internal, "JS interceptor: %v", err)
+		}
+		if r.Status != nil && r.Status.Code != codes.OK {
+			return r.Status.Status().Err()
+		}
+		if r.Meta != nil {
+			e.Meta = *r.Meta
+		}
+		if r.Payload != nil {
+			e.Payload = *r.Payload
+		}
+	}
+	return nil
+}
+
+// message runs the chain on the message and returns the payload replaced by the interceptors,
+// or nil if none replaced it.
+func (c *jsInterceptors) message(ctx context.Context, method string, kind string, msg proto.Message) ([]byte, error) {
+	if c == nil {
+		return nil, nil
+	}
+
+	payload, err := proto.Marshal(msg)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "marshal message for JS interceptors: %v", err)
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	e := interceptEvent{Method: method, Kind: kind, Meta: rpcMeta(md), Payload: payload}
+	if err := c.run(ctx, &e); err != nil {
+		return nil, err
+	}
+	if string(e.Payload) == string(payload) {
+		return nil, nil
+	}
+	return e.Payload, nil
+}
+
+// interceptorsFrom returns the JS interceptors of the listener the call came from,
+// or nil if there are none.
+func interceptorsFrom(ctx context.Context) *jsInterceptors {
+	l := listenerFrom(ctx)
+	if l == nil {
+		return nil
+	}
+	c := &l.interceptors
+	if len(c.list()) == 0 {
+		return nil
+	}
+	return c
+}
+
+// holdsFrom returns the holds of the listener the call came from,
+// or nil if the call did not come through [Listener].
+func holdsFrom(ctx context.Context) *holdRegistry {
+	l := listenerFrom(ctx)
+	if l == nil {
+		return nil
+	}
+	return &l.holds
+}
+
+// JsUnaryServerInterceptor calls the interceptors the JS side registered through the socket
+// with the request before the handler and with the response after it.
+// An interceptor can replace the incoming metadata with the request,
+// replace the message, or fail the call with a status.
+// Messages other than [proto.Message] are passed through.
+// The call is then held before the handler if a hold made by [Listener.JsHold] matches it.
+func JsUnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		holds := holdsFrom(ctx)
+		msg, ok := req.(proto.Message)
+		if holds == nil || !ok {
+			return handler(ctx, req)
+		}
+
+		c := interceptorsFrom(ctx)
+		if c != nil {
+			payload, err := proto.Marshal(msg)
+			if err != nil {
+				return nil, status.Errorf(codes.Internal, "marshal message for JS interceptors: %v", err)
+			}
+
+			md, _ := metadata.FromIncomingContext(ctx)
+			e := interceptEvent{Method: info.FullMethod, Kind: interceptRequest, Meta: rpcMeta(md), Payload: payload}
+			if err := c.run(ctx, &e); err != nil {
+				return nil, err
+			}
+			ctx = metadata.NewIncomingContext(ctx, metadata.MD(e.Meta))
+			if string(e.Payload) != string(payload) {
+				next := msg.ProtoReflect().New().Interface()
+				if err := proto.Unmarshal(e.Payload, next); err != nil {
+					return nil, status.Errorf(codes.Internal, "unmarshal request replaced by JS interceptor: %v", err)
+				}
+				req = next
+				msg = next
+			}
+		}
+		if err := holds.wait(ctx, info.FullMethod, interceptRequest, msg); err != nil {
+			return nil, err
+		}
+
+		res, err := handler(ctx, req)
+		if err != nil {
+			return res, err
+		}
+		out, ok := res.(proto.Message)
+		if !ok {
+			return res, nil
+		}
+
+		payload, err := c.message(ctx, info.FullMethod, interceptResponse, out)
+		if err != nil || payload == nil {
+			return res, err
+		}
+		next := out.ProtoReflect().New().Interface()
+		if err := proto.Unmarshal(payload, next); err != nil {
+			return nil, status.Errorf(codes.Internal, "unmarshal response replaced by JS interceptor: %v", err)
+		}
+		return next, nil
+	}
+}
+
+// JsStreamServerInterceptor calls the interceptors the JS side registered through the socket
+// when the stream opens, without payload, and then with every message received and sent.
+// See [JsUnaryServerInterceptor].
+// The metadata can be replaced only when the stream opens.
+// Holds made by [Listener.JsHold] hold the stream when it opens
+// and then every message after the interceptors.
+func JsStreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := ss.Context()
+		holds := holdsFrom(ctx)
+		if holds == nil {
+			return handler(srv, ss)
+		}
+
+		c := interceptorsFrom(ctx)
+		if c != nil {
+			md, _ := metadata.FromIncomingContext(ctx)
+			e := interceptEvent{Method: info.FullMethod, Kind: interceptOpen, Meta: rpcMeta(md), Payload: []byte{}}
+			if err := c.run(ctx, &e); err != nil {
+				return err
+			}
+			ctx = metadata.NewIncomingContext(ctx, metadata.MD(e.Meta))
+		}
+		if err := holds.wait(ctx, info.FullMethod, interceptOpen, nil); err != nil {
+			return err
+		}
+
+		return handler(srv, &interceptedStream{
+			ServerStream: ss,
+
+			ctx:    ctx,
+			method: info.FullMethod,
+			chain:  c,
+			holds:  holds,
+		})
+	}
+}
+
+type interceptedStream struct {
+	grpc.ServerStream
+
+	ctx    context.Context
+	method string
+	// Nil if there are no interceptors.
+	chain *jsInterceptors
+	holds *holdRegistry
+}
+
+func (s *interceptedStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *interceptedStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	msg, ok := m.(proto.Message)
+	if !ok {
+		return nil
+	}
+
+	payload, err := s.chain.message(s.ctx, s.method, interceptRequest, msg)
+	if err != nil {
+		return err
+	}
+	if payload != nil {
+		if err := proto.Unmarshal(payload, msg); err != nil {
+			return status.Errorf(codes.Internal, "unmarshal request replaced by JS interceptor: %v", err)
+		}
+	}
+	return s.holds.wait(s.ctx, s.method, interceptRequest, msg)
+}
+
+func (s *interceptedStream) SendMsg(m any) error {
+	msg, ok := m.(proto.Message)
+	if !ok {
+		return s.ServerStream.SendMsg(m)
+	}
+
+	payload, err := s.chain.message(s.ctx, s.method, interceptResponse, msg)
+	if err != nil {
+		return err
+	}
+	if payload != nil {
+		next := msg.ProtoReflect().New().Interface()
+		if err := proto.Unmarshal(payload, next); err != nil {
+			return status.Errorf(codes.Internal, "unmarshal response replaced by JS interceptor: %v", err)
+		}
+		m = next
+		msg = next
+	}
+	if err := s.holds.wait(s.ctx, s.method, interceptResponse, msg); err != nil {
+		return err
+	}
+	return s.ServerStream.SendMsg(m)
+}
+
+var interceptParams = jz.Params{
+	{Name: "interceptor", Kind: jz.KindFunction},
+}
+
+// JsIntercept adds the interceptor at the end of the chain called by
+// [JsUnaryServerInterceptor] and [JsStreamServerInterceptor].
+// An interceptor gets each event with what the ones before it replaced,
+// and returns nothing to pass it through, or what to replace.
+// A status other than OK fails the call.
+// Messages are in the protobuf binary format.
+//
+// Signature:
+//
+//	type InterceptEvent = {
+//		method: string
+//		kind: "open" | "request" | "response"
+//		meta: Metadata
+//		payload: Uint8Array
+//	}
+//	type InterceptResult = void | {
+//		payload?: Uint8Array
+//		meta?: Metadata
+//		status?: RpcStatus
+//	}
+//	type Interceptor = (e: InterceptEvent) => InterceptResult | Promise<InterceptResult>
+//	type Interception = {
+//		close: ()=>Promise<void>
+//	}
+//	function(interceptor: Interceptor): Promise<Interception>;
+func (l *Listener) JsIntercept(this js.Value, args []js.Value) any {
+	if err := interceptParams.Check(args); err != nil {
+		return jz.Reject(jz.ToError(err))
+	}
+
+	remove := l.interceptors.add(args[0])
+	var obj *jz.Object
+	release := sync.OnceFunc(func() {
+		remove()
+		obj.Release()
+	})
+	obj = l.scope.Object().
+		Method("close", func(this js.Value, args []js.Value) any {
+			release()
+			return jz.Resolve(js.Undefined())
+		})
+
+	return jz.Resolve(obj.Value())
+}
@@ -0,0 +1,201 @@
+package grpcwasm_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"sync"
+	"testing"
+
+	grpcwasm "github.com/lesomnus/grpc-wasm"
+	"github.com/lesomnus/grpc-wasm/internal/echo"
+	"github.com/lesomnus/grpc-wasm/internal/js"
+	"github.com/lesomnus/grpc-wasm/internal/jz"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestListener_JsIntercept(t *testing.T) {
+	x := require.New(t)
+
+	l := grpcwasm.NewListener()
+	defer l.Close()
+
+	conn, err := grpc.NewClient("passthrough://bufnet",
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(ctx context.Context, s string) (net.Conn, error) {
+			return l.DialContext(ctx)
+		}),
+	)
+	x.NoError(err)
+	defer conn.Close()
+
+	s := grpcwasm.NewServer(
+		grpc.ChainUnaryInterceptor(grpcwasm.JsUnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(grpcwasm.JsStreamServerInterceptor()),
+	)
+	echo.RegisterEchoServiceServer(s, echo.EchoServer{})
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		s.Serve(l)
+	}()
+	defer wg.Wait()
+	defer s.Stop()
+
+	client := echo.NewEchoServiceClient(conn)
+	sock := l.ToJsValue()
+
+	// intercept registers f as an interceptor until the test ends.
+	intercept := func(t *testing.T, f func(e js.Value) any) {
+		fn := js.FuncOf(func(this js.Value, args []js.Value) any {
+			return f(args[0])
+		})
+		interception, err_js := jz.Await(sock.Call("intercept", fn))
+		require.True(t, err_js.IsUndefined())
+		t.Cleanup(func() {
+			jz.Await(interception.Call("close"))
+			fn.Release()
+		})
+	}
+	payloadOf := func(m proto.Message) js.Value {
+		b, err := proto.Marshal(m)
+		x.NoError(err)
+		return jz.BytesToJs(b)
+	}
+
+	t.Run("pass through", func(t *testing.T) {
+		x := require.New(t)
+
+		events := []string{}
+		intercept(t, func(e js.Value) any {
+			events = append(events, e.Get("kind").String()+" "+e.Get("method").String())
+			return nil
+		})
+
+		res, err := client.Once(t.Context(), echo.EchoRequest_builder{Message: "Lebowski"}.Build())
+		x.NoError(err)
+		x.Equal("Lebowski", res.GetMessage())
+		x.Equal([]string{
+			"request " + echo.EchoService_Once_FullMethodName,
+			"response " + echo.EchoService_Once_FullMethodName,
+		}, events)
+	})
+	t.Run("replace", func(t *testing.T) {
+		x := require.New(t)
+
+		intercept(t, func(e js.Value) any {
+			switch e.Get("kind").String() {
+			case "request":
+				req := &echo.EchoRequest{}
+				x.NoError(proto.Unmarshal(jz.BytesToGo(e.Get("payload")), req))
+				x.Equal("Lebowski", req.GetMessage())
+
+				meta := js.Global().Get("Object").New()
+				meta.Set("role", js.ValueOf([]any{"admin"}))
+				return map[string]any{
+					"payload": payloadOf(echo.EchoRequest_builder{Message: "Dude"}.Build()),
+					"meta":    meta,
+				}
+			case "response":
+				return map[string]any{"payload": payloadOf(echo.EchoResponse_builder{Message: e.Get("meta").Get("role").Index(0).String()}.Build())}
+			}
+			return nil
+		})
+		// Next one sees what the previous replaced.
+		seen := ""
+		intercept(t, func(e js.Value) any {
+			res := &echo.EchoResponse{}
+			if e.Get("kind").String() == "response" {
+				x.NoError(proto.Unmarshal(jz.BytesToGo(e.Get("payload")), res))
+				seen = res.GetMessage()
+			}
+			return nil
+		})
+
+		header := metadata.MD{}
+		res, err := client.Once(metadata.AppendToOutgoingContext(t.Context(), "foo", "bar"), echo.EchoRequest_builder{Message: "Lebowski"}.Build(), grpc.Header(&header))
+		x.NoError(err)
+		x.Equal("admin", res.GetMessage())
+		x.Equal("admin", seen)
+		x.Equal([]string{"admin"}, header.Get("role"))
+		x.Empty(header.Get("foo"))
+	})
+	t.Run("deny", func(t *testing.T) {
+		x := require.New(t)
+
+		intercept(t, func(e js.Value) any {
+			return jz.Resolve(js.ValueOf(map[string]any{
+				"status": map[string]any{"code": int(codes.PermissionDenied), "message": "Mark it zero"},
+			}))
+		})
+
+		_, err := client.Once(t.Context(), &echo.EchoRequest{})
+		s, _ := status.FromError(err)
+		x.Equal(codes.PermissionDenied, s.Code())
+		x.Equal("Mark it zero", s.Message())
+
+		stream, err := client.Many(t.Context(), &echo.EchoRequest{})
+		x.NoError(err)
+		_, err = stream.Recv()
+		s, _ = status.FromError(err)
+		x.Equal(codes.PermissionDenied, s.Code())
+	})
+	t.Run("throw", func(t *testing.T) {
+		x := require.New(t)
+
+		intercept(t, func(e js.Value) any {
+			return jz.Reject(js.Global().Get("Error").New("Nihilists"))
+		})
+
+		_, err := client.Once(t.Context(), &echo.EchoRequest{})
+		s, _ := status.FromError(err)
+		x.Equal(codes.Internal, s.Code())
+		x.Contains(s.Message(), "Nihilists")
+	})
+	t.Run("stream", func(t *testing.T) {
+		x := require.New(t)
+
+		events := []string{}
+		intercept(t, func(e js.Value) any {
+			kind := e.Get("kind").String()
+			events = append(events, kind)
+			if kind != "response" {
+				return nil
+			}
+
+			res := &echo.EchoResponse{}
+			x.NoError(proto.Unmarshal(jz.BytesToGo(e.Get("payload")), res))
+			res.SetMessage(res.GetMessage() + "!")
+			return map[string]any{"payload": payloadOf(res)}
+		})
+
+		stream, err := client.Many(t.Context(), echo.EchoRequest_builder{Message: "Dude", Repeat: proto.Uint32(2)}.Build())
+		x.NoError(err)
+		for range 2 {
+			res, err := stream.Recv()
+			x.NoError(err)
+			x.Equal("Dude!", res.GetMessage())
+		}
+		_, err = stream.Recv()
+		x.ErrorIs(err, io.EOF)
+		x.Equal([]string{"open", "request", "response", "response"}, events)
+	})
+	t.Run("closed", func(t *testing.T) {
+		x := require.New(t)
+
+		res, err := client.Once(t.Context(), echo.EchoRequest_builder{Message: "Lebowski"}.Build())
+		x.NoError(err)
+		x.Equal("Lebowski", res.GetMessage())
+
+		_, err_js := jz.Await(sock.Call("intercept", "foo"))
+		x.Equal("argument", err_js.Get("kind").String())
+	})
+}
@@ -0,0 +1,102 @@
+// Package blob encodes named byte strings into one versioned blob.
+//
+// A blob is:
+//
+//	magic | version (uvarint) | count (uvarint) | { name length (uvarint) | name | data length (uvarint) | data }...
+package blob
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// Entry is a named byte string in a blob.
+type Entry struct {
+	Name string
+	Data []byte
+}
+
+// Format is a kind of blobs told apart by their magic.
+type Format struct {
+	Magic   string
+	Version uint64
+	// Names the blobs in errors, e.g. "snapshot".
+	Name string
+}
+
+// Encode returns the blob of given entries.
+func (f Format) Encode(entries []Entry) []byte {
+	b := bytes.NewBufferString(f.Magic)
+	b.Write(binary.AppendUvarint(nil, f.Version))
+	b.Write(binary.AppendUvarint(nil, uint64(len(entries))))
+	for _, e := range entries {
+		b.Write(binary.AppendUvarint(nil, uint64(len(e.Name))))
+		b.WriteString(e.Name)
+		b.Write(binary.AppendUvarint(nil, uint64(len(e.Data))))
+		b.Write(e.Data)
+	}
+	return b.Bytes()
+}
+
+// Decode returns the entries in given blob.
+// Their data refer to the blob.
+func (f Format) Decode(data []byte) ([]Entry, error) {
+	if !bytes.HasPrefix(data, []byte(f.Magic)) {
+		return nil, fmt.Errorf("not a %s", f.Name)
+	}
+	data = data[len(f.Magic):]
+
+	malformed := fmt.Errorf("malformed %s", f.Name)
+	next := func() (uint64, error) {
+		v, n := binary.Uvarint(data)
+		if n <= 0 {
+			return 0, malformed
+		}
+		data = data[n:]
+		return v, nil
+	}
+	take := func() ([]byte, error) {
+		l, err := next()
+		if err != nil {
+			return nil, err
+		}
+		if uint64(len(data)) < l {
+			return nil, malformed
+		}
+		v := data[:l]
+		data = data[l:]
+		return v, nil
+	}
+
+	version, err := next()
+	if err != nil {
+		return nil, err
+	}
+	if version != f.Version {
+		return nil, fmt.Errorf("unsupported %s version %d", f.Name, version)
+	}
+
+	count, err := next()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []Entry{}
+	for range count {
+		name, err := take()
+		if err != nil {
+			return nil, err
+		}
+		v, err := take()
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, Entry{Name: string(name), Data: v})
+	}
+	if len(data) > 0 {
+		return nil, malformed
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,45 @@
+package blob_test
+
+import (
+	"testing"
+
+	"github.com/lesomnus/grpc-wasm/internal/blob"
+	"github.com/stretchr/testify/require"
+)
+
+var format = blob.Format{Magic: "TEST", Version: 1, Name: "test blob"}
+
+func TestFormat(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		x := require.New(t)
+
+		entries := []blob.Entry{
+			{Name: "foo", Data: []byte("bar")},
+			{Name: "empty", Data: []byte{}},
+		}
+		actual, err := format.Decode(format.Encode(entries))
+		x.NoError(err)
+		x.Equal(entries, actual)
+
+		actual, err = format.Decode(format.Encode(nil))
+		x.NoError(err)
+		x.Empty(actual)
+	})
+	t.Run("malformed", func(t *testing.T) {
+		x := require.New(t)
+
+		data := format.Encode([]blob.Entry{{Name: "foo", Data: []byte("bar")}})
+
+		_, err := format.Decode([]byte("Lebowski"))
+		x.ErrorContains(err, "not a test blob")
+
+		_, err = format.Decode(data[:len(data)-1])
+		x.ErrorContains(err, "malformed test blob")
+
+		_, err = format.Decode(append(data, 0))
+		x.ErrorContains(err, "malformed test blob")
+
+		_, err = blob.Format{Magic: "TEST", Version: 2, Name: "test blob"}.Decode(data)
+		x.ErrorContains(err, "unsupported test blob version 1")
+	})
+}
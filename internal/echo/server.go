@@ -5,6 +5,7 @@ import (
 	"errors"
 	"io"
 
+	grpcwasm "github.com/lesomnus/grpc-wasm"
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
@@ -51,11 +52,11 @@ func (EchoServer) Once(ctx context.Context, req *EchoRequest) (*EchoResponse, er
 	return EchoResponse_builder{
 		Message:     v,
 		Sequence:    0,
-		DateCreated: timestamppb.Now(),
+		DateCreated: timestamppb.New(grpcwasm.ClockFrom(ctx).Now()),
 	}.Build(), nil
 }
 
-func (EchoServer) many(seq *uint32, req *EchoRequest, h func(res *EchoResponse) error) error {
+func (EchoServer) many(ctx context.Context, seq *uint32, req *EchoRequest, h func(res *EchoResponse) error) error {
 	if err := req.Error(); err != nil {
 		return err
 	}
@@ -70,7 +71,7 @@ func (EchoServer) many(seq *uint32, req *EchoRequest, h func(res *EchoResponse)
 		if err := h(EchoResponse_builder{
 			Message:     v,
 			Sequence:    *seq,
-			DateCreated: timestamppb.Now(),
+			DateCreated: timestamppb.New(grpcwasm.ClockFrom(ctx).Now()),
 		}.Build()); err != nil {
 			return err
 		}
@@ -87,7 +88,7 @@ func (s EchoServer) Many(req *EchoRequest, stream grpc.ServerStreamingServer[Ech
 	}
 
 	seq := uint32(0)
-	return s.many(&seq, req, stream.Send)
+	return s.many(stream.Context(), &seq, req, stream.Send)
 }
 
 func (s EchoServer) Buff(stream grpc.ClientStreamingServer[EchoRequest, EchoBatchResponse]) error {
@@ -102,7 +103,7 @@ func (s EchoServer) Buff(stream grpc.ClientStreamingServer[EchoRequest, EchoBatc
 			}
 			return err
 		}
-		if err := s.many(&seq, req, func(res *EchoResponse) error {
+		if err := s.many(stream.Context(), &seq, req, func(res *EchoResponse) error {
 			items = append(items, res)
 			return nil
 		}); err != nil {
@@ -136,7 +137,7 @@ func (s EchoServer) Live(stream grpc.BidiStreamingServer[EchoRequest, EchoRespon
 			}
 			return err
 		}
-		if err := s.many(&seq, req, stream.Send); err != nil {
+		if err := s.many(ctx, &seq, req, stream.Send); err != nil {
 			return err
 		}
 	}
@@ -7,15 +7,21 @@ import (
 	"os"
 
 	grpcwasm "github.com/lesomnus/grpc-wasm"
+	"github.com/lesomnus/grpc-wasm/inspector"
 	"github.com/lesomnus/grpc-wasm/internal/echo"
-	"google.golang.org/grpc"
 )
 
 func main() {
-	s := grpc.NewServer()
+	insp := inspector.New()
+
+	s := grpcwasm.NewServer()
 	echo.RegisterEchoServiceServer(s, echo.EchoServer{})
+	inspector.RegisterInspectorServiceServer(s, insp)
 
-	if err := grpcwasm.Serve(s); err != nil {
+	if err := grpcwasm.Serve(s,
+		grpcwasm.WithMetrics(grpcwasm.NewMetrics()),
+		grpcwasm.WithInspector(insp),
+	); err != nil {
 		fmt.Fprintf(os.Stderr, "server stopped with error: %v\n", err)
 	}
 }
@@ -0,0 +1,28 @@
+//go:build wasip1
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	grpcwasm "github.com/lesomnus/grpc-wasm"
+	"github.com/lesomnus/grpc-wasm/internal/echo"
+)
+
+func main() {
+	s := grpcwasm.NewServer()
+	echo.RegisterEchoServiceServer(s, echo.EchoServer{})
+
+	if err := grpcwasm.Serve(s); err != nil {
+		fmt.Fprintf(os.Stderr, "server stopped with error: %v\n", err)
+	}
+
+	// Lets the host test the module that does not exit.
+	if os.Getenv("ECHOBRIDGE_LINGER") != "" {
+		for {
+			time.Sleep(time.Hour)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+//go:build !(js && wasm)
+
+package js
+
+func newSignal() *object {
+	o := newObject(std.signal_proto)
+	o.kind = kindSignal
+	o.setOwn("aborted", Value{v: false})
+	o.setOwn("reason", Undefined())
+	return o
+}
+
+func thisSignal(this Value) *object {
+	o := this.object()
+	if o == nil || o.kind != kindSignal {
+		panic(throw("TypeError", "Illegal invocation"))
+	}
+	return o
+}
+
+// abort aborts the signal and calls its listeners.
+func (o *object) abort(reason Value) {
+	if reason.IsUndefined() {
+		e := throw("AbortError", "This operation was aborted")
+		reason = e.Value
+	}
+
+	lock.Lock()
+	if o.props["aborted"].Bool() {
+		lock.Unlock()
+		return
+	}
+	o.props["aborted"] = Value{v: true}
+	o.props["reason"] = reason
+	listeners := o.listeners
+	o.listeners = nil
+	lock.Unlock()
+
+	signal := Value{v: o}
+	event := ValueOf(map[string]any{"type": "abort", "target": signal})
+	for _, l := range listeners {
+		l.object().invoke(signal, []Value{event})
+	}
+	if f := o.get("onabort"); f.Type() == TypeFunction {
+		f.object().invoke(signal, []Value{event})
+	}
+}
+
+func defineAbort() {
+	signal_proto := std.signal_proto
+	signal_ctor := defineClass("AbortSignal", signal_proto, func(args []Value) Value {
+		panic(throw("TypeError", "Illegal constructor"))
+	})
+
+	method(signal_ctor, "abort", func(this Value, args []Value) Value {
+		o := newSignal()
+		o.abort(arg(args, 0))
+		return Value{v: o}
+	})
+	method(signal_proto, "addEventListener", func(this Value, args []Value) Value {
+		o := thisSignal(this)
+		f := arg(args, 1)
+		if arg(args, 0).Equal(ValueOf("abort")) && f.Type() == TypeFunction {
+			lock.Lock()
+			o.listeners = append(o.listeners, f)
+			lock.Unlock()
+		}
+		return Undefined()
+	})
+	method(signal_proto, "removeEventListener", func(this Value, args []Value) Value {
+		o := thisSignal(this)
+		f := arg(args, 1)
+
+		lock.Lock()
+		defer lock.Unlock()
+
+		for i, l := range o.listeners {
+			if l.Equal(f) {
+				o.listeners = append(o.listeners[:i:i], o.listeners[i+1:]...)
+				break
+			}
+		}
+		return Undefined()
+	})
+	method(signal_proto, "throwIfAborted", func(this Value, args []Value) Value {
+		o := thisSignal(this)
+		if o.get("aborted").Bool() {
+			panic(Error{Value: o.get("reason")})
+		}
+		return Undefined()
+	})
+
+	controller_proto := newObject(std.object_proto)
+	defineClass("AbortController", controller_proto, func(args []Value) Value {
+		o := newObject(controller_proto)
+		o.setOwn("signal", Value{v: newSignal()})
+		return Value{v: o}
+	})
+	method(controller_proto, "abort", func(this Value, args []Value) Value {
+		o := this.mustObject("AbortController.prototype.abort")
+		o.get("signal").object().abort(arg(args, 0))
+		return Undefined()
+	})
+}
@@ -0,0 +1,402 @@
+//go:build !(js && wasm)
+
+package js
+
+import (
+	"math"
+	"time"
+)
+
+// std holds the builtins.
+var std struct {
+	global *object
+
+	object_proto   *object
+	function_proto *object
+	array_proto    *object
+	bytes_proto    *object
+	date_proto     *object
+	error_proto    *object
+	type_error     *object
+	promise_proto  *object
+	signal_proto   *object
+}
+
+func init() {
+	std.object_proto = &object{props: map[string]Value{}}
+	std.function_proto = newObject(std.object_proto)
+	std.array_proto = newObject(std.object_proto)
+	std.bytes_proto = newObject(std.object_proto)
+	std.date_proto = newObject(std.object_proto)
+	std.error_proto = newObject(std.object_proto)
+	std.type_error = newObject(std.error_proto)
+	std.promise_proto = newObject(std.object_proto)
+	std.signal_proto = newObject(std.object_proto)
+
+	std.global = newObject(std.object_proto)
+	std.global.setOwn("globalThis", Value{v: std.global})
+
+	defineObject()
+	defineFunction()
+	defineArray()
+	defineBytes()
+	defineDate()
+	defineError()
+	definePromise()
+	defineJSON()
+	defineAbort()
+}
+
+// defineClass defines a global constructor with given prototype.
+// Calling the constructor as a function constructs too.
+func defineClass(name string, proto *object, ctor func(args []Value) Value) *object {
+	fn := newFunction(name, func(this Value, args []Value) Value {
+		return ctor(args)
+	})
+	fn.ctor = ctor
+	fn.setOwn("prototype", Value{v: proto})
+	proto.setOwn("constructor", Value{v: fn})
+	std.global.setOwn(name, Value{v: fn})
+	return fn
+}
+
+func method(o *object, name string, f func(this Value, args []Value) Value) {
+	o.setOwn(name, Value{v: newFunction(name, f)})
+}
+
+func defineObject() {
+	proto := std.object_proto
+	ctor := defineClass("Object", proto, func(args []Value) Value {
+		if v := arg(args, 0); v.object() != nil {
+			return v
+		}
+		return Value{v: newObject(std.object_proto)}
+	})
+
+	method(proto, "hasOwnProperty", func(this Value, args []Value) Value {
+		o := this.mustObject("Object.prototype.hasOwnProperty")
+		k := toString(arg(args, 0))
+		for _, p := range o.ownKeys() {
+			if p == k {
+				return Value{v: true}
+			}
+		}
+		return Value{v: false}
+	})
+	method(proto, "toString", func(this Value, args []Value) Value {
+		return Value{v: "[object Object]"}
+	})
+
+	method(ctor, "keys", func(this Value, args []Value) Value {
+		o := arg(args, 0).mustObject("Object.keys")
+		ks := o.ownKeys()
+		vs := make([]Value, len(ks))
+		for i, k := range ks {
+			vs[i] = Value{v: k}
+		}
+		return Value{v: newArray(vs)}
+	})
+	method(ctor, "entries", func(this Value, args []Value) Value {
+		o := arg(args, 0).mustObject("Object.entries")
+		ks := o.ownKeys()
+		vs := make([]Value, len(ks))
+		for i, k := range ks {
+			vs[i] = Value{v: newArray([]Value{{v: k}, o.get(k)})}
+		}
+		return Value{v: newArray(vs)}
+	})
+	method(ctor, "assign", func(this Value, args []Value) Value {
+		target := arg(args, 0).mustObject("Object.assign")
+		for _, src := range args[1:] {
+			if o := src.object(); o != nil {
+				for _, k := range o.ownKeys() {
+					target.set(k, o.get(k))
+				}
+			}
+		}
+		return args[0]
+	})
+	method(ctor, "create", func(this Value, args []Value) Value {
+		return Value{v: newObject(arg(args, 0).object())}
+	})
+	method(ctor, "getPrototypeOf", func(this Value, args []Value) Value {
+		o := arg(args, 0).mustObject("Object.getPrototypeOf")
+
+		lock.Lock()
+		defer lock.Unlock()
+
+		if o.proto == nil {
+			return Null()
+		}
+		return Value{v: o.proto}
+	})
+	method(ctor, "setPrototypeOf", func(this Value, args []Value) Value {
+		o := arg(args, 0).mustObject("Object.setPrototypeOf")
+
+		lock.Lock()
+		defer lock.Unlock()
+
+		o.proto = arg(args, 1).object()
+		return args[0]
+	})
+}
+
+func defineFunction() {
+	proto := std.function_proto
+	method(proto, "call", func(this Value, args []Value) Value {
+		if this.Type() != TypeFunction {
+			panic(throw("TypeError", "Function.prototype.call called on non-function"))
+		}
+		return this.object().invoke(arg(args, 0), args[min(1, len(args)):])
+	})
+	method(proto, "apply", func(this Value, args []Value) Value {
+		if this.Type() != TypeFunction {
+			panic(throw("TypeError", "Function.prototype.apply called on non-function"))
+		}
+
+		vs := []Value{}
+		if a := arg(args, 1); a.object() != nil {
+			for i := range a.Length() {
+				vs = append(vs, a.Index(i))
+			}
+		}
+		return this.object().invoke(arg(args, 0), vs)
+	})
+}
+
+func thisArray(this Value) *object {
+	o := this.object()
+	if o == nil || o.kind != kindArray {
+		panic(throw("TypeError", "receiver is not an Array"))
+	}
+	return o
+}
+
+func defineArray() {
+	proto := std.array_proto
+	ctor := defineClass("Array", proto, func(args []Value) Value {
+		if len(args) == 1 && args[0].Type() == TypeNumber {
+			return Value{v: newArray(make([]Value, args[0].Int()))}
+		}
+		return Value{v: newArray(append([]Value{}, args...))}
+	})
+
+	method(ctor, "isArray", func(this Value, args []Value) Value {
+		o := arg(args, 0).object()
+		return Value{v: o != nil && o.kind == kindArray}
+	})
+
+	method(proto, "push", func(this Value, args []Value) Value {
+		o := thisArray(this)
+
+		lock.Lock()
+		defer lock.Unlock()
+
+		o.elems = append(o.elems, args...)
+		return Value{v: float64(len(o.elems))}
+	})
+	method(proto, "pop", func(this Value, args []Value) Value {
+		o := thisArray(this)
+
+		lock.Lock()
+		defer lock.Unlock()
+
+		if len(o.elems) == 0 {
+			return Undefined()
+		}
+		v := o.elems[len(o.elems)-1]
+		o.elems = o.elems[:len(o.elems)-1]
+		return v
+	})
+	method(proto, "indexOf", func(this Value, args []Value) Value {
+		o := thisArray(this)
+
+		lock.Lock()
+		defer lock.Unlock()
+
+		for i, v := range o.elems {
+			if v.Equal(arg(args, 0)) {
+				return Value{v: float64(i)}
+			}
+		}
+		return Value{v: float64(-1)}
+	})
+	method(proto, "slice", func(this Value, args []Value) Value {
+		o := thisArray(this)
+
+		lock.Lock()
+		defer lock.Unlock()
+
+		start, end := sliceRange(len(o.elems), args)
+		return Value{v: newArray(append([]Value{}, o.elems[start:end]...))}
+	})
+}
+
+// sliceRange returns the range of slice(start, end) of given length.
+func sliceRange(n int, args []Value) (int, int) {
+	clamp := func(v Value, def int) int {
+		if v.IsUndefined() {
+			return def
+		}
+		i := int(toNumber(v))
+		if i < 0 {
+			i += n
+		}
+		return max(0, min(i, n))
+	}
+
+	start := clamp(arg(args, 0), 0)
+	end := clamp(arg(args, 1), n)
+	return start, max(start, end)
+}
+
+func thisBytes(this Value) *object {
+	o := this.object()
+	if o == nil || o.kind != kindBytes {
+		panic(throw("TypeError", "receiver is not a Uint8Array"))
+	}
+	return o
+}
+
+func defineBytes() {
+	proto := std.bytes_proto
+	defineClass("Uint8Array", proto, func(args []Value) Value {
+		v := arg(args, 0)
+		switch v.Type() {
+		case TypeUndefined:
+			return Value{v: newBytes([]byte{})}
+		case TypeNumber:
+			return Value{v: newBytes(make([]byte, v.Int()))}
+		case TypeObject:
+			src := v.object()
+			n := src.get("length").Int()
+			b := make([]byte, n)
+			for i := range n {
+				b[i] = byte(int64(toNumber(v.Index(i))))
+			}
+			return Value{v: newBytes(b)}
+		}
+		panic(throw("TypeError", "invalid argument for Uint8Array"))
+	})
+
+	method(proto, "slice", func(this Value, args []Value) Value {
+		o := thisBytes(this)
+
+		lock.Lock()
+		defer lock.Unlock()
+
+		start, end := sliceRange(len(o.bytes), args)
+		return Value{v: newBytes(append([]byte{}, o.bytes[start:end]...))}
+	})
+	method(proto, "subarray", func(this Value, args []Value) Value {
+		o := thisBytes(this)
+
+		lock.Lock()
+		defer lock.Unlock()
+
+		start, end := sliceRange(len(o.bytes), args)
+		return Value{v: newBytes(o.bytes[start:end:end])}
+	})
+}
+
+func thisDate(this Value) float64 {
+	o := this.object()
+	if o == nil || o.kind != kindDate {
+		panic(throw("TypeError", "this is not a Date object."))
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	return o.time
+}
+
+func newDate(ms float64) *object {
+	o := newObject(std.date_proto)
+	o.kind = kindDate
+	o.time = ms
+	return o
+}
+
+func defineDate() {
+	proto := std.date_proto
+	ctor := defineClass("Date", proto, func(args []Value) Value {
+		if len(args) == 0 {
+			return Value{v: newDate(float64(time.Now().UnixMilli()))}
+		}
+
+		v := args[0]
+		switch v.Type() {
+		case TypeString:
+			t, err := time.Parse(time.RFC3339Nano, v.String())
+			if err != nil {
+				return Value{v: newDate(math.NaN())}
+			}
+			return Value{v: newDate(float64(t.UnixMilli()))}
+		case TypeObject:
+			if o := v.object(); o.kind == kindDate {
+				return Value{v: newDate(thisDate(v))}
+			}
+		}
+		return Value{v: newDate(float64(int64(toNumber(v))))}
+	})
+
+	method(ctor, "now", func(this Value, args []Value) Value {
+		return Value{v: float64(time.Now().UnixMilli())}
+	})
+
+	get_time := func(this Value, args []Value) Value {
+		return Value{v: thisDate(this)}
+	}
+	method(proto, "getTime", get_time)
+	method(proto, "valueOf", get_time)
+
+	to_iso := func(this Value, args []Value) Value {
+		ms := thisDate(this)
+		return Value{v: time.UnixMilli(int64(ms)).UTC().Format("2006-01-02T15:04:05.000Z")}
+	}
+	method(proto, "toISOString", to_iso)
+	method(proto, "toJSON", to_iso)
+	method(proto, "toString", to_iso)
+}
+
+func defineError() {
+	proto := std.error_proto
+	proto.setOwn("name", Value{v: "Error"})
+	proto.setOwn("message", Value{v: ""})
+
+	newError := func(proto *object) func(args []Value) Value {
+		return func(args []Value) Value {
+			o := newObject(proto)
+			if msg := arg(args, 0); !msg.IsUndefined() {
+				o.setOwn("message", Value{v: toString(msg)})
+			}
+			if opts := arg(args, 1).object(); opts != nil {
+				lock.Lock()
+				cause, ok := opts.props["cause"]
+				lock.Unlock()
+				if ok {
+					o.setOwn("cause", cause)
+				}
+			}
+			return Value{v: o}
+		}
+	}
+	defineClass("Error", proto, newError(proto))
+
+	std.type_error.setOwn("name", Value{v: "TypeError"})
+	defineClass("TypeError", std.type_error, newError(std.type_error))
+
+	method(proto, "toString", func(this Value, args []Value) Value {
+		o := this.mustObject("Error.prototype.toString")
+		name := toString(o.get("name"))
+		msg := toString(o.get("message"))
+		switch {
+		case msg == "":
+			return Value{v: name}
+		case name == "":
+			return Value{v: msg}
+		}
+		return Value{v: name + ": " + msg}
+	})
+}
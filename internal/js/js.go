@@ -0,0 +1,7 @@
+// Package js is the API of JS values the bridge is written against.
+// It mirrors the subset of syscall/js the bridge uses.
+//
+// On js/wasm it is syscall/js itself.
+// Elsewhere, i.e. on wasip1 and natively, it is an in-memory JS runtime implemented in Go
+// with the builtins the bridge touches and an event loop running promise reactions.
+package js
@@ -0,0 +1,194 @@
+package js_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lesomnus/grpc-wasm/internal/js"
+	"github.com/stretchr/testify/require"
+)
+
+// await waits for the promise to settle.
+func await(t *testing.T, p js.Value) (js.Value, bool) {
+	type result struct {
+		v  js.Value
+		ok bool
+	}
+
+	c := make(chan result, 1)
+	on_resolve := js.FuncOf(func(this js.Value, args []js.Value) any {
+		c <- result{v: args[0], ok: true}
+		return nil
+	})
+	on_reject := js.FuncOf(func(this js.Value, args []js.Value) any {
+		c <- result{v: args[0]}
+		return nil
+	})
+	defer on_resolve.Release()
+	defer on_reject.Release()
+
+	p.Call("then", on_resolve, on_reject)
+	select {
+	case r := <-c:
+		return r.v, r.ok
+	case <-time.After(time.Second):
+		t.Fatal("promise is not settled")
+		return js.Undefined(), false
+	}
+}
+
+func TestValueOf(t *testing.T) {
+	x := require.New(t)
+
+	x.Equal(js.TypeUndefined, js.Undefined().Type())
+	x.Equal(js.TypeNull, js.ValueOf(nil).Type())
+	x.Equal(js.TypeBoolean, js.ValueOf(true).Type())
+	x.Equal(js.TypeNumber, js.ValueOf(42).Type())
+	x.Equal(js.TypeString, js.ValueOf("foo").Type())
+	x.Equal(js.TypeObject, js.ValueOf(map[string]any{}).Type())
+	x.Equal(js.TypeObject, js.ValueOf([]any{}).Type())
+
+	v := js.ValueOf(map[string]any{
+		"foo": 42,
+		"bar": []any{"a", true},
+	})
+	x.Equal(42, v.Get("foo").Int())
+	x.Equal(2, v.Get("bar").Length())
+	x.Equal("a", v.Get("bar").Index(0).String())
+	x.True(v.Get("bar").Index(1).Bool())
+	x.True(v.Get("baz").IsUndefined())
+
+	x.Equal("<number: 42>", js.ValueOf(42).String())
+	x.Equal("<undefined>", js.Undefined().String())
+	x.PanicsWithError("syscall/js: call of Value.Get on undefined", func() {
+		js.Undefined().Get("foo")
+	})
+}
+
+func TestObject(t *testing.T) {
+	x := require.New(t)
+
+	o := js.Global().Get("Object").New()
+	o.Set("b", 1)
+	o.Set("a", 2)
+	o.Set("c", 3)
+	o.Delete("c")
+
+	keys := js.Global().Get("Object").Call("keys", o)
+	x.Equal(2, keys.Length())
+	x.Equal("b", keys.Index(0).String())
+	x.Equal("a", keys.Index(1).String())
+
+	a := js.Global().Get("Array").New()
+	a.Call("push", 1, "two")
+	x.Equal(2, a.Length())
+	x.True(js.Global().Get("Array").Call("isArray", a).Bool())
+	x.False(js.Global().Get("Array").Call("isArray", o).Bool())
+
+	b := js.Global().Get("Uint8Array").New(3)
+	x.Equal(3, js.CopyBytesToJS(b, []byte{1, 2, 3}))
+	x.Equal(2, b.Index(1).Int())
+	out := make([]byte, 3)
+	x.Equal(3, js.CopyBytesToGo(out, b))
+	x.Equal([]byte{1, 2, 3}, out)
+
+	d := js.Global().Get("Date").New(1234)
+	x.Equal(1234, d.Call("getTime").Int())
+	x.True(d.InstanceOf(js.Global().Get("Date")))
+	x.False(o.InstanceOf(js.Global().Get("Date")))
+
+	e := js.Global().Get("Error").New("foo")
+	x.True(e.InstanceOf(js.Global().Get("Error")))
+	x.Equal("foo", e.Get("message").String())
+	x.Equal("Error", e.Get("name").String())
+}
+
+func TestFuncOf(t *testing.T) {
+	x := require.New(t)
+
+	f := js.FuncOf(func(this js.Value, args []js.Value) any {
+		return args[0].Int() + args[1].Int()
+	})
+	x.Equal(3, f.Invoke(1, 2).Int())
+
+	o := js.Global().Get("Object").New()
+	o.Set("f", js.FuncOf(func(this js.Value, args []js.Value) any {
+		return this
+	}))
+	x.True(o.Call("f").Equal(o))
+}
+
+func TestPromise(t *testing.T) {
+	t.Run("resolve", func(t *testing.T) {
+		v, ok := await(t, js.Global().Get("Promise").Call("resolve", 42))
+		require.True(t, ok)
+		require.Equal(t, 42, v.Int())
+	})
+	t.Run("reject", func(t *testing.T) {
+		v, ok := await(t, js.Global().Get("Promise").Call("reject", 42))
+		require.False(t, ok)
+		require.Equal(t, 42, v.Int())
+	})
+	t.Run("executor", func(t *testing.T) {
+		x := require.New(t)
+
+		var resolve js.Value
+		executor := js.FuncOf(func(this js.Value, args []js.Value) any {
+			resolve = args[0]
+			return nil
+		})
+		defer executor.Release()
+
+		p := js.Global().Get("Promise").New(executor)
+		go resolve.Invoke(js.Global().Get("Promise").Call("resolve", "foo"))
+
+		// Resolving with a promise adopts its state.
+		v, ok := await(t, p)
+		x.True(ok)
+		x.Equal("foo", v.String())
+	})
+	t.Run("chain", func(t *testing.T) {
+		x := require.New(t)
+
+		double := js.FuncOf(func(this js.Value, args []js.Value) any {
+			return args[0].Int() * 2
+		})
+		defer double.Release()
+
+		p := js.Global().Get("Promise").Call("resolve", 21).Call("then", double)
+		v, ok := await(t, p)
+		x.True(ok)
+		x.Equal(42, v.Int())
+	})
+}
+
+func TestJSON(t *testing.T) {
+	x := require.New(t)
+
+	v := js.Global().Get("JSON").Call("parse", `{"b":[1,"two",null],"a":{"c":true}}`)
+	x.Equal(3, v.Get("b").Length())
+	x.True(v.Get("a").Get("c").Bool())
+
+	s := js.Global().Get("JSON").Call("stringify", v)
+	x.Equal(`{"b":[1,"two",null],"a":{"c":true}}`, s.String())
+}
+
+func TestAbortController(t *testing.T) {
+	x := require.New(t)
+
+	ac := js.Global().Get("AbortController").New()
+	signal := ac.Get("signal")
+	x.False(signal.Get("aborted").Bool())
+
+	aborted := make(chan js.Value, 1)
+	on_abort := js.FuncOf(func(this js.Value, args []js.Value) any {
+		aborted <- this.Get("reason")
+		return nil
+	})
+	defer on_abort.Release()
+	signal.Call("addEventListener", "abort", on_abort)
+
+	ac.Call("abort", "foo")
+	x.True(signal.Get("aborted").Bool())
+	x.Equal("foo", (<-aborted).String())
+}
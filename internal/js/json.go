@@ -0,0 +1,174 @@
+//go:build !(js && wasm)
+
+package js
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"strings"
+)
+
+func defineJSON() {
+	o := newObject(std.object_proto)
+	std.global.setOwn("JSON", Value{v: o})
+
+	method(o, "stringify", func(this Value, args []Value) Value {
+		b := &strings.Builder{}
+		if !stringify(b, arg(args, 0)) {
+			return Undefined()
+		}
+		return Value{v: b.String()}
+	})
+	method(o, "parse", func(this Value, args []Value) Value {
+		d := json.NewDecoder(strings.NewReader(toString(arg(args, 0))))
+		d.UseNumber()
+
+		v, err := parse(d)
+		if err == nil {
+			if _, err = d.Token(); errors.Is(err, io.EOF) {
+				err = nil
+			} else if err == nil {
+				err = errors.New("unexpected non-whitespace character after JSON")
+			}
+		}
+		if err != nil {
+			panic(throw("SyntaxError", err.Error()))
+		}
+		return v
+	})
+}
+
+func quote(s string) string {
+	b := &bytes.Buffer{}
+	e := json.NewEncoder(b)
+	e.SetEscapeHTML(false)
+	e.Encode(s)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// stringify writes the value as JSON.stringify does.
+// It returns false if the value is not serializable, i.e. undefined or a function.
+func stringify(b *strings.Builder, v Value) bool {
+	switch x := v.v.(type) {
+	case nil:
+		return false
+	case null:
+		b.WriteString("null")
+	case bool:
+		if x {
+			b.WriteString("true")
+		} else {
+			b.WriteString("false")
+		}
+	case float64:
+		if math.IsNaN(x) || math.IsInf(x, 0) {
+			b.WriteString("null")
+		} else {
+			b.WriteString(formatNumber(x))
+		}
+	case string:
+		b.WriteString(quote(x))
+	case *object:
+		if x.call != nil {
+			return false
+		}
+		if f := x.get("toJSON"); f.Type() == TypeFunction {
+			return stringify(b, f.object().invoke(v, nil))
+		}
+
+		if x.kind == kindArray {
+			b.WriteByte('[')
+			for i := range v.Length() {
+				if i > 0 {
+					b.WriteByte(',')
+				}
+				if !stringify(b, v.Index(i)) {
+					b.WriteString("null")
+				}
+			}
+			b.WriteByte(']')
+			return true
+		}
+
+		b.WriteByte('{')
+		first := true
+		for _, k := range x.ownKeys() {
+			item := &strings.Builder{}
+			if !stringify(item, x.get(k)) {
+				continue
+			}
+			if !first {
+				b.WriteByte(',')
+			}
+			first = false
+			b.WriteString(quote(k))
+			b.WriteByte(':')
+			b.WriteString(item.String())
+		}
+		b.WriteByte('}')
+	}
+	return true
+}
+
+// parse parses a JSON value keeping the order of the keys.
+func parse(d *json.Decoder) (Value, error) {
+	t, err := d.Token()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			err = errors.New("unexpected end of JSON input")
+		}
+		return Undefined(), err
+	}
+
+	switch t := t.(type) {
+	case nil:
+		return Null(), nil
+	case bool:
+		return Value{v: t}, nil
+	case json.Number:
+		f, err := t.Float64()
+		if err != nil {
+			return Undefined(), err
+		}
+		return Value{v: f}, nil
+	case string:
+		return Value{v: t}, nil
+	case json.Delim:
+		switch t {
+		case '[':
+			elems := []Value{}
+			for d.More() {
+				v, err := parse(d)
+				if err != nil {
+					return Undefined(), err
+				}
+				elems = append(elems, v)
+			}
+			if _, err := d.Token(); err != nil {
+				return Undefined(), err
+			}
+			return Value{v: newArray(elems)}, nil
+		case '{':
+			o := newObject(std.object_proto)
+			for d.More() {
+				k, err := d.Token()
+				if err != nil {
+					return Undefined(), err
+				}
+				v, err := parse(d)
+				if err != nil {
+					return Undefined(), err
+				}
+				o.setOwn(k.(string), v)
+			}
+			if _, err := d.Token(); err != nil {
+				return Undefined(), err
+			}
+			return Value{v: o}, nil
+		}
+	}
+	return Undefined(), errors.New("unexpected token")
+}
@@ -0,0 +1,58 @@
+//go:build !(js && wasm)
+
+package js
+
+import "sync"
+
+// loop runs jobs one at a time in the order they are queued,
+// as the microtask queue of JS does.
+var loop struct {
+	mu      sync.Mutex
+	jobs    []func()
+	running bool
+}
+
+func enqueue(job func()) {
+	loop.mu.Lock()
+	defer loop.mu.Unlock()
+
+	loop.jobs = append(loop.jobs, job)
+	if !loop.running {
+		loop.running = true
+		go run()
+	}
+}
+
+func run() {
+	for {
+		loop.mu.Lock()
+		if len(loop.jobs) == 0 {
+			loop.running = false
+			loop.mu.Unlock()
+			return
+		}
+		job := loop.jobs[0]
+		loop.jobs[0] = nil
+		loop.jobs = loop.jobs[1:]
+		loop.mu.Unlock()
+
+		job()
+	}
+}
+
+// catch calls f and returns the value thrown by it.
+// Panics other than JS errors are not recovered.
+func catch(f func()) (thrown Value, ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			e, is_error := r.(Error)
+			if !is_error {
+				panic(r)
+			}
+			thrown, ok = e.Value, true
+		}
+	}()
+
+	f()
+	return Undefined(), false
+}
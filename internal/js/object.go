@@ -0,0 +1,316 @@
+//go:build !(js && wasm)
+
+package js
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// lock guards every object, as JS runs on a single thread while Go does not.
+// It is never held while calling functions.
+var lock sync.Mutex
+
+type kind int
+
+const (
+	kindPlain kind = iota
+	kindArray
+	kindBytes
+	kindDate
+	kindPromise
+	kindSignal
+)
+
+type object struct {
+	proto *object
+	props map[string]Value
+	// Keys of props in insertion order.
+	keys []string
+
+	// Set for functions.
+	call func(this Value, args []Value) Value
+	// Set for builtin constructors, which construct without calling.
+	ctor func(args []Value) Value
+
+	kind  kind
+	elems []Value
+	bytes []byte
+	// Milliseconds since the Unix epoch.
+	time    float64
+	promise *promise
+	// Listeners of AbortSignal.
+	listeners []Value
+}
+
+func newObject(proto *object) *object {
+	return &object{proto: proto, props: map[string]Value{}}
+}
+
+func newArray(elems []Value) *object {
+	o := newObject(std.array_proto)
+	o.kind = kindArray
+	o.elems = elems
+	return o
+}
+
+func newBytes(b []byte) *object {
+	o := newObject(std.bytes_proto)
+	o.kind = kindBytes
+	o.bytes = b
+	return o
+}
+
+// newFunction returns a function with its own prototype object so it can be a constructor.
+func newFunction(name string, call func(this Value, args []Value) Value) *object {
+	o := newObject(std.function_proto)
+	o.call = call
+
+	proto := newObject(std.object_proto)
+	proto.setOwn("constructor", Value{v: o})
+	o.setOwn("name", Value{v: name})
+	o.setOwn("prototype", Value{v: proto})
+	return o
+}
+
+// index parses p as an array index.
+func index(p string) (int, bool) {
+	if p == "" || (len(p) > 1 && p[0] == '0') {
+		return 0, false
+	}
+	i, err := strconv.Atoi(p)
+	if err != nil || i < 0 {
+		return 0, false
+	}
+	return i, true
+}
+
+func (o *object) get(p string) Value {
+	lock.Lock()
+	defer lock.Unlock()
+
+	return o.lookup(p)
+}
+
+func (o *object) lookup(p string) Value {
+	switch o.kind {
+	case kindArray:
+		if p == "length" {
+			return Value{v: float64(len(o.elems))}
+		}
+		if i, ok := index(p); ok {
+			if i < len(o.elems) {
+				return o.elems[i]
+			}
+			return Undefined()
+		}
+	case kindBytes:
+		if p == "length" || p == "byteLength" {
+			return Value{v: float64(len(o.bytes))}
+		}
+		if i, ok := index(p); ok {
+			if i < len(o.bytes) {
+				return Value{v: float64(o.bytes[i])}
+			}
+			return Undefined()
+		}
+	}
+
+	for x := o; x != nil; x = x.proto {
+		if v, ok := x.props[p]; ok {
+			return v
+		}
+	}
+	return Undefined()
+}
+
+func (o *object) set(p string, v Value) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	switch o.kind {
+	case kindArray:
+		if p == "length" {
+			n := int(toNumber(v))
+			if n < len(o.elems) {
+				o.elems = o.elems[:n]
+			} else {
+				o.elems = append(o.elems, make([]Value, n-len(o.elems))...)
+			}
+			return
+		}
+		if i, ok := index(p); ok {
+			if i >= len(o.elems) {
+				o.elems = append(o.elems, make([]Value, i+1-len(o.elems))...)
+			}
+			o.elems[i] = v
+			return
+		}
+	case kindBytes:
+		if i, ok := index(p); ok {
+			// Out of range writes are ignored as typed arrays do.
+			if i < len(o.bytes) {
+				o.bytes[i] = byte(int64(toNumber(v)))
+			}
+			return
+		}
+	}
+
+	o.setOwn(p, v)
+}
+
+func (o *object) setOwn(p string, v Value) {
+	if _, ok := o.props[p]; !ok {
+		o.keys = append(o.keys, p)
+	}
+	o.props[p] = v
+}
+
+func (o *object) delete(p string) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	if o.kind == kindArray {
+		if i, ok := index(p); ok {
+			if i < len(o.elems) {
+				o.elems[i] = Undefined()
+			}
+			return
+		}
+	}
+	if _, ok := o.props[p]; !ok {
+		return
+	}
+	delete(o.props, p)
+	for i, k := range o.keys {
+		if k == p {
+			o.keys = append(o.keys[:i:i], o.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+// ownKeys returns the own enumerable keys as Object.keys does.
+func (o *object) ownKeys() []string {
+	lock.Lock()
+	defer lock.Unlock()
+
+	ks := []string{}
+	switch o.kind {
+	case kindArray:
+		for i := range o.elems {
+			ks = append(ks, strconv.Itoa(i))
+		}
+	case kindBytes:
+		for i := range o.bytes {
+			ks = append(ks, strconv.Itoa(i))
+		}
+	}
+	return append(ks, o.keys...)
+}
+
+func (o *object) invoke(this Value, args []Value) Value {
+	return o.call(this, args)
+}
+
+func (o *object) construct(args []Value) Value {
+	if o.ctor != nil {
+		return o.ctor(args)
+	}
+
+	proto := o.get("prototype").object()
+	if proto == nil {
+		proto = std.object_proto
+	}
+	this := Value{v: newObject(proto)}
+	if r := o.call(this, args); r.object() != nil {
+		return r
+	}
+	return this
+}
+
+// throw returns an error to panic with, as JS throws.
+func throw(name string, msg string) Error {
+	proto := std.error_proto
+	if name == "TypeError" {
+		proto = std.type_error
+	}
+	o := newObject(proto)
+	o.setOwn("name", Value{v: name})
+	o.setOwn("message", Value{v: msg})
+	return Error{Value: Value{v: o}}
+}
+
+// arg returns the i-th argument or undefined.
+func arg(args []Value, i int) Value {
+	if i < len(args) {
+		return args[i]
+	}
+	return Undefined()
+}
+
+func toNumber(v Value) float64 {
+	switch x := v.v.(type) {
+	case float64:
+		return x
+	case bool:
+		if x {
+			return 1
+		}
+		return 0
+	case null:
+		return 0
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(x), 64)
+		if err != nil {
+			return math.NaN()
+		}
+		return f
+	}
+	return math.NaN()
+}
+
+// toString converts the value into string as String(v) does.
+func toString(v Value) string {
+	switch x := v.v.(type) {
+	case nil:
+		return "undefined"
+	case null:
+		return "null"
+	case bool:
+		return strconv.FormatBool(x)
+	case float64:
+		return formatNumber(x)
+	case string:
+		return x
+	case *object:
+		if f := x.get("toString"); f.Type() == TypeFunction {
+			return toString(f.object().invoke(v, nil))
+		}
+	}
+	return "[object Object]"
+}
+
+// formatNumber formats the number as JS does.
+func formatNumber(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	case f == 0:
+		return "0"
+	}
+
+	if a := math.Abs(f); a >= 1e21 || a < 1e-6 {
+		s := strconv.FormatFloat(f, 'e', -1, 64)
+		// Go pads the exponent to two digits.
+		s = strings.Replace(s, "e-0", "e-", 1)
+		return strings.Replace(s, "e+0", "e+", 1)
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
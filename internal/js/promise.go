@@ -0,0 +1,193 @@
+//go:build !(js && wasm)
+
+package js
+
+import "sync/atomic"
+
+type promiseState int
+
+const (
+	promisePending promiseState = iota
+	promiseFulfilled
+	promiseRejected
+)
+
+type promise struct {
+	state  promiseState
+	result Value
+	// Called once the promise is settled.
+	reactions []func(state promiseState, result Value)
+}
+
+func newPromise() *object {
+	o := newObject(std.promise_proto)
+	o.kind = kindPromise
+	o.promise = &promise{}
+	return o
+}
+
+// settle settles the promise and schedules its reactions.
+func (o *object) settle(state promiseState, v Value) {
+	lock.Lock()
+	p := o.promise
+	if p.state != promisePending {
+		lock.Unlock()
+		return
+	}
+	p.state = state
+	p.result = v
+	reactions := p.reactions
+	p.reactions = nil
+	lock.Unlock()
+
+	for _, r := range reactions {
+		enqueue(func() { r(state, v) })
+	}
+}
+
+// resolve resolves the promise with given value, adopting its state if it is a thenable.
+func (o *object) resolve(v Value) {
+	x := v.object()
+	if x == nil {
+		o.settle(promiseFulfilled, v)
+		return
+	}
+	if x == o {
+		o.settle(promiseRejected, throw("TypeError", "Chaining cycle detected for promise").Value)
+		return
+	}
+
+	then := x.get("then")
+	if then.Type() != TypeFunction {
+		o.settle(promiseFulfilled, v)
+		return
+	}
+	enqueue(func() {
+		resolve, reject := o.resolvers()
+		if r, ok := catch(func() { then.object().invoke(v, []Value{resolve, reject}) }); ok {
+			reject.object().invoke(Undefined(), []Value{r})
+		}
+	})
+}
+
+// resolvers returns the resolving functions of the promise.
+// Only the first call of either takes effect.
+func (o *object) resolvers() (Value, Value) {
+	done := &atomic.Bool{}
+	resolve := newFunction("", func(this Value, args []Value) Value {
+		if !done.Swap(true) {
+			o.resolve(arg(args, 0))
+		}
+		return Undefined()
+	})
+	reject := newFunction("", func(this Value, args []Value) Value {
+		if !done.Swap(true) {
+			o.settle(promiseRejected, arg(args, 0))
+		}
+		return Undefined()
+	})
+	return Value{v: resolve}, Value{v: reject}
+}
+
+// then attaches the reactions and returns the derived promise.
+func (o *object) then(on_fulfilled Value, on_rejected Value) Value {
+	q := newPromise()
+	reaction := func(state promiseState, v Value) {
+		handler := on_fulfilled
+		if state == promiseRejected {
+			handler = on_rejected
+		}
+		if handler.Type() != TypeFunction {
+			if state == promiseRejected {
+				q.settle(promiseRejected, v)
+			} else {
+				q.resolve(v)
+			}
+			return
+		}
+
+		var r Value
+		if thrown, ok := catch(func() { r = handler.object().invoke(Undefined(), []Value{v}) }); ok {
+			q.settle(promiseRejected, thrown)
+			return
+		}
+		q.resolve(r)
+	}
+
+	lock.Lock()
+	p := o.promise
+	if p.state == promisePending {
+		p.reactions = append(p.reactions, reaction)
+		lock.Unlock()
+	} else {
+		state, v := p.state, p.result
+		lock.Unlock()
+		enqueue(func() { reaction(state, v) })
+	}
+
+	return Value{v: q}
+}
+
+func thisPromise(this Value, method string) *object {
+	o := this.object()
+	if o == nil || o.kind != kindPromise {
+		panic(throw("TypeError", "Method Promise.prototype."+method+" called on incompatible receiver"))
+	}
+	return o
+}
+
+func definePromise() {
+	proto := std.promise_proto
+	ctor := defineClass("Promise", proto, func(args []Value) Value {
+		executor := arg(args, 0)
+		if executor.Type() != TypeFunction {
+			panic(throw("TypeError", "Promise resolver is not a function"))
+		}
+
+		p := newPromise()
+		resolve, reject := p.resolvers()
+		if r, ok := catch(func() { executor.object().invoke(Undefined(), []Value{resolve, reject}) }); ok {
+			reject.object().invoke(Undefined(), []Value{r})
+		}
+		return Value{v: p}
+	})
+
+	method(proto, "then", func(this Value, args []Value) Value {
+		return thisPromise(this, "then").then(arg(args, 0), arg(args, 1))
+	})
+	method(proto, "catch", func(this Value, args []Value) Value {
+		return thisPromise(this, "catch").then(Undefined(), arg(args, 0))
+	})
+	method(proto, "finally", func(this Value, args []Value) Value {
+		p := thisPromise(this, "finally")
+		f := arg(args, 0)
+		if f.Type() != TypeFunction {
+			return p.then(f, f)
+		}
+		return p.then(
+			Value{v: newFunction("", func(this Value, args []Value) Value {
+				f.object().invoke(Undefined(), nil)
+				return arg(args, 0)
+			})},
+			Value{v: newFunction("", func(this Value, args []Value) Value {
+				f.object().invoke(Undefined(), nil)
+				panic(Error{Value: arg(args, 0)})
+			})},
+		)
+	})
+
+	method(ctor, "resolve", func(this Value, args []Value) Value {
+		v := arg(args, 0)
+		if o := v.object(); o != nil && o.kind == kindPromise {
+			return v
+		}
+		p := newPromise()
+		p.resolve(v)
+		return Value{v: p}
+	})
+	method(ctor, "reject", func(this Value, args []Value) Value {
+		p := newPromise()
+		p.settle(promiseRejected, arg(args, 0))
+		return Value{v: p}
+	})
+}
@@ -0,0 +1,412 @@
+//go:build !(js && wasm)
+
+package js
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"sync/atomic"
+)
+
+// Type is the type of a JS value as returned by typeof.
+type Type int
+
+const (
+	TypeUndefined Type = iota
+	TypeNull
+	TypeBoolean
+	TypeNumber
+	TypeString
+	TypeSymbol
+	TypeObject
+	TypeFunction
+)
+
+func (t Type) String() string {
+	switch t {
+	case TypeUndefined:
+		return "undefined"
+	case TypeNull:
+		return "null"
+	case TypeBoolean:
+		return "boolean"
+	case TypeNumber:
+		return "number"
+	case TypeString:
+		return "string"
+	case TypeSymbol:
+		return "symbol"
+	case TypeObject:
+		return "object"
+	case TypeFunction:
+		return "function"
+	}
+	panic("bad type")
+}
+
+// Value is a JS value. The zero value is undefined.
+type Value struct {
+	_ [0]func() // uncomparable, as syscall/js
+
+	// One of nil (undefined), null, bool, float64, string, and *object.
+	v any
+}
+
+type null struct{}
+
+func Undefined() Value {
+	return Value{}
+}
+
+func Null() Value {
+	return Value{v: null{}}
+}
+
+// Global returns the global object.
+func Global() Value {
+	return Value{v: std.global}
+}
+
+// ValueOf returns x as a JS value:
+//
+//	| Go                     | JS                     |
+//	| ---------------------- | ---------------------- |
+//	| js.Value               | [its value]            |
+//	| js.Func                | function               |
+//	| nil                    | null                   |
+//	| bool                   | boolean                |
+//	| integers and floats    | number                 |
+//	| string                 | string                 |
+//	| []interface{}          | new array              |
+//	| map[string]interface{} | new object             |
+//
+// Panics if x is not one of the expected types.
+func ValueOf(x any) Value {
+	switch x := x.(type) {
+	case Value:
+		return x
+	case Func:
+		return x.Value
+	case nil:
+		return Null()
+	case bool:
+		return Value{v: x}
+	case int:
+		return Value{v: float64(x)}
+	case int8:
+		return Value{v: float64(x)}
+	case int16:
+		return Value{v: float64(x)}
+	case int32:
+		return Value{v: float64(x)}
+	case int64:
+		return Value{v: float64(x)}
+	case uint:
+		return Value{v: float64(x)}
+	case uint8:
+		return Value{v: float64(x)}
+	case uint16:
+		return Value{v: float64(x)}
+	case uint32:
+		return Value{v: float64(x)}
+	case uint64:
+		return Value{v: float64(x)}
+	case uintptr:
+		return Value{v: float64(x)}
+	case float32:
+		return Value{v: float64(x)}
+	case float64:
+		return Value{v: x}
+	case string:
+		return Value{v: x}
+	case []any:
+		elems := make([]Value, len(x))
+		for i, v := range x {
+			elems[i] = ValueOf(v)
+		}
+		return Value{v: newArray(elems)}
+	case map[string]any:
+		o := newObject(std.object_proto)
+		for k, v := range x {
+			o.set(k, ValueOf(v))
+		}
+		return Value{v: o}
+	default:
+		panic("ValueOf: invalid value")
+	}
+}
+
+func (v Value) object() *object {
+	o, _ := v.v.(*object)
+	return o
+}
+
+// Type returns the type of the value as typeof does.
+func (v Value) Type() Type {
+	switch v := v.v.(type) {
+	case nil:
+		return TypeUndefined
+	case null:
+		return TypeNull
+	case bool:
+		return TypeBoolean
+	case float64:
+		return TypeNumber
+	case string:
+		return TypeString
+	case *object:
+		if v.call != nil {
+			return TypeFunction
+		}
+		return TypeObject
+	}
+	panic("bad type")
+}
+
+func (v Value) mustObject(method string) *object {
+	o := v.object()
+	if o == nil {
+		panic(&ValueError{Method: method, Type: v.Type()})
+	}
+	return o
+}
+
+// Get returns the property p of the value.
+func (v Value) Get(p string) Value {
+	return v.mustObject("Value.Get").get(p)
+}
+
+// Set sets the property p of the value to ValueOf(x).
+func (v Value) Set(p string, x any) {
+	v.mustObject("Value.Set").set(p, ValueOf(x))
+}
+
+// Delete deletes the property p of the value.
+func (v Value) Delete(p string) {
+	v.mustObject("Value.Delete").delete(p)
+}
+
+// Index returns the i-th element of the value.
+func (v Value) Index(i int) Value {
+	return v.mustObject("Value.Index").get(strconv.Itoa(i))
+}
+
+// SetIndex sets the i-th element of the value to ValueOf(x).
+func (v Value) SetIndex(i int, x any) {
+	v.mustObject("Value.SetIndex").set(strconv.Itoa(i), ValueOf(x))
+}
+
+// Length returns the "length" property of the value.
+func (v Value) Length() int {
+	return v.mustObject("Value.Length").get("length").Int()
+}
+
+func valuesOf(args []any) []Value {
+	vs := make([]Value, len(args))
+	for i, arg := range args {
+		vs[i] = ValueOf(arg)
+	}
+	return vs
+}
+
+// Call calls the method m of the value with given arguments.
+func (v Value) Call(m string, args ...any) Value {
+	o := v.mustObject("Value.Call")
+	f := o.get(m)
+	if f.Type() != TypeFunction {
+		panic("syscall/js: Value.Call: property " + m + " is not a function, got " + f.Type().String())
+	}
+	return f.object().invoke(v, valuesOf(args))
+}
+
+// Invoke calls the value as a function with given arguments.
+func (v Value) Invoke(args ...any) Value {
+	if v.Type() != TypeFunction {
+		panic(&ValueError{Method: "Value.Invoke", Type: v.Type()})
+	}
+	return v.object().invoke(Undefined(), valuesOf(args))
+}
+
+// New calls the value as a constructor with given arguments.
+func (v Value) New(args ...any) Value {
+	if v.Type() != TypeFunction {
+		panic(&ValueError{Method: "Value.New", Type: v.Type()})
+	}
+	return v.object().construct(valuesOf(args))
+}
+
+func (v Value) float(method string) float64 {
+	f, ok := v.v.(float64)
+	if !ok {
+		panic(&ValueError{Method: method, Type: v.Type()})
+	}
+	return f
+}
+
+// Float returns the value as float64. It panics if the value is not a number.
+func (v Value) Float() float64 {
+	return v.float("Value.Float")
+}
+
+// Int returns the value truncated to int. It panics if the value is not a number.
+func (v Value) Int() int {
+	return int(v.float("Value.Int"))
+}
+
+// Bool returns the value as bool. It panics if the value is not a boolean.
+func (v Value) Bool() bool {
+	b, ok := v.v.(bool)
+	if !ok {
+		panic(&ValueError{Method: "Value.Bool", Type: v.Type()})
+	}
+	return b
+}
+
+// Truthy returns the JavaScript "truthiness" of the value.
+func (v Value) Truthy() bool {
+	switch x := v.v.(type) {
+	case nil, null:
+		return false
+	case bool:
+		return x
+	case float64:
+		return x != 0 && !math.IsNaN(x)
+	case string:
+		return x != ""
+	}
+	return true
+}
+
+// String returns the value as string.
+// Values of other types are formatted as "<T>" or "<T: V>" as syscall/js does.
+func (v Value) String() string {
+	switch x := v.v.(type) {
+	case string:
+		return x
+	case nil:
+		return "<undefined>"
+	case null:
+		return "<null>"
+	case bool:
+		return fmt.Sprintf("<boolean: %t>", x)
+	case float64:
+		return "<number: " + formatNumber(x) + ">"
+	}
+	return "<" + v.Type().String() + ">"
+}
+
+func (v Value) IsUndefined() bool {
+	return v.v == nil
+}
+
+func (v Value) IsNull() bool {
+	_, ok := v.v.(null)
+	return ok
+}
+
+// Equal reports whether the values are strictly equal as === does.
+func (v Value) Equal(w Value) bool {
+	return v.v == w.v
+}
+
+// InstanceOf reports whether the value is an instance of given constructor as instanceof does.
+func (v Value) InstanceOf(t Value) bool {
+	if t.Type() != TypeFunction {
+		panic(throw("TypeError", "Right-hand side of 'instanceof' is not callable"))
+	}
+
+	o := v.object()
+	if o == nil {
+		return false
+	}
+	proto := t.Get("prototype").object()
+	if proto == nil {
+		return false
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	for p := o.proto; p != nil; p = p.proto {
+		if p == proto {
+			return true
+		}
+	}
+	return false
+}
+
+// Func is a Go function callable from JS.
+type Func struct {
+	Value // the JS function
+
+	released *atomic.Bool
+}
+
+// FuncOf returns a JS function that calls fn with this and the arguments.
+// It must be released by [Func.Release] once it is no longer used.
+func FuncOf(fn func(this Value, args []Value) any) Func {
+	released := &atomic.Bool{}
+	o := newFunction("", func(this Value, args []Value) Value {
+		if released.Load() {
+			panic("syscall/js: call to released function")
+		}
+		return ValueOf(fn(this, args))
+	})
+	return Func{Value: Value{v: o}, released: released}
+}
+
+// Release frees the function. Calling it afterward panics.
+func (f Func) Release() {
+	if f.released != nil {
+		f.released.Store(true)
+	}
+}
+
+// CopyBytesToGo copies bytes from src to dst and returns the number of bytes copied.
+// It panics if src is not a Uint8Array.
+func CopyBytesToGo(dst []byte, src Value) int {
+	o := src.object()
+	if o == nil || o.kind != kindBytes {
+		panic("syscall/js: CopyBytesToGo: expected src to be a Uint8Array or Uint8ClampedArray")
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	return copy(dst, o.bytes)
+}
+
+// CopyBytesToJS copies bytes from src to dst and returns the number of bytes copied.
+// It panics if dst is not a Uint8Array.
+func CopyBytesToJS(dst Value, src []byte) int {
+	o := dst.object()
+	if o == nil || o.kind != kindBytes {
+		panic("syscall/js: CopyBytesToJS: expected dst to be a Uint8Array or Uint8ClampedArray")
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	return copy(o.bytes, src)
+}
+
+// Error is a JS error thrown to Go.
+type Error struct {
+	// The thrown value.
+	Value
+}
+
+func (e Error) Error() string {
+	return "JavaScript error: " + e.Get("message").String()
+}
+
+// ValueError is the panic value of calling a method of [Value] on the value of wrong type.
+type ValueError struct {
+	Method string
+	Type   Type
+}
+
+func (e *ValueError) Error() string {
+	return "syscall/js: call of " + e.Method + " on " + e.Type.String()
+}
@@ -1,8 +1,6 @@
-//go:build js && wasm
-
 package jz
 
-import "syscall/js"
+import "github.com/lesomnus/grpc-wasm/internal/js"
 
 func BytesToGo(v js.Value) []byte {
 	b := make([]byte, v.Length())
@@ -0,0 +1,76 @@
+package jz
+
+import (
+	"context"
+
+	"github.com/lesomnus/grpc-wasm/internal/js"
+)
+
+// AbortError is the cause of the context returned by [ContextFromSignal] or
+// [ContextFromPromise] when it is cancelled by JS side.
+type AbortError struct {
+	// The abort reason of the AbortSignal or the value the promise is resolved with.
+	Reason js.Value
+}
+
+func (e *AbortError) Error() string {
+	if e.Reason.IsUndefined() {
+		return "aborted"
+	}
+	if e.Reason.InstanceOf(js.Global().Get("Error")) {
+		return "aborted: " + e.Reason.Get("message").String()
+	}
+	return "aborted: " + e.Reason.String()
+}
+
+// ContextFromSignal returns a context that is cancelled when given AbortSignal is aborted,
+// with [AbortError] holding the abort reason as its cause.
+// The listener added to the signal is removed once the context ends,
+// so callers must call the returned cancel function.
+func ContextFromSignal(parent context.Context, signal js.Value) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(parent)
+	if signal.Get("aborted").Bool() {
+		cancel(&AbortError{Reason: signal.Get("reason")})
+		return ctx, func() { cancel(context.Canceled) }
+	}
+
+	on_abort := js.FuncOf(func(this js.Value, args []js.Value) any {
+		cancel(&AbortError{Reason: signal.Get("reason")})
+		return js.Undefined()
+	})
+	signal.Call("addEventListener", "abort", on_abort)
+	go func() {
+		<-ctx.Done()
+		signal.Call("removeEventListener", "abort", on_abort)
+		on_abort.Release()
+	}()
+
+	return ctx, func() { cancel(context.Canceled) }
+}
+
+// ContextFromPromise returns a context that is cancelled when given promise is resolved,
+// with [AbortError] holding the resolved value as its cause.
+// Rejection of the promise does not cancel the context.
+// The callback attached to the promise is released once the context ends,
+// so callers must call the returned cancel function.
+func ContextFromPromise(parent context.Context, p js.Value) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(parent)
+
+	on_resolve := js.FuncOf(func(this js.Value, args []js.Value) any {
+		cancel(&AbortError{Reason: args[0]})
+		return js.Undefined()
+	})
+
+	// A callback attached to a promise cannot be detached,
+	// so it is called through a box which is emptied before the callback is released.
+	box := js.Global().Get("Object").New()
+	box.Set("f", on_resolve)
+	p.Call("then", boxedCall.Invoke(box, "f"))
+	go func() {
+		<-ctx.Done()
+		box.Delete("f")
+		on_resolve.Release()
+	}()
+
+	return ctx, func() { cancel(context.Canceled) }
+}
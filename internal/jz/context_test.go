@@ -0,0 +1,92 @@
+package jz_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lesomnus/grpc-wasm/internal/js"
+	"github.com/lesomnus/grpc-wasm/internal/jz"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContextFromSignal(t *testing.T) {
+	t.Run("aborted", func(t *testing.T) {
+		x := require.New(t)
+
+		ac := js.Global().Get("AbortController").New()
+		ctx, cancel := jz.ContextFromSignal(t.Context(), ac.Get("signal"))
+		defer cancel()
+		x.NoError(ctx.Err())
+
+		ac.Call("abort", "foo")
+		<-ctx.Done()
+
+		var err *jz.AbortError
+		x.ErrorAs(context.Cause(ctx), &err)
+		x.Equal("foo", err.Reason.String())
+	})
+	t.Run("already aborted", func(t *testing.T) {
+		x := require.New(t)
+
+		signal := js.Global().Get("AbortSignal").Call("abort", jz.Error("foo"))
+		ctx, cancel := jz.ContextFromSignal(t.Context(), signal)
+		defer cancel()
+		x.Error(ctx.Err())
+		x.ErrorContains(context.Cause(ctx), "aborted: foo")
+	})
+	t.Run("cancelled", func(t *testing.T) {
+		x := require.New(t)
+
+		ac := js.Global().Get("AbortController").New()
+		ctx, cancel := jz.ContextFromSignal(t.Context(), ac.Get("signal"))
+		cancel()
+		x.ErrorIs(context.Cause(ctx), context.Canceled)
+
+		// Abort after cancel must not affect the context.
+		ac.Call("abort")
+		x.ErrorIs(context.Cause(ctx), context.Canceled)
+	})
+}
+
+func TestContextFromPromise(t *testing.T) {
+	t.Run("resolved", func(t *testing.T) {
+		x := require.New(t)
+
+		p, resolve := newPromise()
+		ctx, cancel := jz.ContextFromPromise(t.Context(), p)
+		defer cancel()
+		x.NoError(ctx.Err())
+
+		resolve.Invoke("foo")
+		<-ctx.Done()
+
+		var err *jz.AbortError
+		x.ErrorAs(context.Cause(ctx), &err)
+		x.Equal("foo", err.Reason.String())
+	})
+	t.Run("resolved after cancel", func(t *testing.T) {
+		x := require.New(t)
+
+		p, resolve := newPromise()
+		ctx, cancel := jz.ContextFromPromise(t.Context(), p)
+		cancel()
+
+		// The callback is released but resolving the promise must not panic.
+		resolve.Invoke("foo")
+		_, err_js := jz.Await(p)
+		x.True(err_js.IsUndefined())
+		x.ErrorIs(context.Cause(ctx), context.Canceled)
+	})
+}
+
+func newPromise() (js.Value, js.Value) {
+	var resolve js.Value
+	executor := js.FuncOf(func(this js.Value, args []js.Value) any {
+		resolve = args[0]
+		return js.Undefined()
+	})
+	defer executor.Release()
+
+	p := js.Global().Get("Promise").New(executor)
+	return p, resolve
+}
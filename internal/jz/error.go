@@ -1,20 +1,175 @@
-//go:build js && wasm
-
 package jz
 
 import (
+	"errors"
 	"fmt"
-	"syscall/js"
+
+	"github.com/lesomnus/grpc-wasm/internal/js"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorKind tells where an error made by [NewError] comes from.
+type ErrorKind string
+
+const (
+	// Status returned by the server.
+	ErrorKindStatus ErrorKind = "status"
+	// Connection to the server failed, i.e. status UNAVAILABLE.
+	ErrorKindTransport ErrorKind = "transport"
+	// Arguments given by JS are wrong.
+	ErrorKindArgument ErrorKind = "argument"
+	// Any other failure in the bridge including panics.
+	ErrorKindInternal ErrorKind = "internal"
+)
+
+// errorClass is the constructor of GrpcWasmError
+// and typeErrorClass is the one of GrpcWasmTypeError, its counterpart extending TypeError.
+// JS side can provide its own classes of the same shape by setting globalThis.GrpcWasmError
+// and globalThis.GrpcWasmTypeError before the program starts.
+var (
+	errorClass     = globalErrorClass("GrpcWasmError", "Error")
+	typeErrorClass = globalErrorClass("GrpcWasmTypeError", "TypeError")
 )
 
+func globalErrorClass(name string, base string) js.Value {
+	if v := js.Global().Get(name); v.Type() == js.TypeFunction {
+		return v
+	}
+
+	v := newErrorClass(name, base)
+	js.Global().Set(name, v)
+	return v
+}
+
+// panicError is an error recovered from a panic.
+type panicError struct {
+	v any
+}
+
+func (e *panicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.v)
+}
+
+// NewError converts Go error into JS GrpcWasmError:
+//
+//	class GrpcWasmError extends Error {
+//		kind: "status" | "transport" | "argument" | "internal"
+//		code: number
+//		grpcMessage: string
+//		details: { type_url: string; value: Uint8Array }[]
+//		metadata: { [key: string]: string[] }
+//		cause?: GrpcWasmError
+//	}
+//
+// Wrong arguments and recovered panics are GrpcWasmTypeError instead,
+// which has the same fields but extends TypeError and is also an instance of GrpcWasmError.
+// The cause is built from [errors.Unwrap] of the error.
+// Metadata is the trailer of the call if the error is from a call, otherwise it can be nil.
+func NewError(err error, md map[string][]string) js.Value {
+	s := status.Convert(err)
+
+	class := errorClass
+	kind := ErrorKindInternal
+	code := s.Code()
+	var (
+		type_err  *TypeError
+		panic_err *panicError
+	)
+	switch {
+	case errors.As(err, &type_err):
+		class = typeErrorClass
+		kind = ErrorKindArgument
+		code = codes.InvalidArgument
+	case errors.As(err, &panic_err):
+		class = typeErrorClass
+		kind = ErrorKindInternal
+		code = codes.Internal
+	case code == codes.Unavailable:
+		kind = ErrorKindTransport
+	case isStatus(err):
+		kind = ErrorKindStatus
+	}
+
+	details := js.Global().Get("Array").New()
+	for _, d := range s.Proto().GetDetails() {
+		details.Call("push", map[string]any{
+			"type_url": d.GetTypeUrl(),
+			"value":    BytesToJs(d.GetValue()),
+		})
+	}
+
+	meta := js.Global().Get("Object").New()
+	for k, vs := range md {
+		a := js.Global().Get("Array").New()
+		for _, v := range vs {
+			a.Call("push", v)
+		}
+		meta.Set(k, a)
+	}
+
+	init := map[string]any{
+		"kind":        string(kind),
+		"code":        int(code),
+		"grpcMessage": s.Message(),
+		"details":     details,
+		"metadata":    meta,
+	}
+	if cause := errors.Unwrap(err); cause != nil {
+		init["cause"] = NewError(cause, nil)
+	}
+
+	return class.New(err.Error(), init)
+}
+
+// isStatus reports whether the error carries gRPC status.
+func isStatus(err error) bool {
+	var s interface{ GRPCStatus() *status.Status }
+	return errors.As(err, &s)
+}
+
+// Error returns GrpcWasmError of kind "internal" with given message.
 func Error(msg string) js.Value {
-	return js.Global().Get("Error").New(msg)
+	return NewError(errors.New(msg), nil)
 }
 
+// ToError converts Go error into GrpcWasmError. See [NewError].
 func ToError(err error) js.Value {
-	return Error(err.Error())
+	return NewError(err, nil)
 }
 
 func ErrorF(format string, a ...any) js.Value {
-	return js.Global().Get("Error").New(fmt.Sprintf(format, a...))
+	return NewError(fmt.Errorf(format, a...), nil)
+}
+
+// fromPanic converts a value recovered from a panic into GrpcWasmError of kind "internal".
+func fromPanic(r any) js.Value {
+	return NewError(&panicError{v: r}, nil)
+}
+
+// JsError is a value thrown by JS, converted by [FromJs].
+type JsError struct {
+	// Name of the error class, e.g. "TypeError". Empty if the value is not an Error.
+	Name    string
+	Message string
+}
+
+func (e *JsError) Error() string {
+	if e.Name == "" {
+		return e.Message
+	}
+	return e.Name + ": " + e.Message
+}
+
+// FromJs converts the value thrown by JS, e.g. the reason of a rejected promise, into Go error.
+// Values other than Error are described by their string or JSON.
+func FromJs(v js.Value) error {
+	switch {
+	case v.Type() == js.TypeObject && v.InstanceOf(js.Global().Get("Error")):
+		return &JsError{Name: v.Get("name").String(), Message: v.Get("message").String()}
+	case v.Type() == js.TypeString:
+		return &JsError{Message: v.String()}
+	default:
+		return &JsError{Message: Stringify(v)}
+	}
 }
@@ -0,0 +1,82 @@
+package jz_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/lesomnus/grpc-wasm/internal/js"
+	"github.com/lesomnus/grpc-wasm/internal/jz"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestNewError(t *testing.T) {
+	t.Run("status", func(t *testing.T) {
+		x := require.New(t)
+
+		s, err := status.New(codes.NotFound, "Where's the money, Lebowski?").WithDetails(&errdetails.ErrorInfo{Reason: "foo"})
+		x.NoError(err)
+
+		v := jz.NewError(s.Err(), map[string][]string{"foo": {"bar"}})
+		x.True(v.InstanceOf(js.Global().Get("Error")))
+		x.True(v.InstanceOf(js.Global().Get("GrpcWasmError")))
+		x.Equal("GrpcWasmError", v.Get("name").String())
+		x.Equal("status", v.Get("kind").String())
+		x.Equal(int(codes.NotFound), v.Get("code").Int())
+		x.Equal("Where's the money, Lebowski?", v.Get("grpcMessage").String())
+		x.Equal("bar", v.Get("metadata").Get("foo").Index(0).String())
+		x.Equal(1, v.Get("details").Length())
+		x.Contains(v.Get("details").Index(0).Get("type_url").String(), "ErrorInfo")
+		x.True(v.Get("details").Index(0).Get("value").InstanceOf(js.Global().Get("Uint8Array")))
+	})
+	t.Run("transport", func(t *testing.T) {
+		v := jz.ToError(status.Error(codes.Unavailable, "connection refused"))
+		require.Equal(t, "transport", v.Get("kind").String())
+	})
+	t.Run("argument", func(t *testing.T) {
+		v := jz.ToError(&jz.TypeError{Path: "foo", Expected: "string", Actual: "number"})
+		require.True(t, v.InstanceOf(js.Global().Get("TypeError")))
+		require.Equal(t, "GrpcWasmTypeError", v.Get("name").String())
+		require.Equal(t, "argument", v.Get("kind").String())
+		require.Equal(t, int(codes.InvalidArgument), v.Get("code").Int())
+	})
+	t.Run("cause chain", func(t *testing.T) {
+		x := require.New(t)
+
+		inner := status.Error(codes.Aborted, "foo")
+		v := jz.ToError(fmt.Errorf("bar: %w", inner))
+		x.Equal("bar: rpc error: code = Aborted desc = foo", v.Get("message").String())
+		x.Equal(int(codes.Aborted), v.Get("code").Int())
+
+		cause := v.Get("cause")
+		x.True(cause.InstanceOf(js.Global().Get("GrpcWasmError")))
+		x.Equal("status", cause.Get("kind").String())
+		x.True(cause.Get("cause").IsUndefined())
+	})
+	t.Run("plain error", func(t *testing.T) {
+		x := require.New(t)
+
+		v := jz.ToError(errors.New("foo"))
+		x.Equal("internal", v.Get("kind").String())
+		x.Equal(int(codes.Unknown), v.Get("code").Int())
+		x.Equal("foo", v.Get("grpcMessage").String())
+		x.Equal(0, v.Get("details").Length())
+	})
+}
+
+func TestFromJs(t *testing.T) {
+	x := require.New(t)
+
+	err := jz.FromJs(js.Global().Get("Error").New("foo"))
+	x.EqualError(err, "Error: foo")
+
+	var js_err *jz.JsError
+	x.ErrorAs(err, &js_err)
+	x.Equal("foo", js_err.Message)
+
+	x.EqualError(jz.FromJs(js.ValueOf("bar")), "bar")
+	x.EqualError(jz.FromJs(js.ValueOf(map[string]any{"baz": 42})), `{"baz":42}`)
+}
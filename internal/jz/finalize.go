@@ -0,0 +1,66 @@
+package jz
+
+import (
+	"sync"
+
+	"github.com/lesomnus/grpc-wasm/internal/js"
+)
+
+var finalizer struct {
+	once     sync.Once
+	registry js.Value
+
+	mu    sync.Mutex
+	seq   int
+	hooks map[int]func()
+}
+
+// onCollected calls f once v is garbage collected using FinalizationRegistry.
+// It returns a function that cancels the hook.
+// The hook is never called if FinalizationRegistry is not available.
+func onCollected(v js.Value, f func()) func() {
+	finalizer.once.Do(func() {
+		ctor := js.Global().Get("FinalizationRegistry")
+		if ctor.Type() != js.TypeFunction {
+			return
+		}
+
+		finalizer.hooks = map[int]func(){}
+		// Lives as long as the program, so it is never released.
+		cleanup := js.FuncOf(func(this js.Value, args []js.Value) any {
+			id := args[0].Int()
+
+			finalizer.mu.Lock()
+			f, ok := finalizer.hooks[id]
+			delete(finalizer.hooks, id)
+			finalizer.mu.Unlock()
+
+			if ok {
+				// Hook may block, e.g. closing a stream.
+				go f()
+			}
+			return js.Undefined()
+		})
+		finalizer.registry = ctor.New(cleanup)
+	})
+	if finalizer.registry.IsUndefined() {
+		return func() {}
+	}
+
+	finalizer.mu.Lock()
+	finalizer.seq++
+	id := finalizer.seq
+	finalizer.hooks[id] = f
+	finalizer.mu.Unlock()
+
+	token := js.Global().Get("Object").New()
+	finalizer.registry.Call("register", v, id, token)
+
+	return func() {
+		finalizer.mu.Lock()
+		delete(finalizer.hooks, id)
+		finalizer.mu.Unlock()
+
+		finalizer.registry.Call("unregister", token)
+	}
+}
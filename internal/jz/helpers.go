@@ -0,0 +1,78 @@
+//go:build !(js && wasm)
+
+package jz
+
+import "github.com/lesomnus/grpc-wasm/internal/js"
+
+// Without a JS engine, the helpers written in JS on js/wasm are Go functions
+// which are never released as they live as long as the program.
+
+// guardedCall returns a function that calls box[key] if it is still set,
+// otherwise resolves with fallbacks[key] if it is set.
+var guardedCall = js.FuncOf(func(this js.Value, args []js.Value) any {
+	box, key, fallbacks := args[0], args[1].String(), args[2]
+	return js.FuncOf(func(this js.Value, args []js.Value) any {
+		f := box.Get(key)
+		if !f.IsUndefined() {
+			vs := make([]any, 0, len(args)+1)
+			vs = append(vs, this)
+			for _, arg := range args {
+				vs = append(vs, arg)
+			}
+			return f.Call("call", vs...)
+		}
+		if v := fallbacks.Get(key); !v.IsUndefined() {
+			return js.Global().Get("Promise").Call("resolve", v)
+		}
+		if key == "close" {
+			return js.Global().Get("Promise").Call("resolve")
+		}
+		return js.Global().Get("Promise").Call("reject", js.Global().Get("Error").New(key+": object is released"))
+	})
+})
+
+// boxedCall returns a function that calls box[key] if it is still set.
+var boxedCall = js.FuncOf(func(this js.Value, args []js.Value) any {
+	box, key := args[0], args[1].String()
+	return js.FuncOf(func(this js.Value, args []js.Value) any {
+		if f := box.Get(key); f.Truthy() {
+			f.Invoke(Arg(args, 0))
+		}
+		return js.Undefined()
+	})
+})
+
+// newErrorClass returns the default class of GrpcWasmError, or of GrpcWasmTypeError
+// which extends TypeError if base is "TypeError".
+func newErrorClass(name string, base string) js.Value {
+	class := js.FuncOf(func(this js.Value, args []js.Value) any {
+		message := Arg(args, 0)
+		init := Arg(args, 1)
+		if isNullish(init) {
+			init = js.Global().Get("Object").New()
+		}
+		or := func(key string, v any) js.Value {
+			if w := init.Get(key); !isNullish(w) {
+				return w
+			}
+			return js.ValueOf(v)
+		}
+
+		if !message.IsUndefined() {
+			this.Set("message", message)
+		}
+		if cause := init.Get("cause"); !cause.IsUndefined() {
+			this.Set("cause", cause)
+		}
+		this.Set("name", name)
+		this.Set("kind", or("kind", "internal"))
+		this.Set("code", or("code", 2))
+		this.Set("grpcMessage", or("grpcMessage", message))
+		this.Set("details", or("details", js.Global().Get("Array").New()))
+		this.Set("metadata", or("metadata", js.Global().Get("Object").New()))
+		return js.Undefined()
+	})
+
+	js.Global().Get("Object").Call("setPrototypeOf", class.Get("prototype"), js.Global().Get(base).Get("prototype"))
+	return class.Value
+}
@@ -0,0 +1,39 @@
+//go:build js && wasm
+
+package jz
+
+import "github.com/lesomnus/grpc-wasm/internal/js"
+
+// guardedCall returns a function that calls box[key] if it is still set,
+// otherwise resolves with fallbacks[key] if it is set.
+var guardedCall = js.Global().Get("Function").New("box", "key", "fallbacks", `return function (...args) {
+	const f = box[key];
+	if (f !== undefined) return f.apply(this, args);
+	if (fallbacks[key] !== undefined) return Promise.resolve(fallbacks[key]);
+	if (key === "close") return Promise.resolve();
+	return Promise.reject(new Error(key + ": object is released"));
+}`)
+
+// boxedCall returns a function that calls box[key] if it is still set.
+var boxedCall = js.Global().Get("Function").New("box", "key", "return (v) => { const f = box[key]; if (f) f(v); }")
+
+// newErrorClass returns the default class of GrpcWasmError, or of GrpcWasmTypeError
+// which extends TypeError if base is "TypeError".
+// GrpcWasmError given by JS is expected to count GrpcWasmTypeError as its instance.
+func newErrorClass(name string, base string) js.Value {
+	return js.Global().Get("Function").New("name", "base", `return class extends globalThis[base] {
+	constructor(message, init = {}) {
+		super(message, init.cause === undefined ? undefined : { cause: init.cause });
+		this.name = name;
+		this.kind = init.kind ?? "internal";
+		this.code = init.code ?? 2;
+		this.grpcMessage = init.grpcMessage ?? message;
+		this.details = init.details ?? [];
+		this.metadata = init.metadata ?? {};
+	}
+	static [Symbol.hasInstance](v) {
+		if (Function.prototype[Symbol.hasInstance].call(this, v)) return true;
+		return this === globalThis.GrpcWasmError && v instanceof globalThis.GrpcWasmTypeError;
+	}
+}`).Invoke(name, base)
+}
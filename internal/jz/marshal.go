@@ -0,0 +1,454 @@
+package jz
+
+import (
+	"encoding"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lesomnus/grpc-wasm/internal/js"
+)
+
+// Marshaler is implemented by types that convert themselves into JS value.
+type Marshaler interface {
+	MarshalJS() (js.Value, error)
+}
+
+// Unmarshaler is implemented by types that read themselves from JS value.
+type Unmarshaler interface {
+	UnmarshalJS(v js.Value) error
+}
+
+// TypeError is returned by [Unmarshal] if the JS value does not fit into the Go value,
+// and by [Marshal] if the Go value cannot be represented in JS.
+type TypeError struct {
+	// Path to the value from the root, e.g. `.meta["foo"][0]`.
+	Path     string
+	Expected string
+	Actual   string
+}
+
+func (e *TypeError) Error() string {
+	path := e.Path
+	if path == "" {
+		path = "value"
+	}
+	return fmt.Sprintf("jz: %s: expected %s, got %s", path, e.Expected, e.Actual)
+}
+
+var (
+	jsValueType     = reflect.TypeFor[js.Value]()
+	jsFuncType      = reflect.TypeFor[js.Func]()
+	timeType        = reflect.TypeFor[time.Time]()
+	marshalerType   = reflect.TypeFor[Marshaler]()
+	unmarshalerType = reflect.TypeFor[Unmarshaler]()
+	textMarshalType = reflect.TypeFor[encoding.TextMarshaler]()
+	textUnmarshType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// Marshal converts Go value into JS value.
+//
+//   - bool, numbers and strings are converted to corresponding JS primitives.
+//   - []byte is copied into a Uint8Array.
+//   - time.Time is converted to Date.
+//   - Slices and arrays are converted to Array, nil slice to null.
+//   - Maps are converted to Object, nil map to null.
+//     Keys must be strings, integers, or implement [encoding.TextMarshaler].
+//   - Structs are converted to Object. Field names are given by `js:"name"` tag
+//     or the field name if the tag is not given. Field with tag "-" is skipped.
+//     Fields with "omitempty" option are omitted if they are zero value.
+//   - Nil pointer and nil interface are converted to null.
+//   - js.Value and js.Func are used as is.
+func Marshal(v any) (js.Value, error) {
+	if v == nil {
+		return js.Null(), nil
+	}
+	return marshal("", reflect.ValueOf(v))
+}
+
+func marshal(path string, v reflect.Value) (js.Value, error) {
+	t := v.Type()
+	switch t {
+	case jsValueType:
+		return v.Interface().(js.Value), nil
+	case jsFuncType:
+		return v.Interface().(js.Func).Value, nil
+	case timeType:
+		t := v.Interface().(time.Time)
+		return js.Global().Get("Date").New(t.UnixMilli()), nil
+	}
+	if t.Implements(marshalerType) {
+		if t.Kind() == reflect.Pointer && v.IsNil() {
+			return js.Null(), nil
+		}
+		return v.Interface().(Marshaler).MarshalJS()
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return js.ValueOf(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return js.ValueOf(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return js.ValueOf(v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return js.ValueOf(v.Float()), nil
+	case reflect.String:
+		return js.ValueOf(v.String()), nil
+
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return js.Null(), nil
+		}
+		return marshal(path, v.Elem())
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return js.Null(), nil
+		}
+		if t.Elem().Kind() == reflect.Uint8 {
+			return BytesToJs(v.Bytes()), nil
+		}
+		fallthrough
+	case reflect.Array:
+		a := js.Global().Get("Array").New(v.Len())
+		for i := range v.Len() {
+			u, err := marshal(fmt.Sprintf("%s[%d]", path, i), v.Index(i))
+			if err != nil {
+				return js.Undefined(), err
+			}
+			a.SetIndex(i, u)
+		}
+		return a, nil
+
+	case reflect.Map:
+		if v.IsNil() {
+			return js.Null(), nil
+		}
+		o := js.Global().Get("Object").New()
+		it := v.MapRange()
+		for it.Next() {
+			k, err := mapKeyToString(path, it.Key())
+			if err != nil {
+				return js.Undefined(), err
+			}
+			u, err := marshal(fmt.Sprintf("%s[%q]", path, k), it.Value())
+			if err != nil {
+				return js.Undefined(), err
+			}
+			o.Set(k, u)
+		}
+		return o, nil
+
+	case reflect.Struct:
+		o := js.Global().Get("Object").New()
+		for _, f := range fieldsOf(t) {
+			u := v.FieldByIndex(f.index)
+			if f.omitempty && u.IsZero() {
+				continue
+			}
+			w, err := marshal(path+"."+f.name, u)
+			if err != nil {
+				return js.Undefined(), err
+			}
+			o.Set(f.name, w)
+		}
+		return o, nil
+	}
+
+	return js.Undefined(), &TypeError{Path: path, Expected: "value representable in JS", Actual: t.String()}
+}
+
+func mapKeyToString(path string, k reflect.Value) (string, error) {
+	if k.Type().Implements(textMarshalType) {
+		b, err := k.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return "", fmt.Errorf("jz: %s: %w", path, err)
+		}
+		return string(b), nil
+	}
+
+	switch k.Kind() {
+	case reflect.String:
+		return k.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10), nil
+	}
+
+	return "", &TypeError{Path: path, Expected: "map key of string, integer or encoding.TextMarshaler", Actual: k.Type().String()}
+}
+
+// Unmarshal reads JS value into Go value pointed by dst.
+// It is the inverse of [Marshal] with following rules:
+//
+//   - undefined and null leave the value unchanged except pointers which are set to nil.
+//     Struct fields with "required" option must not be undefined or null.
+//   - Numbers must be integral and in range for integer types.
+//   - []byte accepts Uint8Array.
+//   - Slices accept Array, maps and structs accept Object.
+//   - time.Time accepts Date.
+//   - js.Value receives the value as is.
+func Unmarshal(v js.Value, dst any) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("jz: destination must be a non-nil pointer, got %T", dst)
+	}
+	return unmarshal("", v, rv.Elem())
+}
+
+func isNullish(v js.Value) bool {
+	return v.IsUndefined() || v.IsNull()
+}
+
+func describe(v js.Value) string {
+	switch {
+	case v.IsNull():
+		return "null"
+	case v.Type() != js.TypeObject:
+		return v.Type().String()
+	case js.Global().Get("Array").Call("isArray", v).Bool():
+		return "Array"
+	}
+
+	c := v.Get("constructor")
+	if c.Type() == js.TypeFunction {
+		if name := c.Get("name"); name.Type() == js.TypeString && name.String() != "" {
+			return name.String()
+		}
+	}
+	return "object"
+}
+
+func unmarshal(path string, v js.Value, dst reflect.Value) error {
+	t := dst.Type()
+	switch t {
+	case jsValueType:
+		dst.Set(reflect.ValueOf(v))
+		return nil
+	case timeType:
+		if isNullish(v) {
+			return nil
+		}
+		if !v.InstanceOf(js.Global().Get("Date")) {
+			return &TypeError{Path: path, Expected: "Date", Actual: describe(v)}
+		}
+		dst.Set(reflect.ValueOf(time.UnixMilli(int64(v.Call("getTime").Float()))))
+		return nil
+	}
+	if reflect.PointerTo(t).Implements(unmarshalerType) {
+		return dst.Addr().Interface().(Unmarshaler).UnmarshalJS(v)
+	}
+
+	if t.Kind() == reflect.Pointer {
+		if isNullish(v) {
+			dst.SetZero()
+			return nil
+		}
+		if dst.IsNil() {
+			dst.Set(reflect.New(t.Elem()))
+		}
+		return unmarshal(path, v, dst.Elem())
+	}
+	if isNullish(v) {
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		if v.Type() != js.TypeBoolean {
+			return &TypeError{Path: path, Expected: "boolean", Actual: describe(v)}
+		}
+		dst.SetBool(v.Bool())
+		return nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		f, err := integerOf(path, v)
+		if err != nil {
+			return err
+		}
+		if f < math.MinInt64 || f >= math.MaxInt64 || dst.OverflowInt(int64(f)) {
+			return &TypeError{Path: path, Expected: t.String(), Actual: "number " + v.String()}
+		}
+		dst.SetInt(int64(f))
+		return nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		f, err := integerOf(path, v)
+		if err != nil {
+			return err
+		}
+		if f < 0 || f >= math.MaxUint64 || dst.OverflowUint(uint64(f)) {
+			return &TypeError{Path: path, Expected: t.String(), Actual: "number " + v.String()}
+		}
+		dst.SetUint(uint64(f))
+		return nil
+
+	case reflect.Float32, reflect.Float64:
+		if v.Type() != js.TypeNumber {
+			return &TypeError{Path: path, Expected: "number", Actual: describe(v)}
+		}
+		dst.SetFloat(v.Float())
+		return nil
+
+	case reflect.String:
+		if v.Type() != js.TypeString {
+			return &TypeError{Path: path, Expected: "string", Actual: describe(v)}
+		}
+		dst.SetString(v.String())
+		return nil
+
+	case reflect.Interface:
+		if t.NumMethod() != 0 {
+			break
+		}
+		dst.Set(reflect.ValueOf(v))
+		return nil
+
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			if !v.InstanceOf(js.Global().Get("Uint8Array")) {
+				return &TypeError{Path: path, Expected: "Uint8Array", Actual: describe(v)}
+			}
+			b := reflect.MakeSlice(t, v.Length(), v.Length())
+			js.CopyBytesToGo(b.Bytes(), v)
+			dst.Set(b)
+			return nil
+		}
+		if !js.Global().Get("Array").Call("isArray", v).Bool() {
+			return &TypeError{Path: path, Expected: "Array", Actual: describe(v)}
+		}
+		l := v.Length()
+		a := reflect.MakeSlice(t, l, l)
+		for i := range l {
+			if err := unmarshal(fmt.Sprintf("%s[%d]", path, i), v.Index(i), a.Index(i)); err != nil {
+				return err
+			}
+		}
+		dst.Set(a)
+		return nil
+
+	case reflect.Map:
+		if v.Type() != js.TypeObject {
+			return &TypeError{Path: path, Expected: "object", Actual: describe(v)}
+		}
+		m := reflect.MakeMap(t)
+		ks := js.Global().Get("Object").Call("keys", v)
+		for i := range ks.Length() {
+			k := ks.Index(i).String()
+			key := reflect.New(t.Key()).Elem()
+			if err := mapKeyFromString(path, k, key); err != nil {
+				return err
+			}
+			u := reflect.New(t.Elem()).Elem()
+			if err := unmarshal(fmt.Sprintf("%s[%q]", path, k), v.Get(k), u); err != nil {
+				return err
+			}
+			m.SetMapIndex(key, u)
+		}
+		dst.Set(m)
+		return nil
+
+	case reflect.Struct:
+		if v.Type() != js.TypeObject {
+			return &TypeError{Path: path, Expected: "object", Actual: describe(v)}
+		}
+		for _, f := range fieldsOf(t) {
+			u := v.Get(f.name)
+			if f.required && isNullish(u) {
+				return &TypeError{Path: path + "." + f.name, Expected: "required value", Actual: describe(u)}
+			}
+			if err := unmarshal(path+"."+f.name, u, dst.FieldByIndex(f.index)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return &TypeError{Path: path, Expected: "value representable in JS", Actual: t.String()}
+}
+
+func integerOf(path string, v js.Value) (float64, error) {
+	if v.Type() != js.TypeNumber {
+		return 0, &TypeError{Path: path, Expected: "number", Actual: describe(v)}
+	}
+	f := v.Float()
+	if f != math.Trunc(f) {
+		return 0, &TypeError{Path: path, Expected: "integer", Actual: "number " + v.String()}
+	}
+	return f, nil
+}
+
+func mapKeyFromString(path string, k string, dst reflect.Value) error {
+	if reflect.PointerTo(dst.Type()).Implements(textUnmarshType) {
+		if err := dst.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(k)); err != nil {
+			return fmt.Errorf("jz: %s[%q]: %w", path, k, err)
+		}
+		return nil
+	}
+
+	switch dst.Kind() {
+	case reflect.String:
+		dst.SetString(k)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(k, 10, dst.Type().Bits())
+		if err != nil {
+			return &TypeError{Path: fmt.Sprintf("%s[%q]", path, k), Expected: "integer key", Actual: "string"}
+		}
+		dst.SetInt(n)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(k, 10, dst.Type().Bits())
+		if err != nil {
+			return &TypeError{Path: fmt.Sprintf("%s[%q]", path, k), Expected: "unsigned integer key", Actual: "string"}
+		}
+		dst.SetUint(n)
+		return nil
+	}
+
+	return &TypeError{Path: path, Expected: "map key of string, integer or encoding.TextUnmarshaler", Actual: dst.Type().String()}
+}
+
+type field struct {
+	name  string
+	index []int
+
+	omitempty bool
+	required  bool
+}
+
+func fieldsOf(t reflect.Type) []field {
+	fs := []field{}
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() || f.Anonymous {
+			continue
+		}
+
+		tag := f.Tag.Get("js")
+		if tag == "-" {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = f.Name
+		}
+
+		v := field{name: name, index: f.Index}
+		for _, opt := range strings.Split(opts, ",") {
+			switch opt {
+			case "omitempty":
+				v.omitempty = true
+			case "required":
+				v.required = true
+			}
+		}
+		fs = append(fs, v)
+	}
+	return fs
+}
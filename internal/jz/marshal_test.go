@@ -0,0 +1,188 @@
+package jz_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lesomnus/grpc-wasm/internal/js"
+	"github.com/lesomnus/grpc-wasm/internal/jz"
+	"github.com/stretchr/testify/require"
+)
+
+type marshalInner struct {
+	Values []string `js:"values"`
+}
+
+type marshalOuter struct {
+	Name     string                  `js:"name,required"`
+	Count    int                     `js:"count"`
+	Ratio    float64                 `js:"ratio,omitempty"`
+	Enabled  *bool                   `js:"enabled,omitempty"`
+	Data     []byte                  `js:"data,omitempty"`
+	Inner    marshalInner            `js:"inner"`
+	Entries  map[string]marshalInner `js:"entries,omitempty"`
+	Date     time.Time               `js:"date,omitempty"`
+	Raw      js.Value                `js:"raw,omitempty"`
+	Untagged string
+	Skipped  string `js:"-"`
+}
+
+func TestMarshal(t *testing.T) {
+	t.Run("primitives", func(t *testing.T) {
+		for _, v := range []any{true, 42, int8(-1), uint64(7), 3.5, "foo"} {
+			u, err := jz.Marshal(v)
+			require.NoError(t, err)
+			require.Equal(t, js.ValueOf(v).String(), u.String())
+		}
+	})
+	t.Run("nil", func(t *testing.T) {
+		v, err := jz.Marshal(nil)
+		require.NoError(t, err)
+		require.True(t, v.IsNull())
+
+		var p *marshalOuter
+		v, err = jz.Marshal(p)
+		require.NoError(t, err)
+		require.True(t, v.IsNull())
+	})
+	t.Run("struct", func(t *testing.T) {
+		x := require.New(t)
+
+		enabled := true
+		v, err := jz.Marshal(marshalOuter{
+			Name:    "Walter",
+			Count:   3,
+			Enabled: &enabled,
+			Data:    []byte{1, 2, 3},
+			Inner:   marshalInner{Values: []string{"a", "b"}},
+			Entries: map[string]marshalInner{
+				"foo": {Values: []string{"bar"}},
+			},
+			Date:     time.UnixMilli(1234),
+			Untagged: "baz",
+			Skipped:  "qux",
+		})
+		x.NoError(err)
+		x.Equal("Walter", v.Get("name").String())
+		x.Equal(3, v.Get("count").Int())
+		x.True(v.Get("ratio").IsUndefined())
+		x.True(v.Get("enabled").Bool())
+		x.True(v.Get("data").InstanceOf(js.Global().Get("Uint8Array")))
+		x.Equal([]byte{1, 2, 3}, jz.BytesToGo(v.Get("data")))
+		x.Equal(2, v.Get("inner").Get("values").Length())
+		x.Equal("b", v.Get("inner").Get("values").Index(1).String())
+		x.Equal("bar", v.Get("entries").Get("foo").Get("values").Index(0).String())
+		x.True(v.Get("date").InstanceOf(js.Global().Get("Date")))
+		x.Equal(1234, v.Get("date").Call("getTime").Int())
+		x.True(v.Get("raw").IsUndefined())
+		x.Equal("baz", v.Get("Untagged").String())
+		x.True(v.Get("Skipped").IsUndefined())
+	})
+	t.Run("map with integer keys", func(t *testing.T) {
+		v, err := jz.Marshal(map[int]string{42: "foo"})
+		require.NoError(t, err)
+		require.Equal(t, "foo", v.Get("42").String())
+	})
+	t.Run("unsupported type names the path", func(t *testing.T) {
+		_, err := jz.Marshal(map[string]any{"foo": []any{make(chan int)}})
+		require.ErrorContains(t, err, `["foo"][0]`)
+	})
+}
+
+func TestUnmarshal(t *testing.T) {
+	t.Run("struct", func(t *testing.T) {
+		x := require.New(t)
+
+		date := js.Global().Get("Date").New(1234)
+		v := js.ValueOf(map[string]any{
+			"name":    "Walter",
+			"count":   3,
+			"enabled": false,
+			"data":    jz.BytesToJs([]byte{1, 2, 3}),
+			"inner":   map[string]any{"values": []any{"a", "b"}},
+			"entries": map[string]any{
+				"foo": map[string]any{"values": []any{"bar"}},
+			},
+			"date":     date,
+			"raw":      42,
+			"Untagged": "baz",
+			"Skipped":  "qux",
+		})
+
+		u := marshalOuter{}
+		err := jz.Unmarshal(v, &u)
+		x.NoError(err)
+		x.Equal("Walter", u.Name)
+		x.Equal(3, u.Count)
+		x.NotNil(u.Enabled)
+		x.False(*u.Enabled)
+		x.Equal([]byte{1, 2, 3}, u.Data)
+		x.Equal([]string{"a", "b"}, u.Inner.Values)
+		x.Equal([]string{"bar"}, u.Entries["foo"].Values)
+		x.Equal(int64(1234), u.Date.UnixMilli())
+		x.Equal(42, u.Raw.Int())
+		x.Equal("baz", u.Untagged)
+		x.Empty(u.Skipped)
+	})
+	t.Run("optional fields", func(t *testing.T) {
+		x := require.New(t)
+
+		u := marshalOuter{Count: 42}
+		err := jz.Unmarshal(js.ValueOf(map[string]any{"name": "Walter"}), &u)
+		x.NoError(err)
+		x.Equal(42, u.Count)
+		x.Nil(u.Enabled)
+		x.Nil(u.Data)
+	})
+	t.Run("required field", func(t *testing.T) {
+		u := marshalOuter{}
+		err := jz.Unmarshal(js.ValueOf(map[string]any{}), &u)
+		require.ErrorContains(t, err, ".name")
+	})
+	t.Run("type error names the path", func(t *testing.T) {
+		x := require.New(t)
+
+		u := marshalOuter{}
+		err := jz.Unmarshal(js.ValueOf(map[string]any{
+			"name": "Walter",
+			"entries": map[string]any{
+				"foo": map[string]any{"values": []any{"bar", 42}},
+			},
+		}), &u)
+
+		var type_err *jz.TypeError
+		x.ErrorAs(err, &type_err)
+		x.Equal(`.entries["foo"].values[1]`, type_err.Path)
+		x.Equal("string", type_err.Expected)
+		x.Equal("number", type_err.Actual)
+	})
+	t.Run("bytes from non Uint8Array", func(t *testing.T) {
+		var b []byte
+		err := jz.Unmarshal(js.ValueOf("foo"), &b)
+		require.ErrorContains(t, err, "Uint8Array")
+	})
+	t.Run("integer", func(t *testing.T) {
+		var n uint8
+		require.ErrorContains(t, jz.Unmarshal(js.ValueOf(1.5), &n), "integer")
+		require.ErrorContains(t, jz.Unmarshal(js.ValueOf(256), &n), "uint8")
+		require.ErrorContains(t, jz.Unmarshal(js.ValueOf(-1), &n), "uint8")
+		require.NoError(t, jz.Unmarshal(js.ValueOf(255), &n))
+		require.Equal(t, uint8(255), n)
+	})
+	t.Run("destination must be pointer", func(t *testing.T) {
+		var n int
+		require.Error(t, jz.Unmarshal(js.ValueOf(1), n))
+	})
+	t.Run("round trip", func(t *testing.T) {
+		x := require.New(t)
+
+		in := map[string][]string{"foo": {"bar", "baz"}}
+		v, err := jz.Marshal(in)
+		x.NoError(err)
+
+		out := map[string][]string{}
+		err = jz.Unmarshal(v, &out)
+		x.NoError(err)
+		x.Equal(in, out)
+	})
+}
@@ -0,0 +1,122 @@
+package jz
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/lesomnus/grpc-wasm/internal/js"
+)
+
+var liveFuncs atomic.Int64
+
+// LiveFuncs returns the number of Go functions held by JS objects made by [Scope.Object]
+// that are not released yet.
+func LiveFuncs() int64 {
+	return liveFuncs.Load()
+}
+
+// Object is a JS object whose methods are implemented by Go functions.
+// It owns the functions and releases them at once by [Object.Release].
+// Once released, its methods reject with an error instead of calling into released functions,
+// except the method named "close" which resolves so closing twice is harmless
+// and the methods given a value by [Object.Fallback] which resolve with it.
+type Object struct {
+	scope *Scope
+	value js.Value
+	// Holds the functions called by the methods of the value.
+	// It is emptied before the functions are released.
+	box js.Value
+	// Values the methods resolve with once released.
+	fallbacks js.Value
+
+	mu       sync.Mutex
+	names    []string
+	funcs    []js.Func
+	released bool
+
+	// Unregisters the value from the finalization registry.
+	unregister func()
+}
+
+// Object returns an empty object whose methods are tracked by the scope.
+func (s *Scope) Object() *Object {
+	return &Object{
+		scope: s,
+		value: js.Global().Get("Object").New(),
+		box:   js.Global().Get("Object").New(),
+
+		fallbacks: js.Global().Get("Object").New(),
+	}
+}
+
+// Value returns the JS object.
+func (o *Object) Value() js.Value {
+	return o.value
+}
+
+// Set sets a property of the object.
+func (o *Object) Set(name string, v any) *Object {
+	o.value.Set(name, v)
+	return o
+}
+
+// Method sets a method of the object implemented by given function.
+func (o *Object) Method(name string, f func(this js.Value, args []js.Value) any) *Object {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.released {
+		panic("jz: method added to released object")
+	}
+
+	fn := o.scope.FuncOf(f)
+	o.names = append(o.names, name)
+	o.funcs = append(o.funcs, fn)
+	liveFuncs.Add(1)
+
+	o.box.Set(name, fn)
+	o.value.Set(name, guardedCall.Invoke(o.box, name, o.fallbacks))
+	return o
+}
+
+// Fallback sets the value the method of given name resolves with once the object is released,
+// e.g. a result telling the object is closed.
+func (o *Object) Fallback(name string, v js.Value) *Object {
+	o.fallbacks.Set(name, v)
+	return o
+}
+
+// OnCollected calls given function if the JS object is garbage collected
+// before it is released.
+func (o *Object) OnCollected(f func()) *Object {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.unregister = onCollected(o.value, f)
+	return o
+}
+
+// Release releases the functions of the object.
+// It is safe to call it multiple times and from any of its methods.
+func (o *Object) Release() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.released {
+		return
+	}
+	o.released = true
+
+	if o.unregister != nil {
+		o.unregister()
+	}
+	for _, name := range o.names {
+		o.box.Delete(name)
+	}
+	for _, fn := range o.funcs {
+		fn.Release()
+	}
+	liveFuncs.Add(-int64(len(o.funcs)))
+	o.names = nil
+	o.funcs = nil
+}
@@ -0,0 +1,71 @@
+package jz_test
+
+import (
+	"testing"
+
+	"github.com/lesomnus/grpc-wasm/internal/js"
+	"github.com/lesomnus/grpc-wasm/internal/jz"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObject(t *testing.T) {
+	t.Run("methods", func(t *testing.T) {
+		x := require.New(t)
+
+		o := jz.NewScope().Object().
+			Set("answer", 42).
+			Method("add", func(this js.Value, args []js.Value) any {
+				return args[0].Int() + args[1].Int()
+			})
+		defer o.Release()
+
+		v := o.Value()
+		x.Equal(42, v.Get("answer").Int())
+		x.Equal(3, v.Call("add", 1, 2).Int())
+	})
+	t.Run("release", func(t *testing.T) {
+		x := require.New(t)
+
+		before := jz.LiveFuncs()
+		o := jz.NewScope().Object().
+			Method("foo", func(this js.Value, args []js.Value) any {
+				return jz.Resolve(js.ValueOf("foo"))
+			}).
+			Method("bar", func(this js.Value, args []js.Value) any {
+				return jz.Resolve(js.ValueOf("bar"))
+			}).
+			Method("close", func(this js.Value, args []js.Value) any {
+				return jz.Resolve(js.Undefined())
+			}).
+			Fallback("bar", js.ValueOf("closed"))
+		x.Equal(before+3, jz.LiveFuncs())
+
+		v := o.Value()
+		o.Release()
+		o.Release()
+		x.Equal(before, jz.LiveFuncs())
+
+		_, err_js := jz.Await(v.Call("foo"))
+		x.False(err_js.IsUndefined())
+		x.Contains(err_js.Get("message").String(), "foo: object is released")
+
+		v_bar, err_js := jz.Await(v.Call("bar"))
+		x.True(err_js.IsUndefined())
+		x.Equal("closed", v_bar.String())
+
+		_, err_js = jz.Await(v.Call("close"))
+		x.True(err_js.IsUndefined())
+	})
+	t.Run("release from its method", func(t *testing.T) {
+		x := require.New(t)
+
+		var o *jz.Object
+		o = jz.NewScope().Object().
+			Method("close", func(this js.Value, args []js.Value) any {
+				o.Release()
+				return js.ValueOf(42)
+			})
+
+		x.Equal(42, o.Value().Call("close").Int())
+	})
+}
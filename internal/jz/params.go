@@ -0,0 +1,122 @@
+package jz
+
+import (
+	"fmt"
+
+	"github.com/lesomnus/grpc-wasm/internal/js"
+)
+
+// Kind is a kind of JS value accepted as an argument.
+type Kind int
+
+const (
+	KindAny Kind = iota
+	KindBoolean
+	KindNumber
+	KindString
+	// Non-null object including arrays and functions.
+	KindObject
+	KindFunction
+	KindUint8Array
+	KindArray
+	// Object with callable "then".
+	KindThenable
+	KindAbortSignal
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindAny:
+		return "any"
+	case KindBoolean:
+		return "boolean"
+	case KindNumber:
+		return "number"
+	case KindString:
+		return "string"
+	case KindObject:
+		return "object"
+	case KindFunction:
+		return "function"
+	case KindUint8Array:
+		return "Uint8Array"
+	case KindArray:
+		return "Array"
+	case KindThenable:
+		return "Promise"
+	case KindAbortSignal:
+		return "AbortSignal"
+	}
+	return fmt.Sprintf("Kind(%d)", int(k))
+}
+
+func (k Kind) match(v js.Value) bool {
+	switch k {
+	case KindAny:
+		return true
+	case KindBoolean:
+		return v.Type() == js.TypeBoolean
+	case KindNumber:
+		return v.Type() == js.TypeNumber
+	case KindString:
+		return v.Type() == js.TypeString
+	case KindObject:
+		return v.Type() == js.TypeObject || v.Type() == js.TypeFunction
+	case KindFunction:
+		return v.Type() == js.TypeFunction
+	case KindUint8Array:
+		return v.InstanceOf(js.Global().Get("Uint8Array"))
+	case KindArray:
+		return js.Global().Get("Array").Call("isArray", v).Bool()
+	case KindThenable:
+		return v.Type() == js.TypeObject && v.Get("then").Type() == js.TypeFunction
+	case KindAbortSignal:
+		ctor := js.Global().Get("AbortSignal")
+		return ctor.Type() == js.TypeFunction && v.InstanceOf(ctor)
+	}
+	return false
+}
+
+// Param describes a parameter of a function exported to JS.
+type Param struct {
+	Name string
+	Kind Kind
+	// Optional parameter accepts undefined and null.
+	Optional bool
+}
+
+// Params is a signature of a function exported to JS.
+// Check the arguments against it before touching them,
+// so wrong arguments are reported as errors of kind "argument" instead of panics in syscall/js.
+//
+//	var fooParams = jz.Params{
+//		{Name: "name", Kind: jz.KindString},
+//		{Name: "option", Kind: jz.KindObject, Optional: true},
+//	}
+type Params []Param
+
+// Check returns [TypeError] if the arguments do not match the parameters.
+// Extra arguments are ignored as JS does.
+func (ps Params) Check(args []js.Value) error {
+	for i, p := range ps {
+		v := js.Undefined()
+		if i < len(args) {
+			v = args[i]
+		}
+		if p.Optional && isNullish(v) {
+			continue
+		}
+		if !p.Kind.match(v) {
+			return &TypeError{Path: p.Name, Expected: p.Kind.String(), Actual: describe(v)}
+		}
+	}
+	return nil
+}
+
+// Arg returns the i-th argument or undefined if it is not given.
+func Arg(args []js.Value, i int) js.Value {
+	if i < len(args) {
+		return args[i]
+	}
+	return js.Undefined()
+}
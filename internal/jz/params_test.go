@@ -0,0 +1,46 @@
+package jz_test
+
+import (
+	"testing"
+
+	"github.com/lesomnus/grpc-wasm/internal/js"
+	"github.com/lesomnus/grpc-wasm/internal/jz"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParamsCheck(t *testing.T) {
+	ps := jz.Params{
+		{Name: "name", Kind: jz.KindString},
+		{Name: "data", Kind: jz.KindUint8Array},
+		{Name: "option", Kind: jz.KindObject, Optional: true},
+	}
+	data := jz.BytesToJs([]byte{42})
+
+	t.Run("ok", func(t *testing.T) {
+		require.NoError(t, ps.Check([]js.Value{js.ValueOf("foo"), data}))
+		require.NoError(t, ps.Check([]js.Value{js.ValueOf("foo"), data, js.Null()}))
+		require.NoError(t, ps.Check([]js.Value{js.ValueOf("foo"), data, js.ValueOf(map[string]any{}), js.ValueOf(42)}))
+	})
+	t.Run("missing", func(t *testing.T) {
+		var err *jz.TypeError
+		require.ErrorAs(t, ps.Check([]js.Value{js.ValueOf("foo")}), &err)
+		require.Equal(t, "data", err.Path)
+		require.Equal(t, "undefined", err.Actual)
+	})
+	t.Run("wrong type", func(t *testing.T) {
+		var err *jz.TypeError
+		require.ErrorAs(t, ps.Check([]js.Value{js.ValueOf("foo"), js.ValueOf("bar")}), &err)
+		require.Equal(t, "data", err.Path)
+		require.Equal(t, "Uint8Array", err.Expected)
+		require.Equal(t, "string", err.Actual)
+
+		require.ErrorAs(t, ps.Check([]js.Value{js.ValueOf("foo"), data, js.ValueOf(42)}), &err)
+		require.Equal(t, "option", err.Path)
+	})
+	t.Run("JS error", func(t *testing.T) {
+		err := ps.Check(nil)
+		v := jz.ToError(err)
+		require.True(t, v.InstanceOf(js.Global().Get("TypeError")))
+		require.Contains(t, v.Get("message").String(), "name")
+	})
+}
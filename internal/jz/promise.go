@@ -1,8 +1,10 @@
-//go:build js && wasm
-
 package jz
 
-import "syscall/js"
+import (
+	"context"
+
+	"github.com/lesomnus/grpc-wasm/internal/js"
+)
 
 func Promise(f func() (js.Value, js.Value)) js.Value {
 	return globalScope.Promise(f)
@@ -12,6 +14,10 @@ func Await(p js.Value) (js.Value, js.Value) {
 	return globalScope.Await(p)
 }
 
+func AwaitContext(ctx context.Context, p js.Value) (js.Value, js.Value, error) {
+	return globalScope.AwaitContext(ctx, p)
+}
+
 func Resolve(v js.Value) js.Value {
 	return js.Global().Get("Promise").Call("resolve", v)
 }
@@ -1,11 +1,9 @@
-//go:build js && wasm
-
 package jz_test
 
 import (
-	"syscall/js"
 	"testing"
 
+	"github.com/lesomnus/grpc-wasm/internal/js"
 	"github.com/lesomnus/grpc-wasm/internal/jz"
 	"github.com/stretchr/testify/require"
 )
@@ -1,10 +1,14 @@
-//go:build js && wasm
-
 package jz
 
 import (
+	"context"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
 	"sync"
-	"syscall/js"
+
+	"github.com/lesomnus/grpc-wasm/internal/js"
 )
 
 var globalScope = NewScope()
@@ -13,35 +17,137 @@ func GlobalScope() *Scope {
 	return globalScope
 }
 
+// Scope tracks the tasks started from JS side, i.e. calls of the functions made by
+// [Scope.FuncOf], executors of [Scope.Promise], and [Scope.Await],
+// so the Go side can wait for them before it exits.
 type Scope struct {
-	wg sync.WaitGroup
+	mu    sync.Mutex
+	seq   uint64
+	tasks map[uint64]string
+	// Closed when the number of tasks drops to zero.
+	idle chan struct{}
 }
 
 func NewScope() *Scope {
-	return &Scope{}
+	return &Scope{
+		tasks: map[uint64]string{},
+	}
+}
+
+// begin registers a task with given description and returns a function
+// that must be called when the task is finished.
+func (s *Scope) begin(desc string) func() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.tasks) == 0 {
+		s.idle = make(chan struct{})
+	}
+
+	s.seq++
+	id := s.seq
+	s.tasks[id] = desc
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		delete(s.tasks, id)
+		if len(s.tasks) == 0 {
+			close(s.idle)
+		}
+	}
 }
 
 func (s *Scope) Wait() {
-	s.wg.Wait()
+	s.WaitContext(context.Background())
+}
+
+// WaitContext waits for the tasks in the scope to finish or the context to end.
+// It returns the descriptions of the tasks still outstanding when the context ends,
+// or nil if all tasks are finished.
+func (s *Scope) WaitContext(ctx context.Context) []string {
+	for {
+		s.mu.Lock()
+		if len(s.tasks) == 0 {
+			s.mu.Unlock()
+			return nil
+		}
+		idle := s.idle
+		s.mu.Unlock()
+
+		select {
+		case <-idle:
+		case <-ctx.Done():
+			return s.pending()
+		}
+	}
+}
+
+func (s *Scope) pending() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := make([]uint64, 0, len(s.tasks))
+	for id := range s.tasks {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	vs := make([]string, len(ids))
+	for i, id := range ids {
+		vs[i] = s.tasks[id]
+	}
+	return vs
+}
+
+// funcName returns the name of given function without its package path.
+func funcName(f any) string {
+	name := runtime.FuncForPC(reflect.ValueOf(f).Pointer()).Name()
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
 }
 
+// FuncOf returns a function that calls f.
+// If f panics, the function returns a promise rejected with GrpcWasmError
+// instead of crashing the program.
 func (s *Scope) FuncOf(f func(this js.Value, args []js.Value) any) js.Func {
-	return js.FuncOf(func(this js.Value, args []js.Value) any {
-		s.wg.Add(1)
-		defer s.wg.Done()
+	desc := funcName(f)
+	return js.FuncOf(func(this js.Value, args []js.Value) (v any) {
+		defer s.begin(desc)()
+		defer func() {
+			if r := recover(); r != nil {
+				v = Reject(fromPanic(r))
+			}
+		}()
 
 		return f(this, args)
 	})
 }
 
+// Promise returns a promise settled by f which runs in a new goroutine.
+// f returns a value to resolve with, or a reason to reject with if it is not undefined.
+// If f panics, the promise is rejected with GrpcWasmError instead of crashing the program.
 func (s *Scope) Promise(f func() (js.Value, js.Value)) js.Value {
-	return js.Global().Get("Promise").New(js.FuncOf(func(this js.Value, args []js.Value) any {
-		s.wg.Add(1)
+	desc := funcName(f)
+
+	var executor js.Func
+	executor = js.FuncOf(func(this js.Value, args []js.Value) any {
+		executor.Release()
+
+		end := s.begin(desc)
 		resolve := args[0]
 		reject := args[1]
 
 		go func() {
-			defer s.wg.Done()
+			defer end()
+			defer func() {
+				if r := recover(); r != nil {
+					reject.Invoke(fromPanic(r))
+				}
+			}()
 
 			v, err := f()
 			if !err.IsUndefined() {
@@ -51,31 +157,56 @@ func (s *Scope) Promise(f func() (js.Value, js.Value)) js.Value {
 			}
 		}()
 		return nil
-	}))
+	})
+	return js.Global().Get("Promise").New(executor)
 }
 
 func (s *Scope) Await(p js.Value) (js.Value, js.Value) {
-	s.wg.Add(1)
-	defer s.wg.Done()
-
-	c := make(chan js.Value)
-	caught := false
-	p.Call("then",
-		js.FuncOf(func(this js.Value, args []js.Value) any {
-			c <- args[0]
-			return js.Undefined()
-		}),
-		js.FuncOf(func(this js.Value, args []js.Value) any {
-			caught = true
-			c <- args[0]
-			return js.Undefined()
-		}),
-	)
-
-	v := <-c
-	if caught {
-		return js.Undefined(), v
-	} else {
-		return v, js.Undefined()
+	v, err, _ := s.AwaitContext(context.Background(), p)
+	return v, err
+}
+
+// AwaitContext waits for the promise to settle or the context to end.
+// It returns the resolved value or the rejection reason,
+// or the cause of the context if it ends first.
+// The callbacks attached to the promise are released in either case.
+func (s *Scope) AwaitContext(ctx context.Context, p js.Value) (js.Value, js.Value, error) {
+	defer s.begin("await")()
+
+	type result struct {
+		v      js.Value
+		caught bool
+	}
+
+	// Buffered so the callback never blocks even if no one receives.
+	c := make(chan result, 1)
+	on_resolve := js.FuncOf(func(this js.Value, args []js.Value) any {
+		c <- result{v: args[0]}
+		return js.Undefined()
+	})
+	on_reject := js.FuncOf(func(this js.Value, args []js.Value) any {
+		c <- result{v: args[0], caught: true}
+		return js.Undefined()
+	})
+	defer on_resolve.Release()
+	defer on_reject.Release()
+
+	// Callbacks attached to a promise cannot be detached,
+	// so they are called through a box which is emptied before they are released.
+	box := js.Global().Get("Object").New()
+	box.Set("f", on_resolve)
+	box.Set("g", on_reject)
+	defer box.Delete("g")
+	defer box.Delete("f")
+	p.Call("then", boxedCall.Invoke(box, "f"), boxedCall.Invoke(box, "g"))
+
+	select {
+	case r := <-c:
+		if r.caught {
+			return js.Undefined(), r.v, nil
+		}
+		return r.v, js.Undefined(), nil
+	case <-ctx.Done():
+		return js.Undefined(), js.Undefined(), context.Cause(ctx)
 	}
 }
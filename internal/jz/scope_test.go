@@ -0,0 +1,112 @@
+package jz_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lesomnus/grpc-wasm/internal/js"
+	"github.com/lesomnus/grpc-wasm/internal/jz"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScopeAwaitContext(t *testing.T) {
+	t.Run("settled", func(t *testing.T) {
+		s := jz.NewScope()
+		v, err_js, err := s.AwaitContext(t.Context(), jz.Resolve(js.ValueOf(42)))
+		require.NoError(t, err)
+		require.True(t, err_js.IsUndefined())
+		require.Equal(t, 42, v.Int())
+
+		v, err_js, err = s.AwaitContext(t.Context(), jz.Reject(js.ValueOf(42)))
+		require.NoError(t, err)
+		require.True(t, v.IsUndefined())
+		require.Equal(t, 42, err_js.Int())
+	})
+	t.Run("context ends first", func(t *testing.T) {
+		x := require.New(t)
+
+		s := jz.NewScope()
+		p, resolve := newPromise()
+
+		ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+		defer cancel()
+
+		_, _, err := s.AwaitContext(ctx, p)
+		x.ErrorIs(err, context.DeadlineExceeded)
+		x.Nil(s.WaitContext(t.Context()))
+
+		// The callbacks are released but settling the promise must not panic.
+		resolve.Invoke(42)
+		_, err_js := jz.Await(p)
+		x.True(err_js.IsUndefined())
+	})
+}
+
+func TestScopePanic(t *testing.T) {
+	t.Run("promise", func(t *testing.T) {
+		s := jz.NewScope()
+		p := s.Promise(func() (js.Value, js.Value) {
+			// Panics since undefined has no length.
+			js.Undefined().Length()
+			return js.Undefined(), js.Undefined()
+		})
+
+		_, err_js := jz.Await(p)
+		require.True(t, err_js.InstanceOf(js.Global().Get("TypeError")))
+		require.Contains(t, err_js.Get("message").String(), "panic")
+		require.Nil(t, s.WaitContext(t.Context()))
+	})
+	t.Run("func", func(t *testing.T) {
+		s := jz.NewScope()
+		f := s.FuncOf(func(this js.Value, args []js.Value) any {
+			panic("foo")
+		})
+		defer f.Release()
+
+		_, err_js := jz.Await(f.Invoke())
+		require.True(t, err_js.InstanceOf(js.Global().Get("TypeError")))
+		require.Contains(t, err_js.Get("message").String(), "foo")
+	})
+}
+
+func TestScopeWaitContext(t *testing.T) {
+	t.Run("no tasks", func(t *testing.T) {
+		s := jz.NewScope()
+		require.Nil(t, s.WaitContext(t.Context()))
+	})
+	t.Run("tasks finished", func(t *testing.T) {
+		s := jz.NewScope()
+
+		done := make(chan struct{})
+		p := s.Promise(func() (js.Value, js.Value) {
+			<-done
+			return js.Undefined(), js.Undefined()
+		})
+
+		time.AfterFunc(10*time.Millisecond, func() { close(done) })
+		require.Nil(t, s.WaitContext(t.Context()))
+
+		_, err_js := jz.Await(p)
+		require.True(t, err_js.IsUndefined())
+	})
+	t.Run("outstanding tasks", func(t *testing.T) {
+		x := require.New(t)
+
+		s := jz.NewScope()
+
+		done := make(chan struct{})
+		defer close(done)
+		s.Promise(func() (js.Value, js.Value) {
+			<-done
+			return js.Undefined(), js.Undefined()
+		})
+
+		ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+		defer cancel()
+
+		pending := s.WaitContext(ctx)
+		x.Len(pending, 1)
+		x.Contains(pending[0], "TestScopeWaitContext")
+	})
+}
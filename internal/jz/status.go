@@ -1,10 +1,7 @@
-//go:build js && wasm
-
 package jz
 
 import (
-	"syscall/js"
-
+	"github.com/lesomnus/grpc-wasm/internal/js"
 	"google.golang.org/grpc/status"
 )
 
@@ -1,8 +1,6 @@
-//go:build js && wasm
-
 package jz
 
-import "syscall/js"
+import "github.com/lesomnus/grpc-wasm/internal/js"
 
 func Stringify(v js.Value) string {
 	return js.Global().Get("JSON").Call("stringify", v).String()
@@ -0,0 +1,90 @@
+package kv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/lesomnus/grpc-wasm/internal/js"
+	"github.com/lesomnus/grpc-wasm/internal/jz"
+)
+
+var _ Backend = (*IndexedDB)(nil)
+
+// indexedDBStore is the name of the object store holding the entries.
+const indexedDBStore = "entries"
+
+// IndexedDB persists the entries in an IndexedDB database of the browser.
+// Each commit is an IndexedDB transaction.
+type IndexedDB struct {
+	db js.Value
+}
+
+// OpenIndexedDB opens the IndexedDB database of given name, creating it if it does not exist.
+func OpenIndexedDB(ctx context.Context, name string) (*IndexedDB, error) {
+	factory := js.Global().Get("indexedDB")
+	if !factory.Truthy() {
+		return nil, errors.New("IndexedDB is not available")
+	}
+
+	req := factory.Call("open", name, 1)
+	// The store must be created while the event is dispatched.
+	on_upgrade := js.FuncOf(func(this js.Value, args []js.Value) any {
+		req.Get("result").Call("createObjectStore", indexedDBStore)
+		return js.Undefined()
+	})
+	defer on_upgrade.Release()
+	req.Set("onupgradeneeded", on_upgrade)
+	defer req.Set("onupgradeneeded", js.Null())
+
+	if err := wait(ctx, req, "success", "error", "blocked"); err != nil {
+		return nil, fmt.Errorf("open IndexedDB %q: %w", name, err)
+	}
+	return &IndexedDB{db: req.Get("result")}, nil
+}
+
+func (b *IndexedDB) Load(ctx context.Context) ([]Entry, error) {
+	tx := b.db.Call("transaction", indexedDBStore, "readonly")
+	store := tx.Call("objectStore", indexedDBStore)
+	keys := store.Call("getAllKeys")
+	values := store.Call("getAll")
+	if err := wait(ctx, tx, "complete", "error", "abort"); err != nil {
+		return nil, err
+	}
+
+	ks := keys.Get("result")
+	vs := values.Get("result")
+	entries := make([]Entry, ks.Length())
+	for i := range entries {
+		entries[i] = Entry{
+			Key:   ks.Index(i).String(),
+			Value: jz.BytesToGo(vs.Index(i)),
+		}
+	}
+	return entries, nil
+}
+
+// Commit applies the changes in one transaction.
+// Once the transaction is started, it waits for the transaction regardless of the context,
+// so the store never misses what the database has.
+func (b *IndexedDB) Commit(ctx context.Context, changes []Change) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	tx := b.db.Call("transaction", indexedDBStore, "readwrite")
+	store := tx.Call("objectStore", indexedDBStore)
+	for _, c := range changes {
+		if c.Value == nil {
+			store.Call("delete", c.Key)
+		} else {
+			store.Call("put", jz.BytesToJs(c.Value), c.Key)
+		}
+	}
+	return wait(context.WithoutCancel(ctx), tx, "complete", "error", "abort")
+}
+
+func (b *IndexedDB) Close() error {
+	b.db.Call("close")
+	return nil
+}
@@ -0,0 +1,65 @@
+package kv
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lesomnus/grpc-wasm/internal/js"
+	"github.com/lesomnus/grpc-wasm/internal/jz"
+)
+
+// await waits for the promise, converting its rejection into Go error.
+func await(ctx context.Context, p js.Value) (js.Value, error) {
+	v, reason, err := jz.AwaitContext(ctx, p)
+	if err != nil {
+		return js.Undefined(), err
+	}
+	if !reason.IsUndefined() {
+		return js.Undefined(), jz.FromJs(reason)
+	}
+	return v, nil
+}
+
+// wait waits for given IndexedDB request or transaction to fire the event of done,
+// or one of the events of failed which ends with its error.
+// Every handler is detached once one of them is fired.
+func wait(ctx context.Context, target js.Value, done string, failed ...string) error {
+	events := append([]string{done}, failed...)
+	detach := func() {
+		for _, e := range events {
+			target.Set("on"+e, js.Null())
+		}
+	}
+
+	c := make(chan error, 1)
+	fs := make([]js.Func, len(events))
+	for i, e := range events {
+		fs[i] = js.FuncOf(func(this js.Value, args []js.Value) any {
+			detach()
+
+			var err error
+			if e != done {
+				err = fmt.Errorf("IndexedDB %s", e)
+				if v := target.Get("error"); v.Truthy() {
+					err = jz.FromJs(v)
+				}
+			}
+			c <- err
+			return js.Undefined()
+		})
+		target.Set("on"+e, fs[i])
+	}
+	defer func() {
+		for _, f := range fs {
+			f.Release()
+		}
+	}()
+
+	select {
+	case err := <-c:
+		return err
+	case <-ctx.Done():
+		detach()
+		return context.Cause(ctx)
+	}
+}
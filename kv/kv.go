@@ -0,0 +1,369 @@
+// Package kv is a key-value store for the services in the bridge,
+// persisted by a pluggable [Backend] such as IndexedDB or OPFS of the browser.
+//
+// The store holds every entry in memory and writes the changes through to the backend,
+// so reads never wait for the backend.
+package kv
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/lesomnus/grpc-wasm/internal/blob"
+)
+
+var (
+	ErrNotFound = errors.New("key not found")
+	ErrEmptyKey = errors.New("empty key")
+)
+
+// Entry is a key and its value.
+type Entry struct {
+	Key   string
+	Value []byte
+}
+
+// Change is a write committed to the backend.
+// Value is nil if the key is deleted.
+type Change struct {
+	Key   string
+	Value []byte
+}
+
+// Backend persists the entries of a [Store].
+type Backend interface {
+	// Load returns every entry stored.
+	Load(ctx context.Context) ([]Entry, error)
+	// Commit applies the changes all or nothing.
+	Commit(ctx context.Context, changes []Change) error
+	Close() error
+}
+
+// Range selects the keys to scan.
+// The zero value selects every key.
+type Range struct {
+	// Keys starting with it.
+	Prefix string
+	// Keys greater than or equal to it.
+	Start string
+	// Keys less than it. Empty means no upper bound.
+	End string
+	// Maximum number of entries. Zero means no limit.
+	Limit int
+}
+
+func (r Range) contains(key string) bool {
+	return strings.HasPrefix(key, r.Prefix) && key >= r.Start && (r.End == "" || key < r.End)
+}
+
+// Store is an ordered key-value store safe for concurrent use.
+// Writes are serialized; each [Store.Update] is committed to the backend at once.
+type Store struct {
+	backend Backend
+
+	// Held while a transaction is running, so transactions are serialized.
+	tx_mu sync.Mutex
+
+	mu     sync.RWMutex
+	keys   []string
+	values map[string][]byte
+}
+
+// Open loads the entries from given backend.
+func Open(ctx context.Context, b Backend) (*Store, error) {
+	entries, err := b.Load(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load: %w", err)
+	}
+
+	s := &Store{backend: b}
+	s.reset(entries)
+	return s, nil
+}
+
+// NewMemory returns an empty store that is not persisted, e.g. for tests.
+func NewMemory() *Store {
+	s, _ := Open(context.Background(), NewMemoryBackend())
+	return s
+}
+
+// Close closes the backend.
+func (s *Store) Close() error {
+	return s.backend.Close()
+}
+
+func (s *Store) reset(entries []Entry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keys = make([]string, 0, len(entries))
+	s.values = make(map[string][]byte, len(entries))
+	for _, e := range entries {
+		if _, ok := s.values[e.Key]; !ok {
+			s.keys = append(s.keys, e.Key)
+		}
+		s.values[e.Key] = e.Value
+	}
+	slices.Sort(s.keys)
+}
+
+// get returns the value of given key. The value must not be modified.
+func (s *Store) get(key string) ([]byte, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	v, ok := s.values[key]
+	return v, ok
+}
+
+// scan returns the entries in given range in the order of the keys,
+// until f returns false. The values must not be modified.
+func (s *Store) scan(r Range, f func(key string, value []byte) bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	i, _ := slices.BinarySearch(s.keys, max(r.Start, r.Prefix))
+	for _, k := range s.keys[i:] {
+		// Keys of the prefix are contiguous from where the scan starts.
+		if !r.contains(k) {
+			break
+		}
+		if !f(k, s.values[k]) {
+			break
+		}
+	}
+}
+
+func (s *Store) apply(changes []Change) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, c := range changes {
+		i, found := slices.BinarySearch(s.keys, c.Key)
+		switch {
+		case c.Value == nil && found:
+			s.keys = slices.Delete(s.keys, i, i+1)
+			delete(s.values, c.Key)
+		case c.Value != nil && !found:
+			s.keys = slices.Insert(s.keys, i, c.Key)
+			fallthrough
+		case c.Value != nil:
+			s.values[c.Key] = c.Value
+		}
+	}
+}
+
+// Get returns the value of given key, or [ErrNotFound].
+func (s *Store) Get(ctx context.Context, key string) ([]byte, error) {
+	v, ok := s.get(key)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return bytes.Clone(v), nil
+}
+
+// Scan returns the entries in given range in the order of the keys.
+func (s *Store) Scan(ctx context.Context, r Range) ([]Entry, error) {
+	entries := []Entry{}
+	s.scan(r, func(key string, value []byte) bool {
+		entries = append(entries, Entry{Key: key, Value: bytes.Clone(value)})
+		return r.Limit <= 0 || len(entries) < r.Limit
+	})
+	return entries, nil
+}
+
+func (s *Store) Put(ctx context.Context, key string, value []byte) error {
+	return s.Update(ctx, func(tx *Tx) error {
+		return tx.Put(key, value)
+	})
+}
+
+// Delete deletes given key. Deleting a key that does not exist is not an error.
+func (s *Store) Delete(ctx context.Context, key string) error {
+	return s.Update(ctx, func(tx *Tx) error {
+		return tx.Delete(key)
+	})
+}
+
+// Update runs f in a transaction.
+// The writes made by f are committed if f returns nil, and discarded otherwise.
+// Transactions are serialized, so what f reads is not changed by others until it returns.
+// f must not call the methods of the store that write, such as [Store.Put] and [Store.Delete],
+// as they wait for the transaction f is in and never return.
+func (s *Store) Update(ctx context.Context, f func(tx *Tx) error) error {
+	s.tx_mu.Lock()
+	defer s.tx_mu.Unlock()
+
+	tx := &Tx{s: s, writes: map[string][]byte{}}
+	if err := f(tx); err != nil {
+		return err
+	}
+	if len(tx.writes) == 0 {
+		return nil
+	}
+
+	changes := make([]Change, 0, len(tx.writes))
+	for k, v := range tx.writes {
+		changes = append(changes, Change{Key: k, Value: v})
+	}
+	slices.SortFunc(changes, func(a, b Change) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+	if err := s.backend.Commit(ctx, changes); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+
+	s.apply(changes)
+	return nil
+}
+
+// Tx is a transaction made by [Store.Update].
+// It reads its own writes. It must not be used after f returns.
+type Tx struct {
+	s *Store
+	// Nil value means deleted.
+	writes map[string][]byte
+}
+
+// Get returns the value of given key, or [ErrNotFound].
+func (tx *Tx) Get(key string) ([]byte, error) {
+	v, ok := tx.writes[key]
+	if !ok {
+		v, ok = tx.s.get(key)
+	}
+	if !ok || v == nil {
+		return nil, ErrNotFound
+	}
+	return bytes.Clone(v), nil
+}
+
+// Scan returns the entries in given range in the order of the keys.
+func (tx *Tx) Scan(r Range) ([]Entry, error) {
+	values := map[string][]byte{}
+	tx.s.scan(Range{Prefix: r.Prefix, Start: r.Start, End: r.End}, func(key string, value []byte) bool {
+		values[key] = value
+		return true
+	})
+	for k, v := range tx.writes {
+		if r.contains(k) {
+			values[k] = v
+		}
+	}
+
+	entries := []Entry{}
+	for k, v := range values {
+		if v != nil {
+			entries = append(entries, Entry{Key: k, Value: bytes.Clone(v)})
+		}
+	}
+	slices.SortFunc(entries, func(a, b Entry) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+	if r.Limit > 0 && len(entries) > r.Limit {
+		entries = entries[:r.Limit]
+	}
+	return entries, nil
+}
+
+func (tx *Tx) Put(key string, value []byte) error {
+	if key == "" {
+		return ErrEmptyKey
+	}
+	if value == nil {
+		value = []byte{}
+	}
+	tx.writes[key] = bytes.Clone(value)
+	return nil
+}
+
+// Delete deletes given key. Deleting a key that does not exist is not an error.
+func (tx *Tx) Delete(key string) error {
+	if key == "" {
+		return ErrEmptyKey
+	}
+	tx.writes[key] = nil
+	return nil
+}
+
+// DumpVersion is the version of the blob made by [Store.Snapshot].
+const DumpVersion = 1
+
+var dumpFormat = blob.Format{Magic: "GWKV", Version: DumpVersion, Name: "kv dump"}
+
+// Snapshot returns every entry as one versioned blob,
+// so the store can be registered to grpcwasm.Snapshots or exported to JS.
+//
+// The blob is:
+//
+//	"GWKV" | version (uvarint) | count (uvarint) | { key length (uvarint) | key | value length (uvarint) | value }...
+func (s *Store) Snapshot() ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries := make([]Entry, len(s.keys))
+	for i, k := range s.keys {
+		entries[i] = Entry{Key: k, Value: s.values[k]}
+	}
+	return encodeDump(entries), nil
+}
+
+// Restore replaces every entry with the ones in the blob made by [Store.Snapshot].
+func (s *Store) Restore(data []byte) error {
+	return s.Import(context.Background(), data)
+}
+
+// Import replaces every entry with the ones in the blob made by [Store.Snapshot],
+// committing it to the backend as one transaction.
+func (s *Store) Import(ctx context.Context, data []byte) error {
+	entries, err := parseDump(data)
+	if err != nil {
+		return err
+	}
+
+	return s.Update(ctx, func(tx *Tx) error {
+		for _, k := range s.allKeys() {
+			if err := tx.Delete(k); err != nil {
+				return err
+			}
+		}
+		for _, e := range entries {
+			if err := tx.Put(e.Key, e.Value); err != nil {
+				return fmt.Errorf("entry %q: %w", e.Key, err)
+			}
+		}
+		return nil
+	})
+}
+
+func (s *Store) allKeys() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return slices.Clone(s.keys)
+}
+
+func encodeDump(entries []Entry) []byte {
+	blobs := make([]blob.Entry, len(entries))
+	for i, e := range entries {
+		blobs[i] = blob.Entry{Name: e.Key, Data: e.Value}
+	}
+	return dumpFormat.Encode(blobs)
+}
+
+func parseDump(data []byte) ([]Entry, error) {
+	blobs, err := dumpFormat.Decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, len(blobs))
+	for i, b := range blobs {
+		entries[i] = Entry{Key: b.Name, Value: bytes.Clone(b.Data)}
+	}
+	return entries, nil
+}
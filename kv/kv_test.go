@@ -0,0 +1,176 @@
+package kv_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/lesomnus/grpc-wasm/kv"
+	"github.com/stretchr/testify/require"
+)
+
+// failingBackend fails every commit.
+type failingBackend struct {
+	*kv.MemoryBackend
+}
+
+func (failingBackend) Commit(ctx context.Context, changes []kv.Change) error {
+	return errors.New("disk full")
+}
+
+func keysOf(entries []kv.Entry) []string {
+	keys := []string{}
+	for _, e := range entries {
+		keys = append(keys, e.Key)
+	}
+	return keys
+}
+
+func TestStore(t *testing.T) {
+	t.Run("get put delete", func(t *testing.T) {
+		x := require.New(t)
+		ctx := t.Context()
+
+		s := kv.NewMemory()
+		_, err := s.Get(ctx, "foo")
+		x.ErrorIs(err, kv.ErrNotFound)
+
+		x.NoError(s.Put(ctx, "foo", []byte("bar")))
+		v, err := s.Get(ctx, "foo")
+		x.NoError(err)
+		x.Equal("bar", string(v))
+
+		// Values are copied.
+		v[0] = 'c'
+		v, err = s.Get(ctx, "foo")
+		x.NoError(err)
+		x.Equal("bar", string(v))
+
+		x.NoError(s.Delete(ctx, "foo"))
+		_, err = s.Get(ctx, "foo")
+		x.ErrorIs(err, kv.ErrNotFound)
+		x.NoError(s.Delete(ctx, "foo"))
+
+		x.ErrorIs(s.Put(ctx, "", nil), kv.ErrEmptyKey)
+	})
+	t.Run("scan", func(t *testing.T) {
+		x := require.New(t)
+		ctx := t.Context()
+
+		s := kv.NewMemory()
+		for _, k := range []string{"users/2", "posts/1", "users/1", "users/10", "usersx"} {
+			x.NoError(s.Put(ctx, k, []byte(k)))
+		}
+
+		entries, err := s.Scan(ctx, kv.Range{})
+		x.NoError(err)
+		x.Equal([]string{"posts/1", "users/1", "users/10", "users/2", "usersx"}, keysOf(entries))
+
+		entries, err = s.Scan(ctx, kv.Range{Prefix: "users/"})
+		x.NoError(err)
+		x.Equal([]string{"users/1", "users/10", "users/2"}, keysOf(entries))
+		x.Equal("users/1", string(entries[0].Value))
+
+		entries, err = s.Scan(ctx, kv.Range{Prefix: "users/", Start: "users/10", Limit: 1})
+		x.NoError(err)
+		x.Equal([]string{"users/10"}, keysOf(entries))
+
+		entries, err = s.Scan(ctx, kv.Range{Start: "p", End: "users/10"})
+		x.NoError(err)
+		x.Equal([]string{"posts/1", "users/1"}, keysOf(entries))
+	})
+	t.Run("transaction", func(t *testing.T) {
+		x := require.New(t)
+		ctx := t.Context()
+
+		s := kv.NewMemory()
+		x.NoError(s.Put(ctx, "a", []byte("1")))
+		x.NoError(s.Put(ctx, "b", []byte("2")))
+
+		err := s.Update(ctx, func(tx *kv.Tx) error {
+			x.NoError(tx.Put("c", []byte("3")))
+			x.NoError(tx.Delete("a"))
+
+			// Reads its own writes.
+			_, err := tx.Get("a")
+			x.ErrorIs(err, kv.ErrNotFound)
+			entries, err := tx.Scan(kv.Range{})
+			x.NoError(err)
+			x.Equal([]string{"b", "c"}, keysOf(entries))
+
+			// Not visible outside until committed.
+			_, err = s.Get(ctx, "c")
+			x.ErrorIs(err, kv.ErrNotFound)
+			return nil
+		})
+		x.NoError(err)
+
+		entries, err := s.Scan(ctx, kv.Range{})
+		x.NoError(err)
+		x.Equal([]string{"b", "c"}, keysOf(entries))
+
+		err = s.Update(ctx, func(tx *kv.Tx) error {
+			x.NoError(tx.Put("d", []byte("4")))
+			return errors.New("oops")
+		})
+		x.EqualError(err, "oops")
+		_, err = s.Get(ctx, "d")
+		x.ErrorIs(err, kv.ErrNotFound)
+	})
+	t.Run("backend", func(t *testing.T) {
+		x := require.New(t)
+		ctx := t.Context()
+
+		b := kv.NewMemoryBackend()
+		s, err := kv.Open(ctx, b)
+		x.NoError(err)
+		x.NoError(s.Put(ctx, "foo", []byte("bar")))
+		x.NoError(s.Close())
+
+		// Reloaded.
+		s, err = kv.Open(ctx, b)
+		x.NoError(err)
+		v, err := s.Get(ctx, "foo")
+		x.NoError(err)
+		x.Equal("bar", string(v))
+
+		// Failed commit changes nothing.
+		s, err = kv.Open(ctx, failingBackend{b})
+		x.NoError(err)
+		x.ErrorContains(s.Put(ctx, "foo", []byte("baz")), "disk full")
+		v, err = s.Get(ctx, "foo")
+		x.NoError(err)
+		x.Equal("bar", string(v))
+	})
+	t.Run("snapshot", func(t *testing.T) {
+		x := require.New(t)
+		ctx := t.Context()
+
+		s := kv.NewMemory()
+		x.NoError(s.Put(ctx, "foo", []byte("bar")))
+		x.NoError(s.Put(ctx, "empty", []byte{}))
+		data, err := s.Snapshot()
+		x.NoError(err)
+
+		x.NoError(s.Put(ctx, "baz", []byte("qux")))
+		x.NoError(s.Restore(data))
+
+		entries, err := s.Scan(ctx, kv.Range{})
+		x.NoError(err)
+		x.Equal([]kv.Entry{{Key: "empty", Value: []byte{}}, {Key: "foo", Value: []byte("bar")}}, entries)
+
+		x.ErrorContains(s.Restore([]byte("Lebowski")), "not a kv dump")
+		x.ErrorContains(s.Restore(data[:len(data)-1]), "malformed")
+
+		// One entry of an empty key.
+		x.ErrorIs(s.Restore([]byte("GWKV\x01\x01\x00\x01x")), kv.ErrEmptyKey)
+		entries, err = s.Scan(ctx, kv.Range{})
+		x.NoError(err)
+		x.Len(entries, 2)
+	})
+}
+
+func TestOpenIndexedDB(t *testing.T) {
+	_, err := kv.OpenIndexedDB(t.Context(), "test")
+	require.ErrorContains(t, err, "not available")
+}
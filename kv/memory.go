@@ -0,0 +1,51 @@
+package kv
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+)
+
+var _ Backend = (*MemoryBackend)(nil)
+
+// MemoryBackend keeps the entries in memory.
+// A store opened again with the same backend sees the entries committed before,
+// which is handy to test reloads.
+type MemoryBackend struct {
+	mu      sync.Mutex
+	entries map[string][]byte
+}
+
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{entries: map[string][]byte{}}
+}
+
+func (b *MemoryBackend) Load(ctx context.Context) ([]Entry, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	entries := make([]Entry, 0, len(b.entries))
+	for _, k := range slices.Sorted(maps.Keys(b.entries)) {
+		entries = append(entries, Entry{Key: k, Value: b.entries[k]})
+	}
+	return entries, nil
+}
+
+func (b *MemoryBackend) Commit(ctx context.Context, changes []Change) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, c := range changes {
+		if c.Value == nil {
+			delete(b.entries, c.Key)
+		} else {
+			b.entries[c.Key] = c.Value
+		}
+	}
+	return nil
+}
+
+func (b *MemoryBackend) Close() error {
+	return nil
+}
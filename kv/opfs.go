@@ -0,0 +1,110 @@
+package kv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/lesomnus/grpc-wasm/internal/js"
+	"github.com/lesomnus/grpc-wasm/internal/jz"
+)
+
+var _ Backend = (*OPFS)(nil)
+
+// OPFS persists the entries in a file of the origin private file system of the browser,
+// in the format of [Store.Snapshot].
+// Each commit rewrites the file, which the browser replaces at once when the write is closed.
+type OPFS struct {
+	handle js.Value
+
+	mu      sync.Mutex
+	entries map[string][]byte
+}
+
+// OpenOPFS opens the file of given name at the root of the origin private file system,
+// creating it if it does not exist.
+func OpenOPFS(ctx context.Context, name string) (*OPFS, error) {
+	navigator := js.Global().Get("navigator")
+	if !navigator.Truthy() || !navigator.Get("storage").Truthy() {
+		return nil, errors.New("OPFS is not available")
+	}
+
+	root, err := await(ctx, navigator.Get("storage").Call("getDirectory"))
+	if err != nil {
+		return nil, fmt.Errorf("open OPFS: %w", err)
+	}
+	handle, err := await(ctx, root.Call("getFileHandle", name, map[string]any{"create": true}))
+	if err != nil {
+		return nil, fmt.Errorf("open OPFS file %q: %w", name, err)
+	}
+	return &OPFS{handle: handle, entries: map[string][]byte{}}, nil
+}
+
+func (b *OPFS) Load(ctx context.Context) ([]Entry, error) {
+	file, err := await(ctx, b.handle.Call("getFile"))
+	if err != nil {
+		return nil, err
+	}
+	buf, err := await(ctx, file.Call("arrayBuffer"))
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []Entry{}
+	if data := jz.BytesToGo(js.Global().Get("Uint8Array").New(buf)); len(data) > 0 {
+		entries, err = parseDump(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.entries = map[string][]byte{}
+	for _, e := range entries {
+		b.entries[e.Key] = e.Value
+	}
+	return entries, nil
+}
+
+func (b *OPFS) Commit(ctx context.Context, changes []Change) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	next := maps.Clone(b.entries)
+	for _, c := range changes {
+		if c.Value == nil {
+			delete(next, c.Key)
+		} else {
+			next[c.Key] = c.Value
+		}
+	}
+
+	entries := make([]Entry, 0, len(next))
+	for _, k := range slices.Sorted(maps.Keys(next)) {
+		entries = append(entries, Entry{Key: k, Value: next[k]})
+	}
+
+	w, err := await(ctx, b.handle.Call("createWritable"))
+	if err != nil {
+		return err
+	}
+	if _, err := await(ctx, w.Call("write", jz.BytesToJs(encodeDump(entries)))); err != nil {
+		w.Call("abort")
+		return err
+	}
+	if _, err := await(context.WithoutCancel(ctx), w.Call("close")); err != nil {
+		return err
+	}
+
+	b.entries = next
+	return nil
+}
+
+func (b *OPFS) Close() error {
+	return nil
+}
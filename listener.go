@@ -1,16 +1,30 @@
-//go:build js && wasm
-
 package grpcwasm
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
+	"math/rand/v2"
 	"net"
-	"syscall/js"
+	"runtime"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/lesomnus/grpc-wasm/inspector"
+	"github.com/lesomnus/grpc-wasm/internal/js"
 	"github.com/lesomnus/grpc-wasm/internal/jz"
+	"github.com/lesomnus/grpc-wasm/kv"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/grpc/status"
 	"google.golang.org/grpc/test/bufconn"
 )
 
@@ -18,20 +32,104 @@ type Listener struct {
 	*bufconn.Listener
 
 	scope *jz.Scope
-	ctx   context.Context
+	// Cancelled by [Listener.Close], so the calls waiting for it end.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// Options applied to every connection made by [Listener.Dial].
+	dial_opts []grpc.DialOption
+	buf_size  int
+
+	// Set by [WithServerFactory].
+	servers *serverPool
+	// Set by [WithIsolatedServers].
+	isolated_servers bool
+
+	conn_seq atomic.Uint32
+	calls    *callRegistry
+	handles  *handleCounts
+
+	// Set once the listener is exported to JS.
+	obj      *jz.Object
+	obj_once sync.Once
+
+	metrics   *Metrics
+	inspector *inspector.Inspector
+	panics    panicHub
+	snapshots *Snapshots
+	files     *MemFS
+	kv_store  *kv.Store
+
+	// Registered by [Listener.JsIntercept].
+	interceptors jsInterceptors
+	// Registered by [Listener.JsHold].
+	holds holdRegistry
+
+	clock    Clock
+	rand_src *SeededSource
+	rand     *rand.Rand
+	// Location handlers get by [LocationFrom]; nil for [time.Local].
+	location atomic.Pointer[time.Location]
+
+	// How long [Serve] waits for the calls from JS side after the server stopped.
+	shutdown_timeout time.Duration
+
+	stream_idle_timeout time.Duration
+
+	// Restore the runtime settings changed by the options, in reverse order on [Listener.Close].
+	restores   []func()
+	close_once sync.Once
+}
+
+// handleCounts counts the objects exported to JS that are not released yet.
+type handleCounts struct {
+	conns       atomic.Int64
+	streams     atomic.Int64
+	inspections atomic.Int64
+	panics      atomic.Int64
+}
+
+// Handles is the number of live objects exported to JS.
+type Handles struct {
+	// Go functions held by JS.
+	Funcs int64 `js:"funcs"`
+
+	Conns       int64 `js:"conns"`
+	Streams     int64 `js:"streams"`
+	Inspections int64 `js:"inspections"`
+	Panics      int64 `js:"panics"`
 }
 
 func NewListener(opts ...ListenOption) *Listener {
 	l := &Listener{
 		scope: jz.NewScope(),
-		ctx:   context.Background(),
+
+		calls:   newCallRegistry(),
+		handles: &handleCounts{},
+
+		rand_src: NewSeededSource(rand.Uint64()),
+
+		// Default buffer size 1MB
+		buf_size: 1 << 20,
+
+		shutdown_timeout: 10 * time.Second,
 	}
 	for _, opt := range opts {
 		opt(l)
 	}
-	if l.Listener == nil {
-		// Default buffer size 1MB
-		l.Listener = bufconn.Listen(1 << 20)
+	if l.servers != nil {
+		l.servers.isolated = l.isolated_servers
+	}
+	l.ctx, l.cancel = context.WithCancel(context.Background())
+	l.Listener = bufconn.Listen(l.buf_size)
+	l.rand = rand.New(l.rand_src)
+	if l.clock == nil {
+		c := NewFakeClock(time.Now())
+		c.Resume()
+		l.clock = c
+	}
+	if l.kv_store == nil {
+		l.kv_store = kv.NewMemory()
 	}
 
 	return l
@@ -68,10 +166,99 @@ func (l *Listener) Addr() net.Addr {
 	return addr{}
 }
 
+func (l *Listener) Accept() (net.Conn, error) {
+	return l.accept(l.Listener)
+}
+
+// accept accepts a connection from given listener as the server side of the connection
+// dialed by l.
+func (l *Listener) accept(lis net.Listener) (net.Conn, error) {
+	c, err := lis.Accept()
+	if err != nil {
+		return nil, err
+	}
+
+	return serverConn{Conn: c, l: l}, nil
+}
+
+// Close closes the listener and releases the functions exported to JS.
+// Pending receives of the subscriptions resolve with done.
+func (l *Listener) Close() error {
+	l.cancel()
+	l.close_once.Do(func() {
+		for _, restore := range slices.Backward(l.restores) {
+			restore()
+		}
+	})
+	err := l.Listener.Close()
+	if l.obj != nil {
+		l.obj.Release()
+	}
+	return err
+}
+
+// Handles returns the number of live objects exported to JS.
+// It is useful to find leaks, e.g. streams that JS never closes.
+func (l *Listener) Handles() Handles {
+	return Handles{
+		Funcs: jz.LiveFuncs(),
+
+		Conns:       l.handles.conns.Load(),
+		Streams:     l.handles.streams.Load(),
+		Inspections: l.handles.inspections.Load(),
+		Panics:      l.handles.panics.Load(),
+	}
+}
+
+// Signature:
+//
+//	type Handles = {
+//		funcs: number
+//		conns: number
+//		streams: number
+//		inspections: number
+//		panics: number
+//	}
+//	function(): Promise<Handles>;
+func (l *Listener) JsHandles(this js.Value, args []js.Value) any {
+	v, err := jz.Marshal(l.Handles())
+	if err != nil {
+		return jz.Reject(jz.ToError(err))
+	}
+
+	return jz.Resolve(v)
+}
+
 func (l *Listener) Wait() {
 	l.scope.Wait()
 }
 
+// WaitContext waits for the calls from JS side to finish or the context to end.
+// It returns the descriptions of the calls still outstanding when the context ends.
+func (l *Listener) WaitContext(ctx context.Context) []string {
+	return l.scope.WaitContext(ctx)
+}
+
+// shutdownContext returns a context that ends after the shutdown timeout.
+func (l *Listener) shutdownContext() (context.Context, context.CancelFunc) {
+	if l.shutdown_timeout > 0 {
+		return context.WithTimeout(context.Background(), l.shutdown_timeout)
+	}
+	return context.WithCancel(context.Background())
+}
+
+// shutdown waits for the calls from JS side to finish up to the shutdown timeout.
+func (l *Listener) shutdown(err error) error {
+	ctx, cancel := l.shutdownContext()
+	defer cancel()
+
+	if pending := l.WaitContext(ctx); len(pending) > 0 {
+		err = errors.Join(err, fmt.Errorf("shutdown timed out with %d outstanding tasks: %s", len(pending), strings.Join(pending, ", ")))
+	}
+
+	return err
+}
+
 // Signature:
 //
 //	function();
@@ -80,25 +267,541 @@ func (l *Listener) JsClose(this js.Value, args []js.Value) any {
 	return nil
 }
 
+// Metrics returns metrics collector set by [WithMetrics] or nil if it is not enabled.
+func (l *Listener) Metrics() *Metrics {
+	return l.metrics
+}
+
+// Signature:
+//
+//	type Histogram = {
+//		buckets: { le: number; count: number }[]
+//		count: number
+//		sum: number
+//	}
+//	type MethodMetrics = {
+//		method: string
+//		type: "unary" | "client_stream" | "server_stream" | "bidi_stream"
+//		started: number
+//		handled: { [code: string]: number }
+//		in_flight: number
+//		msg_received: number
+//		msg_sent: number
+//		bytes_received: number
+//		bytes_sent: number
+//		latency: Histogram
+//	}
+//	type Metrics = {
+//		methods: MethodMetrics[]
+//	}
+//	function(): Promise<Metrics>;
+func (l *Listener) JsMetrics(this js.Value, args []js.Value) any {
+	if l.metrics == nil {
+		return jz.Reject(jz.Error("metrics are not enabled"))
+	}
+
+	v, err := metricsToJs(l.metrics.Snapshot())
+	if err != nil {
+		return jz.Reject(jz.ToError(err))
+	}
+
+	return jz.Resolve(v)
+}
+
+// JsMetricsText returns metrics in Prometheus text exposition format.
+//
+// Signature:
+//
+//	function(): Promise<string>;
+func (l *Listener) JsMetricsText(this js.Value, args []js.Value) any {
+	if l.metrics == nil {
+		return jz.Reject(jz.Error("metrics are not enabled"))
+	}
+
+	b := &strings.Builder{}
+	if err := l.metrics.WritePrometheus(b); err != nil {
+		return jz.Reject(jz.ToError(err))
+	}
+
+	return jz.Resolve(js.ValueOf(b.String()))
+}
+
+// Signature:
+//
+//	function(): Promise<void>;
+func (l *Listener) JsResetMetrics(this js.Value, args []js.Value) any {
+	if l.metrics == nil {
+		return jz.Reject(jz.Error("metrics are not enabled"))
+	}
+
+	l.metrics.Reset()
+	return jz.Resolve(js.Undefined())
+}
+
+// JsInspect subscribes the events of the calls made through the listener.
+// The first event is always a snapshot of the calls currently open.
+// Events are in the protobuf JSON mapping of `grpcwasm.inspector.Event`
+// with original field names.
+//
+// Signature:
+//
+//	type InspectResult =
+//		| {
+//			done: false
+//			event: InspectorEvent
+//		}
+//		| {
+//			done: true
+//		}
+//	type Inspection = {
+//		recv: ()=>Promise<InspectResult>
+//		close: ()=>Promise<void>
+//	}
+//	function(): Promise<Inspection>;
+func (l *Listener) JsInspect(this js.Value, args []js.Value) any {
+	if l.inspector == nil {
+		return jz.Reject(jz.Error("inspector is not enabled"))
+	}
+
+	sub := l.inspector.Subscribe()
+	var obj *jz.Object
+	release := sync.OnceFunc(func() {
+		sub.Close()
+		obj.Release()
+		l.handles.inspections.Add(-1)
+	})
+	obj = l.scope.Object().
+		Method("recv", func(this js.Value, args []js.Value) any {
+			return l.scope.Promise(func() (js.Value, js.Value) {
+				e, err := sub.Recv(l.ctx)
+				if err != nil {
+					if errors.Is(err, inspector.ErrClosed) || l.ctx.Err() != nil {
+						return js.ValueOf(map[string]any{"done": true}), js.Undefined()
+					}
+					return js.Undefined(), jz.ToError(err)
+				}
+
+				v, err := eventToJs(e)
+				if err != nil {
+					return js.Undefined(), jz.ToError(err)
+				}
+				return js.ValueOf(map[string]any{
+					"done":  false,
+					"event": v,
+				}), js.Undefined()
+			})
+		}).
+		Method("close", func(this js.Value, args []js.Value) any {
+			release()
+			return jz.Resolve(js.Undefined())
+		}).
+		OnCollected(release)
+	l.handles.inspections.Add(1)
+
+	return jz.Resolve(obj.Value())
+}
+
+// Panics subscribes the panics recovered in the handlers of the calls made through the listener.
+// Handlers are recovered only if the server is made by [NewServer] or
+// has [RecoveryUnaryServerInterceptor] and [RecoveryStreamServerInterceptor].
+// The returned function unsubscribes.
+func (l *Listener) Panics() (<-chan PanicEvent, func()) {
+	return l.panics.subscribe()
+}
+
+// JsPanics subscribes the panics recovered in the handlers. See [Listener.Panics].
+//
+// Signature:
+//
+//	type PanicEvent = {
+//		method: string
+//		value: string
+//		stack: string
+//		time: Date
+//	}
+//	type PanicResult =
+//		| {
+//			done: false
+//			event: PanicEvent
+//		}
+//		| {
+//			done: true
+//		}
+//	type PanicSubscription = {
+//		recv: ()=>Promise<PanicResult>
+//		close: ()=>Promise<void>
+//	}
+//	function(): Promise<PanicSubscription>;
+func (l *Listener) JsPanics(this js.Value, args []js.Value) any {
+	c, unsubscribe := l.Panics()
+	done := make(chan struct{})
+
+	var obj *jz.Object
+	release := sync.OnceFunc(func() {
+		unsubscribe()
+		close(done)
+		obj.Release()
+		l.handles.panics.Add(-1)
+	})
+	obj = l.scope.Object().
+		Method("recv", func(this js.Value, args []js.Value) any {
+			return l.scope.Promise(func() (js.Value, js.Value) {
+				type result struct {
+					Done  bool        `js:"done"`
+					Event *PanicEvent `js:"event,omitempty"`
+				}
+
+				var r result
+				select {
+				case <-done:
+					r.Done = true
+				case <-l.ctx.Done():
+					r.Done = true
+				case e := <-c:
+					r.Event = &e
+				}
+
+				v, err := jz.Marshal(r)
+				if err != nil {
+					return js.Undefined(), jz.ToError(err)
+				}
+				return v, js.Undefined()
+			})
+		}).
+		Method("close", func(this js.Value, args []js.Value) any {
+			release()
+			return jz.Resolve(js.Undefined())
+		}).
+		OnCollected(release)
+	l.handles.panics.Add(1)
+
+	return jz.Resolve(obj.Value())
+}
+
+// JsSnapshot captures the state of the services registered to the registry set by [WithSnapshots].
+//
+// Signature:
+//
+//	function(): Promise<Uint8Array>;
+func (l *Listener) JsSnapshot(this js.Value, args []js.Value) any {
+	if l.snapshots == nil {
+		return jz.Reject(jz.Error("snapshots are not enabled"))
+	}
+
+	return l.scope.Promise(func() (js.Value, js.Value) {
+		data, err := l.snapshots.Snapshot()
+		if err != nil {
+			return js.Undefined(), jz.ToError(err)
+		}
+
+		return jz.BytesToJs(data), js.Undefined()
+	})
+}
+
+var restoreParams = jz.Params{
+	{Name: "data", Kind: jz.KindUint8Array},
+}
+
+// JsRestore restores the services from the snapshot made by [Listener.JsSnapshot].
+//
+// Signature:
+//
+//	function(data: Uint8Array): Promise<void>;
+func (l *Listener) JsRestore(this js.Value, args []js.Value) any {
+	if err := restoreParams.Check(args); err != nil {
+		return jz.Reject(jz.ToError(err))
+	}
+	if l.snapshots == nil {
+		return jz.Reject(jz.Error("snapshots are not enabled"))
+	}
+
+	data := jz.BytesToGo(args[0])
+	return l.scope.Promise(func() (js.Value, js.Value) {
+		if err := l.snapshots.Restore(data); err != nil {
+			return js.Undefined(), jz.ToError(err)
+		}
+
+		return js.Undefined(), js.Undefined()
+	})
+}
+
+// ActiveCalls returns the calls in flight made through the listener.
+func (l *Listener) ActiveCalls() []CallInfo {
+	return l.calls.List()
+}
+
+// CancelCall cancels the call of given ID so the client sees given status.
+func (l *Listener) CancelCall(id uint32, s *status.Status) error {
+	return l.calls.Cancel(id, s)
+}
+
+// Signature:
+//
+//	type ActiveCall = {
+//		id: number
+//		conn_id: number
+//		method: string
+//		type: "unary" | "client_stream" | "server_stream" | "bidi_stream"
+//		meta: Metadata
+//		date_started: Date
+//		msg_sent: number
+//		msg_received: number
+//	}
+//	function(): Promise<ActiveCall[]>;
+func (l *Listener) JsActiveCalls(this js.Value, args []js.Value) any {
+	v, err := jz.Marshal(l.ActiveCalls())
+	if err != nil {
+		return jz.Reject(jz.ToError(err))
+	}
+
+	return jz.Resolve(v)
+}
+
+var cancelCallParams = jz.Params{
+	{Name: "id", Kind: jz.KindNumber},
+	{Name: "status", Kind: jz.KindObject, Optional: true},
+}
+
+// JsCancelCall cancels the call of given ID.
+// The call is cancelled with status CANCELLED if the status is not given.
+//
+// Signature:
+//
+//	function(id: number, status?: RpcStatus): Promise<void>;
+func (l *Listener) JsCancelCall(this js.Value, args []js.Value) any {
+	if err := cancelCallParams.Check(args); err != nil {
+		return jz.Reject(jz.ToError(err))
+	}
+
+	var id uint32
+	if err := jz.Unmarshal(args[0], &id); err != nil {
+		return jz.Reject(jz.ToError(err))
+	}
+
+	st := newRpcStatus(status.New(codes.Canceled, "cancelled by the bridge"))
+	if err := jz.Unmarshal(jz.Arg(args, 1), &st); err != nil {
+		return jz.Reject(jz.ToError(err))
+	}
+	if err := l.CancelCall(id, st.Status()); err != nil {
+		return jz.Reject(jz.ToError(err))
+	}
+
+	return jz.Resolve(js.Undefined())
+}
+
+var profileParams = jz.Params{
+	{Name: "name", Kind: jz.KindString},
+	{Name: "option", Kind: jz.KindObject, Optional: true},
+}
+
+// JsProfile returns the runtime/pprof profile of given name.
+// See [Profile] for the meaning of debug.
+//
+// Signature:
+//
+//	type ProfileName = "heap" | "allocs" | "goroutine" | "block" | "mutex" | "threadcreate"
+//	function(name: ProfileName, option?: { debug?: number }): Promise<Uint8Array>;
+func (l *Listener) JsProfile(this js.Value, args []js.Value) any {
+	if err := profileParams.Check(args); err != nil {
+		return jz.Reject(jz.ToError(err))
+	}
+
+	var (
+		name string
+		opt  struct {
+			Debug int `js:"debug"`
+		}
+	)
+	if err := jz.Unmarshal(args[0], &name); err != nil {
+		return jz.Reject(jz.ToError(err))
+	}
+	if err := jz.Unmarshal(jz.Arg(args, 1), &opt); err != nil {
+		return jz.Reject(jz.ToError(err))
+	}
+	debug := opt.Debug
+
+	return l.scope.Promise(func() (js.Value, js.Value) {
+		data, err := Profile(name, debug)
+		if err != nil {
+			return js.Undefined(), jz.ToError(err)
+		}
+
+		return jz.BytesToJs(data), js.Undefined()
+	})
+}
+
+var traceParams = jz.Params{
+	{Name: "duration_ms", Kind: jz.KindNumber},
+}
+
+// JsTrace captures runtime/trace execution trace for given duration.
+//
+// Signature:
+//
+//	function(duration_ms: number): Promise<Uint8Array>;
+func (l *Listener) JsTrace(this js.Value, args []js.Value) any {
+	if err := traceParams.Check(args); err != nil {
+		return jz.Reject(jz.ToError(err))
+	}
+
+	d := time.Duration(args[0].Float() * float64(time.Millisecond))
+	return l.scope.Promise(func() (js.Value, js.Value) {
+		data, err := Trace(l.ctx, d)
+		if err != nil {
+			return js.Undefined(), jz.ToError(err)
+		}
+
+		return jz.BytesToJs(data), js.Undefined()
+	})
+}
+
+var setMemoryParams = jz.Params{
+	{Name: "option", Kind: jz.KindObject},
+}
+
+// JsSetMemory sets the soft memory limit in bytes and GOGC.
+// Negative limit or undefined leaves the limit unchanged.
+// It resolves with the previous values.
+//
+// Signature:
+//
+//	type MemoryOption = {
+//		limit?: number
+//		gc_percent?: number
+//	}
+//	function(option: MemoryOption): Promise<MemoryOption>;
+func (l *Listener) JsSetMemory(this js.Value, args []js.Value) any {
+	if err := setMemoryParams.Check(args); err != nil {
+		return jz.Reject(jz.ToError(err))
+	}
+
+	// Default limit is math.MaxInt64 which cannot be represented exactly in JS,
+	// so the limit is read as float and clamped.
+	var opt struct {
+		Limit     *float64 `js:"limit"`
+		GCPercent *int     `js:"gc_percent"`
+	}
+	if err := jz.Unmarshal(args[0], &opt); err != nil {
+		return jz.Reject(jz.ToError(err))
+	}
+
+	limit := int64(-1)
+	if opt.Limit != nil {
+		// float64(math.MaxInt64) is 2^63, which overflows int64.
+		if *opt.Limit >= math.MaxInt64 {
+			limit = math.MaxInt64
+		} else {
+			limit = int64(*opt.Limit)
+		}
+	}
+	prev_limit := debug.SetMemoryLimit(limit)
+
+	prev_gc_percent := gcPercent()
+	if opt.GCPercent != nil {
+		prev_gc_percent = debug.SetGCPercent(*opt.GCPercent)
+	}
+
+	v, err := jz.Marshal(map[string]any{
+		"limit":      prev_limit,
+		"gc_percent": prev_gc_percent,
+	})
+	if err != nil {
+		return jz.Reject(jz.ToError(err))
+	}
+
+	return jz.Resolve(v)
+}
+
+// JsGC forces a garbage collection and returns as much memory to the runtime as possible.
+// Note that the linear memory of the WASM instance never shrinks.
+//
+// Signature:
+//
+//	function(): Promise<void>;
+func (l *Listener) JsGC(this js.Value, args []js.Value) any {
+	return l.scope.Promise(func() (js.Value, js.Value) {
+		debug.FreeOSMemory()
+		return js.Undefined(), js.Undefined()
+	})
+}
+
+// Signature:
+//
+//	type MemoryStats = {
+//		heap_live: number
+//		heap_objects: number
+//		total: number
+//		goroutines: number
+//		gc_cycles: number
+//		gc_pauses: { count: number; p50: number; p99: number; max: number }
+//		gc_percent: number
+//		memory_limit: number
+//	}
+//	function(): Promise<MemoryStats>;
+func (l *Listener) JsMemoryStats(this js.Value, args []js.Value) any {
+	v, err := jz.Marshal(ReadMemoryStats())
+	if err != nil {
+		return jz.Reject(jz.ToError(err))
+	}
+
+	return jz.Resolve(v)
+}
+
 func (l *Listener) Dial() (*Conn, error) {
+	dial := l.DialContext
+
+	// Connection gets its own server in isolation mode.
+	var slot *serverSlot
+	if l.servers != nil && l.servers.isolated {
+		s, err := l.servers.isolate()
+		if err != nil {
+			return nil, fmt.Errorf("make server: %w", err)
+		}
+
+		slot = s
+		dial = s.front.DialContext
+	}
+
 	opts := []grpc.DialOption{
 		grpc.WithDefaultCallOptions(grpc.ForceCodec(NoopCodec{})),
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
 		grpc.WithContextDialer(func(ctx context.Context, s string) (net.Conn, error) {
-			return l.DialContext(ctx)
+			return dial(ctx)
 		}),
 	}
+	opts = append(opts, l.dial_opts...)
+
+	var inspect *inspector.ConnHandler
+	if l.inspector != nil {
+		inspect = l.inspector.Dial()
+		opts = append(opts, grpc.WithStatsHandler(inspect))
+	}
 
 	conn, err := grpc.NewClient("passthrough://bufnet", opts...)
 	if err != nil {
+		if inspect != nil {
+			inspect.Close()
+		}
+		if slot != nil {
+			slot.close()
+		}
 		return nil, err
 	}
 
 	return &Conn{
 		ClientConn: conn,
 
+		id:      l.conn_seq.Add(1),
+		calls:   l.calls,
+		handles: l.handles,
+
 		scope: l.scope,
 		ctx:   l.ctx,
+
+		inspect: inspect,
+		server:  slot,
+
+		stream_idle_timeout: l.stream_idle_timeout,
 	}, nil
 }
 
@@ -114,18 +817,142 @@ func (l *Listener) JsDial(this js.Value, args []js.Value) any {
 	return jz.Resolve(conn.ToJs())
 }
 
+// ToJsValue exports the listener to JS.
+// Its functions are released when the listener is closed.
 func (l *Listener) ToJsValue() js.Value {
-	return js.ValueOf(map[string]any{
-		"close": l.scope.FuncOf(l.JsClose),
-		"dial":  l.scope.FuncOf(l.JsDial),
+	l.obj_once.Do(func() {
+		l.obj = l.scope.Object().
+			Method("close", l.JsClose).
+			Method("dial", l.JsDial).
+			Method("metrics", l.JsMetrics).
+			Method("metrics_text", l.JsMetricsText).
+			Method("reset_metrics", l.JsResetMetrics).
+			Method("inspect", l.JsInspect).
+			Method("active_calls", l.JsActiveCalls).
+			Method("cancel_call", l.JsCancelCall).
+			Method("profile", l.JsProfile).
+			Method("trace", l.JsTrace).
+			Method("set_memory", l.JsSetMemory).
+			Method("gc", l.JsGC).
+			Method("memory_stats", l.JsMemoryStats).
+			Method("handles", l.JsHandles).
+			Method("panics", l.JsPanics).
+			Method("reset", l.JsReset).
+			Method("snapshot", l.JsSnapshot).
+			Method("restore", l.JsRestore).
+			Method("clock_now", l.JsClockNow).
+			Method("clock_freeze", l.JsClockFreeze).
+			Method("clock_resume", l.JsClockResume).
+			Method("clock_set", l.JsClockSet).
+			Method("clock_advance", l.JsClockAdvance).
+			Method("set_seed", l.JsSetSeed).
+			Method("set_timezone", l.JsSetTimeZone).
+			Method("put_files", l.JsPutFiles).
+			Method("remove_files", l.JsRemoveFiles).
+			Method("kv_export", l.JsKVExport).
+			Method("kv_import", l.JsKVImport).
+			Method("intercept", l.JsIntercept).
+			Method("hold", l.JsHold)
 	})
+
+	return l.obj.Value()
 }
 
 type ListenOption func(l *Listener)
 
 func WithBufferSize(size int) ListenOption {
 	return func(l *Listener) {
-		l.Listener = bufconn.Listen(size)
+		l.buf_size = size
+	}
+}
+
+// WithStatsHandler installs given handler on every connection dialed by the listener,
+// so the handler sees every call the JS side makes.
+func WithStatsHandler(h stats.Handler) ListenOption {
+	return func(l *Listener) {
+		l.dial_opts = append(l.dial_opts, grpc.WithStatsHandler(h))
+	}
+}
+
+// WithMetrics enables metrics collection and exposes it to the JS side.
+func WithMetrics(m *Metrics) ListenOption {
+	return func(l *Listener) {
+		l.metrics = m
+		WithStatsHandler(m)(l)
+	}
+}
+
+// WithContentionProfiles enables block and mutex profiles with given rates.
+// See [runtime.SetBlockProfileRate] and [runtime.SetMutexProfileFraction].
+// Note that the rates are global to the program.
+func WithContentionProfiles(block_rate int, mutex_fraction int) ListenOption {
+	return func(l *Listener) {
+		runtime.SetBlockProfileRate(block_rate)
+		runtime.SetMutexProfileFraction(mutex_fraction)
+	}
+}
+
+// WithMemoryLimit sets the soft memory limit of the runtime in bytes until the listener is closed.
+// See [debug.SetMemoryLimit].
+func WithMemoryLimit(limit int64) ListenOption {
+	return func(l *Listener) {
+		prev := debug.SetMemoryLimit(limit)
+		l.restores = append(l.restores, func() { debug.SetMemoryLimit(prev) })
+	}
+}
+
+// WithGCPercent sets GOGC until the listener is closed. See [debug.SetGCPercent].
+func WithGCPercent(percent int) ListenOption {
+	return func(l *Listener) {
+		prev := debug.SetGCPercent(percent)
+		l.restores = append(l.restores, func() { debug.SetGCPercent(prev) })
+	}
+}
+
+// WithMemoryWatchdog rejects new calls with ResourceExhausted status
+// once heap objects grow over given threshold in bytes.
+// See [MemoryWatchdog].
+func WithMemoryWatchdog(threshold uint64) ListenOption {
+	return func(l *Listener) {
+		w := &MemoryWatchdog{Threshold: threshold}
+		l.dial_opts = append(l.dial_opts,
+			grpc.WithChainUnaryInterceptor(w.UnaryClientInterceptor()),
+			grpc.WithChainStreamInterceptor(w.StreamClientInterceptor()),
+		)
+	}
+}
+
+// WithInspector reports the calls made through the listener to given inspector
+// and exposes its events to the JS side.
+// Register the inspector on the server as well to watch the events through gRPC.
+func WithInspector(i *inspector.Inspector) ListenOption {
+	return func(l *Listener) {
+		l.inspector = i
+	}
+}
+
+// WithSnapshots exposes the snapshots of the services registered to given registry to the JS side.
+func WithSnapshots(r *Snapshots) ListenOption {
+	return func(l *Listener) {
+		l.snapshots = r
+	}
+}
+
+// WithShutdownTimeout sets how long [Serve] waits for the calls from JS side
+// to finish after the server stopped. Zero or negative waits forever.
+func WithShutdownTimeout(d time.Duration) ListenOption {
+	return func(l *Listener) {
+		l.shutdown_timeout = d
+	}
+}
+
+// WithStreamIdleTimeout cancels streams exported to JS and releases their functions
+// if JS does not call any of their functions for given duration
+// while none of the calls is in progress.
+// Zero disables it, which is the default.
+func WithStreamIdleTimeout(d time.Duration) ListenOption {
+	return func(l *Listener) {
+		l.stream_idle_timeout = d
 	}
 }
 
@@ -133,3 +960,33 @@ type addr struct{}
 
 func (addr) Network() string { return "grpcwasm" }
 func (addr) String() string  { return "grpcwasm" }
+
+// peerAddr is the address of the client seen by the server,
+// through which the server handlers reach the listener the call came from.
+type peerAddr struct {
+	addr
+	l *Listener
+}
+
+// listenerFrom returns the listener the call came from if it is a context of a handler,
+// or nil if the call did not come through [Listener].
+func listenerFrom(ctx context.Context) *Listener {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	a, ok := p.Addr.(peerAddr)
+	if !ok {
+		return nil
+	}
+	return a.l
+}
+
+type serverConn struct {
+	net.Conn
+	l *Listener
+}
+
+func (c serverConn) RemoteAddr() net.Addr {
+	return peerAddr{l: c.l}
+}
@@ -1,13 +1,20 @@
-//go:build js && wasm
-
 package grpcwasm_test
 
 import (
-	"syscall/js"
+	"context"
+	"math"
+	"runtime/debug"
 	"testing"
+	"time"
 
 	grpcwasm "github.com/lesomnus/grpc-wasm"
+	"github.com/lesomnus/grpc-wasm/inspector"
+	"github.com/lesomnus/grpc-wasm/internal/echo"
+	"github.com/lesomnus/grpc-wasm/internal/js"
+	"github.com/lesomnus/grpc-wasm/internal/jz"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 )
 
 var jsNoopFn = js.FuncOf(func(this js.Value, args []js.Value) any {
@@ -75,3 +82,366 @@ func TestJsDial(t *testing.T) {
 		require.True(v.InstanceOf(js.Global().Get("Promise")))
 	})
 }
+
+func TestListener_JsMetrics(t *testing.T) {
+	t.Run("rejects if metrics are not enabled", func(t *testing.T) {
+		l := grpcwasm.NewListener()
+		defer l.Close()
+
+		_, err_js := jz.Await(l.JsMetrics(js.Undefined(), nil).(js.Value))
+		require.False(t, err_js.IsUndefined())
+	})
+	t.Run("collects calls made through the listener", func(t *testing.T) {
+		x := require.New(t)
+
+		m := grpcwasm.NewMetrics()
+		l := grpcwasm.NewListener(grpcwasm.WithMetrics(m))
+		defer l.Close()
+
+		s := grpc.NewServer()
+		echo.RegisterEchoServiceServer(s, echo.EchoServer{})
+		go s.Serve(l)
+		defer s.Stop()
+
+		conn, err := l.Dial()
+		x.NoError(err)
+		defer conn.Close()
+
+		_, err_js := jsInvoke(x, conn, echo.EchoService_Once_FullMethodName, &echo.EchoRequest{}, nil)
+		x.True(err_js.IsUndefined())
+
+		v, err_js := jz.Await(l.JsMetrics(js.Undefined(), nil).(js.Value))
+		x.True(err_js.IsUndefined())
+		x.Equal(1, v.Get("methods").Length())
+		x.Equal(echo.EchoService_Once_FullMethodName, v.Get("methods").Index(0).Get("method").String())
+		x.Equal(1, v.Get("methods").Index(0).Get("handled").Get("OK").Int())
+
+		v, err_js = jz.Await(l.JsMetricsText(js.Undefined(), nil).(js.Value))
+		x.True(err_js.IsUndefined())
+		x.Contains(v.String(), "grpc_server_started_total")
+
+		_, err_js = jz.Await(l.JsResetMetrics(js.Undefined(), nil).(js.Value))
+		x.True(err_js.IsUndefined())
+		x.Empty(m.Snapshot())
+	})
+}
+
+func TestListener_JsInspect(t *testing.T) {
+	x := require.New(t)
+
+	l := grpcwasm.NewListener(grpcwasm.WithInspector(inspector.New()))
+	defer l.Close()
+
+	s := grpc.NewServer()
+	echo.RegisterEchoServiceServer(s, echo.EchoServer{})
+	go s.Serve(l)
+	defer s.Stop()
+
+	sub, err_js := jz.Await(l.JsInspect(js.Undefined(), nil).(js.Value))
+	x.True(err_js.IsUndefined())
+	defer sub.Call("close")
+
+	v, err_js := jz.Await(sub.Call("recv"))
+	x.True(err_js.IsUndefined())
+	x.False(v.Get("done").Bool())
+	x.Equal(js.TypeObject, v.Get("event").Get("snapshot").Type())
+
+	conn, err := l.Dial()
+	x.NoError(err)
+	defer conn.Close()
+
+	v, err_js = jz.Await(sub.Call("recv"))
+	x.True(err_js.IsUndefined())
+	x.Equal(js.TypeObject, v.Get("event").Get("conn_dialed").Type())
+
+	req := echo.EchoRequest{}
+	req.SetMessage("Lebowski")
+	_, err_js = jsInvoke(x, conn, echo.EchoService_Once_FullMethodName, &req, nil)
+	x.True(err_js.IsUndefined())
+
+	v, err_js = jz.Await(sub.Call("recv"))
+	x.True(err_js.IsUndefined())
+	x.Equal(echo.EchoService_Once_FullMethodName, v.Get("event").Get("call_started").Get("method").String())
+
+	for {
+		v, err_js = jz.Await(sub.Call("recv"))
+		x.True(err_js.IsUndefined())
+		if e := v.Get("event").Get("message_sent"); !e.IsUndefined() {
+			x.Equal("Lebowski", e.Get("decoded").Get("message").String())
+			break
+		}
+	}
+
+	// Drain the events so the next recv is pending.
+	for {
+		v, err_js = jz.Await(sub.Call("recv"))
+		x.True(err_js.IsUndefined())
+		if !v.Get("event").Get("call_finished").IsUndefined() {
+			break
+		}
+	}
+
+	// Pending recv is resolved with done once closed.
+	p := sub.Call("recv")
+	_, err_js = jz.Await(sub.Call("close"))
+	x.True(err_js.IsUndefined())
+
+	v, err_js = jz.Await(p)
+	x.True(err_js.IsUndefined())
+	x.True(v.Get("done").Bool())
+}
+
+func TestListener_Handles(t *testing.T) {
+	t.Run("released on close", withListener(func(ctx context.Context, x *require.Assertions, l *grpcwasm.Listener, conn *grpcwasm.Conn) {
+		before := l.Handles()
+
+		stream, err_js := jz.Await(conn.JsOpenBidiStream(js.Undefined(), []js.Value{
+			js.ValueOf(echo.EchoService_Live_FullMethodName),
+			js.ValueOf(map[string]any{}),
+		}).(js.Value))
+		x.True(err_js.IsUndefined())
+
+		v, err_js := jz.Await(l.JsHandles(js.Undefined(), nil).(js.Value))
+		x.True(err_js.IsUndefined())
+		x.Equal(int(before.Streams+1), v.Get("streams").Int())
+		x.Equal(int(before.Funcs+5), v.Get("funcs").Int())
+
+		_, err_js = jz.Await(stream.Call("close"))
+		x.True(err_js.IsUndefined())
+		x.Equal(before, l.Handles())
+	}))
+	t.Run("idle stream", withListener(func(ctx context.Context, x *require.Assertions, l *grpcwasm.Listener, conn *grpcwasm.Conn) {
+		before := l.Handles()
+
+		stream, err_js := jz.Await(conn.JsOpenBidiStream(js.Undefined(), []js.Value{
+			js.ValueOf(echo.EchoService_Live_FullMethodName),
+			js.ValueOf(map[string]any{}),
+		}).(js.Value))
+		x.True(err_js.IsUndefined())
+
+		// Pending recv keeps the stream alive.
+		p := stream.Call("recv")
+		time.Sleep(300 * time.Millisecond)
+		x.Equal(before.Streams+1, l.Handles().Streams)
+
+		req := echo.EchoRequest{}
+		in, err := protoMarshal(&req)
+		x.NoError(err)
+		_, err_js = jz.Await(stream.Call("send", in))
+		x.True(err_js.IsUndefined())
+		_, err_js = jz.Await(p)
+		x.True(err_js.IsUndefined())
+
+		x.Eventually(func() bool {
+			return l.Handles() == before
+		}, 2*time.Second, 10*time.Millisecond)
+		x.Empty(l.ActiveCalls())
+	}, grpcwasm.WithStreamIdleTimeout(200*time.Millisecond)))
+}
+
+func TestListener_JsPanics(t *testing.T) {
+	x := require.New(t)
+
+	l := grpcwasm.NewListener()
+	defer l.Close()
+
+	s := grpcwasm.NewServer()
+	echo.RegisterEchoServiceServer(s, panicEchoServer{})
+	go s.Serve(l)
+	defer s.Stop()
+
+	conn, err := l.Dial()
+	x.NoError(err)
+	defer conn.Close()
+
+	sub, err_js := jz.Await(l.JsPanics(js.Undefined(), nil).(js.Value))
+	x.True(err_js.IsUndefined())
+
+	v, err_js := jsInvoke(x, conn, echo.EchoService_Once_FullMethodName, &echo.EchoRequest{}, nil)
+	x.True(err_js.IsUndefined())
+	x.Equal(int(codes.Internal), v.Get("status").Get("code").Int())
+
+	v, err_js = jz.Await(sub.Call("recv"))
+	x.True(err_js.IsUndefined())
+	x.False(v.Get("done").Bool())
+	x.Equal(echo.EchoService_Once_FullMethodName, v.Get("event").Get("method").String())
+	x.Equal("handler panicked", v.Get("event").Get("value").String())
+	x.Contains(v.Get("event").Get("stack").String(), "panicEchoServer")
+
+	p := sub.Call("recv")
+	_, err_js = jz.Await(sub.Call("close"))
+	x.True(err_js.IsUndefined())
+
+	v, err_js = jz.Await(p)
+	x.True(err_js.IsUndefined())
+	x.True(v.Get("done").Bool())
+
+	// Pending receives end once the listener is closed.
+	sub, err_js = jz.Await(l.JsPanics(js.Undefined(), nil).(js.Value))
+	x.True(err_js.IsUndefined())
+	p = sub.Call("recv")
+	l.Close()
+
+	v, err_js = jz.Await(p)
+	x.True(err_js.IsUndefined())
+	x.True(v.Get("done").Bool())
+}
+
+func TestListener_WaitContext(t *testing.T) {
+	x := require.New(t)
+
+	l := grpcwasm.NewListener(grpcwasm.WithInspector(inspector.New()))
+	defer l.Close()
+
+	sub, err_js := jz.Await(l.JsInspect(js.Undefined(), nil).(js.Value))
+	x.True(err_js.IsUndefined())
+	defer sub.Call("close")
+
+	// Snapshot.
+	_, err_js = jz.Await(sub.Call("recv"))
+	x.True(err_js.IsUndefined())
+
+	// Never resolved since no event occurs.
+	sub.Call("recv")
+
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+
+	pending := l.WaitContext(ctx)
+	x.Len(pending, 1)
+	x.Contains(pending[0], "JsInspect")
+
+	// Pending recv ends once the listener is closed.
+	l.Close()
+	x.Empty(l.WaitContext(t.Context()))
+}
+
+func TestListener_JsProfile(t *testing.T) {
+	t.Run("heap", func(t *testing.T) {
+		l := grpcwasm.NewListener()
+		defer l.Close()
+
+		v, err_js := jz.Await(l.JsProfile(js.Undefined(), []js.Value{js.ValueOf("heap")}).(js.Value))
+		require.True(t, err_js.IsUndefined())
+		require.True(t, v.InstanceOf(js.Global().Get("Uint8Array")))
+		require.Equal(t, []byte{0x1f, 0x8b}, jz.BytesToGo(v)[:2])
+	})
+	t.Run("unknown", func(t *testing.T) {
+		l := grpcwasm.NewListener()
+		defer l.Close()
+
+		_, err_js := jz.Await(l.JsProfile(js.Undefined(), []js.Value{js.ValueOf("foo")}).(js.Value))
+		require.False(t, err_js.IsUndefined())
+	})
+}
+
+func TestListener_JsTrace(t *testing.T) {
+	l := grpcwasm.NewListener()
+	defer l.Close()
+
+	v, err_js := jz.Await(l.JsTrace(js.Undefined(), []js.Value{js.ValueOf(10)}).(js.Value))
+	require.True(t, err_js.IsUndefined())
+	require.True(t, v.InstanceOf(js.Global().Get("Uint8Array")))
+	require.NotZero(t, v.Length())
+}
+
+func TestListener_JsMemory(t *testing.T) {
+	t.Run("set memory", func(t *testing.T) {
+		x := require.New(t)
+
+		l := grpcwasm.NewListener()
+		defer l.Close()
+
+		v, err_js := jz.Await(l.JsSetMemory(js.Undefined(), []js.Value{js.ValueOf(map[string]any{
+			"limit":      1 << 30,
+			"gc_percent": 50,
+		})}).(js.Value))
+		x.True(err_js.IsUndefined())
+
+		prev_limit := v.Get("limit").Float()
+		prev_gc_percent := v.Get("gc_percent").Int()
+		defer l.JsSetMemory(js.Undefined(), []js.Value{v})
+
+		v, err_js = jz.Await(l.JsMemoryStats(js.Undefined(), nil).(js.Value))
+		x.True(err_js.IsUndefined())
+		x.Equal(1<<30, v.Get("memory_limit").Int())
+		x.Equal(50, v.Get("gc_percent").Int())
+
+		v, err_js = jz.Await(l.JsSetMemory(js.Undefined(), []js.Value{js.ValueOf(map[string]any{})}).(js.Value))
+		x.True(err_js.IsUndefined())
+		x.Equal(1<<30, v.Get("limit").Int())
+		x.Equal(50, v.Get("gc_percent").Int())
+		x.NotEqual(float64(1<<30), prev_limit)
+		x.NotEqual(50, prev_gc_percent)
+	})
+	t.Run("restore default limit", func(t *testing.T) {
+		x := require.New(t)
+
+		l := grpcwasm.NewListener()
+		defer l.Close()
+
+		v, err_js := jz.Await(l.JsSetMemory(js.Undefined(), []js.Value{js.ValueOf(map[string]any{
+			"limit": 1 << 30,
+		})}).(js.Value))
+		x.True(err_js.IsUndefined())
+		x.Equal(float64(math.MaxInt64), v.Get("limit").Float())
+
+		_, err_js = jz.Await(l.JsSetMemory(js.Undefined(), []js.Value{v}).(js.Value))
+		x.True(err_js.IsUndefined())
+		x.Equal(int64(math.MaxInt64), debug.SetMemoryLimit(-1))
+	})
+	t.Run("options restored on close", func(t *testing.T) {
+		x := require.New(t)
+
+		prev_limit := debug.SetMemoryLimit(-1)
+		prev_gc_percent := grpcwasm.ReadMemoryStats().GCPercent
+
+		l := grpcwasm.NewListener(grpcwasm.WithMemoryLimit(1<<30), grpcwasm.WithGCPercent(50))
+		x.Equal(int64(1<<30), debug.SetMemoryLimit(-1))
+		x.EqualValues(50, grpcwasm.ReadMemoryStats().GCPercent)
+
+		l.Close()
+		x.Equal(prev_limit, debug.SetMemoryLimit(-1))
+		x.EqualValues(prev_gc_percent, grpcwasm.ReadMemoryStats().GCPercent)
+	})
+	t.Run("gc", func(t *testing.T) {
+		l := grpcwasm.NewListener()
+		defer l.Close()
+
+		before := grpcwasm.ReadMemoryStats().GCCycles
+		_, err_js := jz.Await(l.JsGC(js.Undefined(), nil).(js.Value))
+		require.True(t, err_js.IsUndefined())
+		require.Greater(t, grpcwasm.ReadMemoryStats().GCCycles, before)
+	})
+	t.Run("watchdog", withListener(func(ctx context.Context, x *require.Assertions, l *grpcwasm.Listener, conn *grpcwasm.Conn) {
+		v, err_js := jsInvoke(x, conn, echo.EchoService_Once_FullMethodName, &echo.EchoRequest{}, nil)
+		x.True(err_js.IsUndefined())
+		x.Equal(int(codes.ResourceExhausted), v.Get("status").Get("code").Int())
+	}, grpcwasm.WithMemoryWatchdog(1)))
+}
+
+func TestListener_JsSnapshot(t *testing.T) {
+	x := require.New(t)
+
+	users := &fakeSnapshotter{state: "Lebowski"}
+	r := grpcwasm.NewSnapshots()
+	r.Register("users", users)
+
+	l := grpcwasm.NewListener(grpcwasm.WithSnapshots(r))
+	defer l.Close()
+
+	sock := l.ToJsValue()
+	data, err_js := jz.Await(sock.Call("snapshot"))
+	x.True(err_js.IsUndefined())
+
+	users.state = "Walter"
+	_, err_js = jz.Await(sock.Call("restore", data))
+	x.True(err_js.IsUndefined())
+	x.Equal("Lebowski", users.state)
+
+	_, err_js = jz.Await(sock.Call("restore", "Lebowski"))
+	x.Equal("argument", err_js.Get("kind").String())
+
+	_, err_js = jz.Await(sock.Call("restore", jz.BytesToJs([]byte("Lebowski"))))
+	x.Contains(err_js.Get("message").String(), "not a snapshot")
+}
@@ -0,0 +1,192 @@
+package grpcwasm
+
+import (
+	"context"
+	"math"
+	"runtime"
+	"runtime/metrics"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// MemoryStats is a summary of runtime/metrics about the memory of the bridge.
+type MemoryStats struct {
+	// Heap memory occupied by live objects that were marked by the previous GC.
+	HeapLive uint64 `js:"heap_live"`
+	// Memory occupied by live objects and dead objects that have not yet been freed.
+	HeapObjects uint64 `js:"heap_objects"`
+	// All memory mapped by the Go runtime. On WASM, it is roughly the size of
+	// the linear memory which only grows.
+	Total uint64 `js:"total"`
+
+	Goroutines uint64 `js:"goroutines"`
+
+	GCCycles uint64     `js:"gc_cycles"`
+	GCPauses PauseStats `js:"gc_pauses"`
+
+	// Current GOGC; negative if GC is off.
+	GCPercent int64 `js:"gc_percent"`
+	// Current soft memory limit in bytes.
+	MemoryLimit int64 `js:"memory_limit"`
+}
+
+// PauseStats approximates the distribution of the stop-the-world pauses for GC in seconds.
+type PauseStats struct {
+	Count uint64  `js:"count"`
+	P50   float64 `js:"p50"`
+	P99   float64 `js:"p99"`
+	Max   float64 `js:"max"`
+}
+
+var memoryMetricNames = []string{
+	"/gc/heap/live:bytes",
+	"/memory/classes/heap/objects:bytes",
+	"/memory/classes/total:bytes",
+	"/sched/goroutines:goroutines",
+	"/gc/cycles/total:gc-cycles",
+	"/sched/pauses/total/gc:seconds",
+	"/gc/gogc:percent",
+	"/gc/gomemlimit:bytes",
+}
+
+func ReadMemoryStats() MemoryStats {
+	samples := make([]metrics.Sample, len(memoryMetricNames))
+	for i, name := range memoryMetricNames {
+		samples[i].Name = name
+	}
+	metrics.Read(samples)
+
+	u := func(i int) uint64 {
+		if samples[i].Value.Kind() != metrics.KindUint64 {
+			return 0
+		}
+		return samples[i].Value.Uint64()
+	}
+
+	v := MemoryStats{
+		HeapLive:    u(0),
+		HeapObjects: u(1),
+		Total:       u(2),
+		Goroutines:  u(3),
+		GCCycles:    u(4),
+		GCPercent:   int64(u(6)),
+		MemoryLimit: int64(u(7)),
+	}
+	if samples[5].Value.Kind() == metrics.KindFloat64Histogram {
+		v.GCPauses = pauseStatsOf(samples[5].Value.Float64Histogram())
+	}
+
+	return v
+}
+
+func pauseStatsOf(h *metrics.Float64Histogram) PauseStats {
+	v := PauseStats{}
+	for _, n := range h.Counts {
+		v.Count += n
+	}
+	if v.Count == 0 {
+		return v
+	}
+
+	// Upper bound of the bucket where the quantile falls in.
+	quantile := func(q float64) float64 {
+		target := uint64(math.Ceil(q * float64(v.Count)))
+		acc := uint64(0)
+		for i, n := range h.Counts {
+			acc += n
+			if acc >= target {
+				return finite(h.Buckets[i+1], h.Buckets[i])
+			}
+		}
+		return 0
+	}
+
+	v.P50 = quantile(0.5)
+	v.P99 = quantile(0.99)
+	v.Max = quantile(1)
+	return v
+}
+
+// gcPercent returns current GOGC, negative if GC is off.
+func gcPercent() int {
+	sample := []metrics.Sample{{Name: "/gc/gogc:percent"}}
+	metrics.Read(sample)
+	// Negative value is stored as its two's complement.
+	return int(int64(sample[0].Value.Uint64()))
+}
+
+// finite returns v if it is finite, otherwise the fallback.
+func finite(v float64, fallback float64) float64 {
+	if math.IsInf(v, 0) {
+		return fallback
+	}
+	return v
+}
+
+// MemoryWatchdog rejects new calls with ResourceExhausted status
+// if the heap grows over its threshold, so the bridge fails the calls
+// instead of exhausting the memory of the WASM instance.
+type MemoryWatchdog struct {
+	// Threshold of the memory occupied by heap objects in bytes.
+	Threshold uint64
+	// Minimum interval between the GCs forced while over the threshold.
+	// Zero means [DefaultWatchdogGCInterval].
+	GCInterval time.Duration
+
+	// Unix time in nanoseconds of the last forced GC.
+	last_gc atomic.Int64
+}
+
+// DefaultWatchdogGCInterval is the default of [MemoryWatchdog.GCInterval].
+const DefaultWatchdogGCInterval = time.Second
+
+// check returns ResourceExhausted error if heap objects exceed the threshold.
+// It tries GC before it gives up, at most once per [MemoryWatchdog.GCInterval]
+// since GC stops the world.
+func (w *MemoryWatchdog) check() error {
+	sample := []metrics.Sample{{Name: "/memory/classes/heap/objects:bytes"}}
+	metrics.Read(sample)
+	if sample[0].Value.Uint64() <= w.Threshold {
+		return nil
+	}
+
+	interval := w.GCInterval
+	if interval == 0 {
+		interval = DefaultWatchdogGCInterval
+	}
+	now := time.Now().UnixNano()
+	last := w.last_gc.Load()
+	if (last == 0 || now-last >= int64(interval)) && w.last_gc.CompareAndSwap(last, now) {
+		runtime.GC()
+		metrics.Read(sample)
+	}
+
+	v := sample[0].Value.Uint64()
+	if v <= w.Threshold {
+		return nil
+	}
+
+	return status.Errorf(codes.ResourceExhausted, "heap objects of %d bytes exceed the threshold of %d bytes", v, w.Threshold)
+}
+
+func (w *MemoryWatchdog) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if err := w.check(); err != nil {
+			return err
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+func (w *MemoryWatchdog) StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		if err := w.check(); err != nil {
+			return nil, err
+		}
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+}
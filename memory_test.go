@@ -0,0 +1,70 @@
+package grpcwasm_test
+
+import (
+	"context"
+	"runtime/metrics"
+	"testing"
+	"time"
+
+	grpcwasm "github.com/lesomnus/grpc-wasm"
+	"github.com/lesomnus/grpc-wasm/internal/echo"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestReadMemoryStats(t *testing.T) {
+	v := grpcwasm.ReadMemoryStats()
+	require.NotZero(t, v.HeapObjects)
+	require.NotZero(t, v.Total)
+	require.NotZero(t, v.Goroutines)
+	require.NotZero(t, v.MemoryLimit)
+	require.GreaterOrEqual(t, v.GCPauses.Max, v.GCPauses.P99)
+	require.GreaterOrEqual(t, v.GCPauses.P99, v.GCPauses.P50)
+}
+
+func TestMemoryWatchdog(t *testing.T) {
+	t.Run("passes calls under threshold", func(t *testing.T) {
+		w := &grpcwasm.MemoryWatchdog{Threshold: 1 << 40}
+		withEchoClient(func(ctx context.Context, x *require.Assertions, client echo.EchoServiceClient) {
+			_, err := client.Once(ctx, echo.EchoRequest_builder{Message: "Lebowski"}.Build())
+			x.NoError(err)
+		},
+			grpc.WithChainUnaryInterceptor(w.UnaryClientInterceptor()),
+			grpc.WithChainStreamInterceptor(w.StreamClientInterceptor()),
+		)(t)
+	})
+	t.Run("rejects calls over threshold", func(t *testing.T) {
+		w := &grpcwasm.MemoryWatchdog{Threshold: 1}
+		withEchoClient(func(ctx context.Context, x *require.Assertions, client echo.EchoServiceClient) {
+			_, err := client.Once(ctx, echo.EchoRequest_builder{Message: "Lebowski"}.Build())
+			x.Equal(codes.ResourceExhausted, status.Code(err))
+
+			_, err = client.Live(ctx)
+			x.Equal(codes.ResourceExhausted, status.Code(err))
+		},
+			grpc.WithChainUnaryInterceptor(w.UnaryClientInterceptor()),
+			grpc.WithChainStreamInterceptor(w.StreamClientInterceptor()),
+		)(t)
+	})
+	t.Run("forces GC at most once per interval", func(t *testing.T) {
+		w := &grpcwasm.MemoryWatchdog{Threshold: 1, GCInterval: time.Hour}
+		forced := func() uint64 {
+			sample := []metrics.Sample{{Name: "/gc/cycles/forced:gc-cycles"}}
+			metrics.Read(sample)
+			return sample[0].Value.Uint64()
+		}
+		withEchoClient(func(ctx context.Context, x *require.Assertions, client echo.EchoServiceClient) {
+			before := forced()
+			for range 3 {
+				_, err := client.Once(ctx, echo.EchoRequest_builder{Message: "Lebowski"}.Build())
+				x.Equal(codes.ResourceExhausted, status.Code(err))
+			}
+			x.Equal(before+1, forced())
+		},
+			grpc.WithChainUnaryInterceptor(w.UnaryClientInterceptor()),
+			grpc.WithChainStreamInterceptor(w.StreamClientInterceptor()),
+		)(t)
+	})
+}
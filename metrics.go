@@ -0,0 +1,285 @@
+package grpcwasm
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/grpc/status"
+)
+
+var _ stats.Handler = (*Metrics)(nil)
+
+// DefaultLatencyBuckets are upper bounds in seconds of the latency histogram buckets.
+var DefaultLatencyBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Metrics collects per-method statistics of the calls made through the bridge.
+// It is installed on the connections dialed by the [Listener] so it sees every call
+// the JS side makes, but the numbers are named from the server's point of view,
+// e.g. "received" is a message sent by JS and received by the server.
+type Metrics struct {
+	buckets []float64
+
+	mu      sync.Mutex
+	methods map[string]*MethodMetrics
+}
+
+func NewMetrics() *Metrics {
+	return &Metrics{
+		buckets: slices.Clone(DefaultLatencyBuckets),
+		methods: map[string]*MethodMetrics{},
+	}
+}
+
+// NewMetricsWithBuckets is same as [NewMetrics] but uses given upper bounds in seconds
+// for the latency histogram instead of [DefaultLatencyBuckets].
+func NewMetricsWithBuckets(buckets []float64) *Metrics {
+	m := NewMetrics()
+	m.buckets = slices.Clone(buckets)
+	slices.Sort(m.buckets)
+	return m
+}
+
+type MethodType string
+
+const (
+	MethodTypeUnary        MethodType = "unary"
+	MethodTypeClientStream MethodType = "client_stream"
+	MethodTypeServerStream MethodType = "server_stream"
+	MethodTypeBidiStream   MethodType = "bidi_stream"
+)
+
+func methodTypeOf(client_stream bool, server_stream bool) MethodType {
+	switch {
+	case client_stream && server_stream:
+		return MethodTypeBidiStream
+	case client_stream:
+		return MethodTypeClientStream
+	case server_stream:
+		return MethodTypeServerStream
+	default:
+		return MethodTypeUnary
+	}
+}
+
+type Histogram struct {
+	// Upper bounds in seconds.
+	Buckets []float64
+	// Cumulative counts of each bucket.
+	Counts []uint64
+	Count  uint64
+	Sum    float64
+}
+
+func (h *Histogram) observe(v float64) {
+	for i, b := range h.Buckets {
+		if v <= b {
+			h.Counts[i]++
+		}
+	}
+	h.Count++
+	h.Sum += v
+}
+
+type MethodMetrics struct {
+	Method string     `js:"method"`
+	Type   MethodType `js:"type"`
+
+	Started uint64                `js:"started"`
+	Handled map[codes.Code]uint64 `js:"handled"`
+	// Number of calls that are started but not finished yet.
+	InFlight int64 `js:"in_flight"`
+
+	MsgReceived   uint64 `js:"msg_received"`
+	MsgSent       uint64 `js:"msg_sent"`
+	BytesReceived uint64 `js:"bytes_received"`
+	BytesSent     uint64 `js:"bytes_sent"`
+
+	Latency Histogram `js:"latency"`
+}
+
+func (m *Metrics) method(name string, t MethodType) *MethodMetrics {
+	v, ok := m.methods[name]
+	if ok {
+		return v
+	}
+
+	v = &MethodMetrics{
+		Method:  name,
+		Type:    t,
+		Handled: map[codes.Code]uint64{},
+		Latency: Histogram{
+			Buckets: m.buckets,
+			Counts:  make([]uint64, len(m.buckets)),
+		},
+	}
+	m.methods[name] = v
+	return v
+}
+
+type metricsCtxKey struct{}
+
+type metricsTag struct {
+	method string
+	t      MethodType
+	begin  time.Time
+}
+
+func (m *Metrics) TagRPC(ctx context.Context, info *stats.RPCTagInfo) context.Context {
+	return context.WithValue(ctx, metricsCtxKey{}, &metricsTag{method: info.FullMethodName})
+}
+
+func (m *Metrics) HandleRPC(ctx context.Context, s stats.RPCStats) {
+	tag, ok := ctx.Value(metricsCtxKey{}).(*metricsTag)
+	if !ok {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	switch s := s.(type) {
+	case *stats.Begin:
+		tag.t = methodTypeOf(s.IsClientStream, s.IsServerStream)
+		tag.begin = s.BeginTime
+
+		v := m.method(tag.method, tag.t)
+		v.Started++
+		v.InFlight++
+
+	case *stats.OutPayload:
+		v := m.method(tag.method, tag.t)
+		v.MsgReceived++
+		v.BytesReceived += uint64(s.Length)
+
+	case *stats.InPayload:
+		v := m.method(tag.method, tag.t)
+		v.MsgSent++
+		v.BytesSent += uint64(s.Length)
+
+	case *stats.End:
+		v := m.method(tag.method, tag.t)
+		v.InFlight--
+		v.Handled[status.Code(s.Error)]++
+		v.Latency.observe(s.EndTime.Sub(tag.begin).Seconds())
+	}
+}
+
+func (m *Metrics) TagConn(ctx context.Context, info *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (m *Metrics) HandleConn(ctx context.Context, s stats.ConnStats) {}
+
+// Snapshot returns a copy of the current metrics sorted by method name.
+func (m *Metrics) Snapshot() []MethodMetrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	vs := make([]MethodMetrics, 0, len(m.methods))
+	for _, v := range m.methods {
+		u := *v
+		u.Handled = make(map[codes.Code]uint64, len(v.Handled))
+		for k, n := range v.Handled {
+			u.Handled[k] = n
+		}
+		u.Latency.Buckets = slices.Clone(v.Latency.Buckets)
+		u.Latency.Counts = slices.Clone(v.Latency.Counts)
+		vs = append(vs, u)
+	}
+	slices.SortFunc(vs, func(a, b MethodMetrics) int {
+		return strings.Compare(a.Method, b.Method)
+	})
+
+	return vs
+}
+
+// Reset drops all the collected metrics.
+// Number of calls in flight is kept so it does not go negative when they finish.
+func (m *Metrics) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	methods := m.methods
+	m.methods = map[string]*MethodMetrics{}
+	for k, v := range methods {
+		if v.InFlight == 0 {
+			continue
+		}
+
+		m.method(k, v.Type).InFlight = v.InFlight
+	}
+}
+
+// WritePrometheus writes the metrics in Prometheus text exposition format.
+// Metric names follow the ones of go-grpc-prometheus server metrics.
+func (m *Metrics) WritePrometheus(w io.Writer) error {
+	vs := m.Snapshot()
+
+	b := &strings.Builder{}
+	family := func(name string, t string, help string, f func(v *MethodMetrics, labels string)) {
+		fmt.Fprintf(b, "# HELP %s %s\n", name, help)
+		fmt.Fprintf(b, "# TYPE %s %s\n", name, t)
+		for i := range vs {
+			v := &vs[i]
+			service, method := splitMethodName(v.Method)
+			labels := fmt.Sprintf(`grpc_method=%q,grpc_service=%q,grpc_type=%q`, method, service, v.Type)
+			f(v, labels)
+		}
+	}
+
+	family("grpc_server_started_total", "counter", "Total number of RPCs started on the server.", func(v *MethodMetrics, labels string) {
+		fmt.Fprintf(b, "grpc_server_started_total{%s} %d\n", labels, v.Started)
+	})
+	family("grpc_server_handled_total", "counter", "Total number of RPCs completed on the server, regardless of success or failure.", func(v *MethodMetrics, labels string) {
+		cs := make([]codes.Code, 0, len(v.Handled))
+		for c := range v.Handled {
+			cs = append(cs, c)
+		}
+		slices.Sort(cs)
+		for _, c := range cs {
+			fmt.Fprintf(b, "grpc_server_handled_total{grpc_code=%q,%s} %d\n", c.String(), labels, v.Handled[c])
+		}
+	})
+	family("grpc_server_in_flight", "gauge", "Number of RPCs started on the server but not finished yet.", func(v *MethodMetrics, labels string) {
+		fmt.Fprintf(b, "grpc_server_in_flight{%s} %d\n", labels, v.InFlight)
+	})
+	family("grpc_server_msg_received_total", "counter", "Total number of RPC stream messages received on the server.", func(v *MethodMetrics, labels string) {
+		fmt.Fprintf(b, "grpc_server_msg_received_total{%s} %d\n", labels, v.MsgReceived)
+	})
+	family("grpc_server_msg_sent_total", "counter", "Total number of gRPC stream messages sent by the server.", func(v *MethodMetrics, labels string) {
+		fmt.Fprintf(b, "grpc_server_msg_sent_total{%s} %d\n", labels, v.MsgSent)
+	})
+	family("grpc_server_msg_received_bytes_total", "counter", "Total size in bytes of RPC stream messages received on the server.", func(v *MethodMetrics, labels string) {
+		fmt.Fprintf(b, "grpc_server_msg_received_bytes_total{%s} %d\n", labels, v.BytesReceived)
+	})
+	family("grpc_server_msg_sent_bytes_total", "counter", "Total size in bytes of gRPC stream messages sent by the server.", func(v *MethodMetrics, labels string) {
+		fmt.Fprintf(b, "grpc_server_msg_sent_bytes_total{%s} %d\n", labels, v.BytesSent)
+	})
+	family("grpc_server_handling_seconds", "histogram", "Histogram of response latency (seconds) of gRPC that had been application-level handled by the server.", func(v *MethodMetrics, labels string) {
+		for i, le := range v.Latency.Buckets {
+			fmt.Fprintf(b, "grpc_server_handling_seconds_bucket{%s,le=\"%g\"} %d\n", labels, le, v.Latency.Counts[i])
+		}
+		fmt.Fprintf(b, "grpc_server_handling_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels, v.Latency.Count)
+		fmt.Fprintf(b, "grpc_server_handling_seconds_sum{%s} %g\n", labels, v.Latency.Sum)
+		fmt.Fprintf(b, "grpc_server_handling_seconds_count{%s} %d\n", labels, v.Latency.Count)
+	})
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// splitMethodName splits "/package.service/method" into "package.service" and "method".
+func splitMethodName(name string) (string, string) {
+	name = strings.TrimPrefix(name, "/")
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		return name[:i], name[i+1:]
+	}
+	return "unknown", name
+}
@@ -0,0 +1,134 @@
+package grpcwasm_test
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	grpcwasm "github.com/lesomnus/grpc-wasm"
+	"github.com/lesomnus/grpc-wasm/internal/echo"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func withEchoClient(f func(ctx context.Context, x *require.Assertions, client echo.EchoServiceClient), opts ...grpc.DialOption) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Helper()
+
+		x := require.New(t)
+
+		l := bufconn.Listen(1 << 20)
+		defer l.Close()
+
+		opts = append(opts,
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			grpc.WithContextDialer(func(ctx context.Context, s string) (net.Conn, error) {
+				return l.DialContext(ctx)
+			}),
+		)
+		conn, err := grpc.NewClient("passthrough://bufnet", opts...)
+		x.NoError(err)
+		defer conn.Close()
+
+		s := grpc.NewServer()
+		echo.RegisterEchoServiceServer(s, echo.EchoServer{})
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.Serve(l)
+		}()
+
+		f(t.Context(), x, echo.NewEchoServiceClient(conn))
+
+		conn.Close()
+		s.Stop()
+		wg.Wait()
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	m := grpcwasm.NewMetrics()
+	t.Run("unary", withEchoClient(func(ctx context.Context, x *require.Assertions, client echo.EchoServiceClient) {
+		m.Reset()
+
+		_, err := client.Once(ctx, echo.EchoRequest_builder{Message: "Lebowski"}.Build())
+		x.NoError(err)
+		_, err = client.Once(ctx, echo.EchoRequest_builder{
+			Status: echo.Status_builder{Code: int32(codes.NotFound)}.Build(),
+		}.Build())
+		x.Error(err)
+
+		vs := m.Snapshot()
+		x.Len(vs, 1)
+
+		v := vs[0]
+		x.Equal(echo.EchoService_Once_FullMethodName, v.Method)
+		x.Equal(grpcwasm.MethodTypeUnary, v.Type)
+		x.Equal(uint64(2), v.Started)
+		x.Equal(uint64(1), v.Handled[codes.OK])
+		x.Equal(uint64(1), v.Handled[codes.NotFound])
+		x.Equal(int64(0), v.InFlight)
+		x.Equal(uint64(2), v.MsgReceived)
+		x.Equal(uint64(1), v.MsgSent)
+		x.NotZero(v.BytesReceived)
+		x.NotZero(v.BytesSent)
+		x.Equal(uint64(2), v.Latency.Count)
+
+		// Snapshot is a copy.
+		v.Latency.Buckets[0] = 42
+		x.NotEqual(float64(42), m.Snapshot()[0].Latency.Buckets[0])
+		x.NotEqual(float64(42), grpcwasm.DefaultLatencyBuckets[0])
+	}, grpc.WithStatsHandler(m)))
+	t.Run("server stream", withEchoClient(func(ctx context.Context, x *require.Assertions, client echo.EchoServiceClient) {
+		m.Reset()
+
+		stream, err := client.Many(ctx, echo.EchoRequest_builder{Message: "Lebowski", Repeat: ptr(uint32(3))}.Build())
+		x.NoError(err)
+		for range 3 {
+			_, err := stream.Recv()
+			x.NoError(err)
+		}
+
+		vs := m.Snapshot()
+		x.Len(vs, 1)
+		x.Equal(grpcwasm.MethodTypeServerStream, vs[0].Type)
+		x.Equal(int64(1), vs[0].InFlight)
+		x.Equal(uint64(3), vs[0].MsgSent)
+
+		m.Reset()
+		_, err = stream.Recv()
+		x.Error(err)
+
+		vs = m.Snapshot()
+		x.Len(vs, 1)
+		x.Equal(int64(0), vs[0].InFlight)
+		x.Equal(uint64(1), vs[0].Handled[codes.OK])
+	}, grpc.WithStatsHandler(m)))
+	t.Run("prometheus", withEchoClient(func(ctx context.Context, x *require.Assertions, client echo.EchoServiceClient) {
+		m.Reset()
+
+		_, err := client.Once(ctx, echo.EchoRequest_builder{Message: "Lebowski"}.Build())
+		x.NoError(err)
+
+		b := &strings.Builder{}
+		err = m.WritePrometheus(b)
+		x.NoError(err)
+
+		v := b.String()
+		x.Contains(v, "# TYPE grpc_server_started_total counter\n")
+		x.Contains(v, `grpc_server_started_total{grpc_method="Once",grpc_service="echo.EchoService",grpc_type="unary"} 1`)
+		x.Contains(v, `grpc_server_handled_total{grpc_code="OK",grpc_method="Once",grpc_service="echo.EchoService",grpc_type="unary"} 1`)
+		x.Contains(v, `grpc_server_handling_seconds_bucket{grpc_method="Once",grpc_service="echo.EchoService",grpc_type="unary",le="+Inf"} 1`)
+	}, grpc.WithStatsHandler(m)))
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,14 @@
+//go:build !(js && wasm)
+
+package grpcwasm
+
+import (
+	"errors"
+	"io/fs"
+)
+
+// MountFS makes the files of fsys readable by package os under given absolute directory.
+// It is supported only in js/wasm; read fsys directly elsewhere.
+func MountFS(dir string, fsys fs.FS) error {
+	return errors.ErrUnsupported
+}
@@ -0,0 +1,298 @@
+//go:build js && wasm
+
+package grpcwasm
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/lesomnus/grpc-wasm/internal/js"
+)
+
+// MountFS makes the files of fsys readable by package os under given absolute directory,
+// e.g. so [os.ReadFile] of "/fixtures/seed.json" reads "seed.json" of fsys.
+// It hooks the "fs" object that wasm_exec.js gives to the Go runtime;
+// paths outside the directory go to the original one.
+// The mount is read-only and lasts until the program exits.
+func MountFS(dir string, fsys fs.FS) error {
+	if !path.IsAbs(dir) {
+		return fmt.Errorf("mount point must be absolute: %q", dir)
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		// The browser shim has no working directory.
+		cwd = "/"
+	}
+
+	m := &mount{
+		dir:   path.Clean(dir),
+		cwd:   cwd,
+		fsys:  fsys,
+		files: map[int]fs.File{},
+	}
+	m.hook(js.Global().Get("fs"))
+	return nil
+}
+
+// mountFdBase is the first file descriptor of the mounted files,
+// far from the ones the host gives.
+const mountFdBase = 1 << 30
+
+var mountFd = struct {
+	sync.Mutex
+	next int
+}{next: mountFdBase}
+
+type mount struct {
+	dir  string
+	cwd  string
+	fsys fs.FS
+
+	mu    sync.Mutex
+	files map[int]fs.File
+}
+
+// rel returns the name in the mounted file system of given host path.
+func (m *mount) rel(p string) (string, bool) {
+	if path.IsAbs(p) {
+		p = path.Clean(p)
+	} else {
+		p = path.Join(m.cwd, p)
+	}
+
+	if p == m.dir {
+		return ".", true
+	}
+	prefix := m.dir + "/"
+	if m.dir == "/" {
+		prefix = "/"
+	}
+	rest, ok := strings.CutPrefix(p, prefix)
+	return rest, ok
+}
+
+func (m *mount) file(fd int) (fs.File, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, ok := m.files[fd]
+	return f, ok
+}
+
+// jsError returns the error of Node.js style that the Go runtime maps to errno.
+func jsError(err error) js.Value {
+	code := "EIO"
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		code = "ENOENT"
+	case errors.Is(err, fs.ErrInvalid):
+		code = "EINVAL"
+	case errors.Is(err, fs.ErrPermission):
+		code = "EACCES"
+	case errors.Is(err, syscall.EROFS):
+		code = "EROFS"
+	case errors.Is(err, syscall.EBADF):
+		code = "EBADF"
+	case errors.Is(err, syscall.EISDIR):
+		code = "EISDIR"
+	}
+
+	e := js.Global().Get("Error").New(err.Error())
+	e.Set("code", code)
+	return e
+}
+
+var isDirectory = js.FuncOf(func(this js.Value, args []js.Value) any {
+	return uint32(this.Get("mode").Int())&syscall.S_IFMT == syscall.S_IFDIR
+})
+
+func jsStat(info fs.FileInfo) js.Value {
+	mode := uint32(info.Mode().Perm())
+	if info.IsDir() {
+		mode |= syscall.S_IFDIR
+	} else {
+		mode |= syscall.S_IFREG
+	}
+	ms := info.ModTime().UnixMilli()
+
+	st := js.ValueOf(map[string]any{
+		"dev":     0,
+		"ino":     0,
+		"mode":    mode,
+		"nlink":   1,
+		"uid":     0,
+		"gid":     0,
+		"rdev":    0,
+		"size":    info.Size(),
+		"blksize": 4096,
+		"blocks":  (info.Size() + 511) / 512,
+		"atimeMs": ms,
+		"mtimeMs": ms,
+		"ctimeMs": ms,
+	})
+	st.Set("isDirectory", isDirectory)
+	return st
+}
+
+// hook replaces the methods of given fs object.
+// Each hooked method handles the paths and the file descriptors of the mount,
+// answering through the callback in its own goroutine as Node.js does asynchronously.
+func (m *mount) hook(fsys js.Value) {
+	by_path := func(name string, f func(name string, args []js.Value) (any, error)) {
+		m.wrap(fsys, name, func(args []js.Value) (func() (any, error), bool) {
+			name, ok := m.rel(args[0].String())
+			if !ok {
+				return nil, false
+			}
+			return func() (any, error) { return f(name, args[1:]) }, true
+		})
+	}
+	by_fd := func(name string, f func(file fs.File, fd int, args []js.Value) (any, error)) {
+		m.wrap(fsys, name, func(args []js.Value) (func() (any, error), bool) {
+			fd := args[0].Int()
+			file, ok := m.file(fd)
+			if !ok {
+				return nil, false
+			}
+			return func() (any, error) { return f(file, fd, args[1:]) }, true
+		})
+	}
+	read_only := func(args []js.Value) (func() (any, error), bool) {
+		if args[0].Type() == js.TypeNumber {
+			if _, ok := m.file(args[0].Int()); !ok {
+				return nil, false
+			}
+		} else if _, ok := m.rel(args[0].String()); !ok {
+			return nil, false
+		}
+		return func() (any, error) { return nil, syscall.EROFS }, true
+	}
+
+	// Flags that open a file for writing; the browser shim gives -1 for all of them.
+	writing := 0
+	for _, name := range []string{"O_WRONLY", "O_RDWR", "O_CREAT", "O_TRUNC", "O_APPEND", "O_EXCL"} {
+		if v := fsys.Get("constants").Get(name); v.Type() == js.TypeNumber && v.Int() > 0 {
+			writing |= v.Int()
+		}
+	}
+
+	by_path("open", func(name string, args []js.Value) (any, error) {
+		if flags := args[0].Int(); flags < 0 || flags&writing != 0 {
+			return nil, syscall.EROFS
+		}
+		f, err := m.fsys.Open(name)
+		if err != nil {
+			return nil, err
+		}
+
+		mountFd.Lock()
+		fd := mountFd.next
+		mountFd.next++
+		mountFd.Unlock()
+
+		m.mu.Lock()
+		m.files[fd] = f
+		m.mu.Unlock()
+		return fd, nil
+	})
+	by_fd("close", func(file fs.File, fd int, args []js.Value) (any, error) {
+		m.mu.Lock()
+		delete(m.files, fd)
+		m.mu.Unlock()
+		return nil, file.Close()
+	})
+	by_fd("fstat", func(file fs.File, fd int, args []js.Value) (any, error) {
+		info, err := file.Stat()
+		if err != nil {
+			return nil, err
+		}
+		return jsStat(info), nil
+	})
+	by_fd("read", func(file fs.File, fd int, args []js.Value) (any, error) {
+		buf, offset, length, position := args[0], args[1].Int(), args[2].Int(), args[3]
+
+		b := make([]byte, length)
+		var n int
+		var err error
+		if position.IsNull() || position.IsUndefined() {
+			n, err = file.Read(b)
+		} else if r, ok := file.(io.ReaderAt); ok {
+			n, err = r.ReadAt(b, int64(position.Int()))
+		} else {
+			return nil, syscall.ESPIPE
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		if n > 0 {
+			js.CopyBytesToJS(buf.Call("subarray", offset, offset+n), b[:n])
+		}
+		return n, nil
+	})
+	for _, name := range []string{"stat", "lstat"} {
+		by_path(name, func(name string, args []js.Value) (any, error) {
+			info, err := fs.Stat(m.fsys, name)
+			if err != nil {
+				return nil, err
+			}
+			return jsStat(info), nil
+		})
+	}
+	by_path("readdir", func(name string, args []js.Value) (any, error) {
+		entries, err := fs.ReadDir(m.fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		names := make([]any, len(entries))
+		for i, e := range entries {
+			names[i] = e.Name()
+		}
+		return names, nil
+	})
+	for _, name := range []string{
+		"write", "fchmod", "fchown", "fsync", "ftruncate",
+		"chmod", "chown", "lchown", "link", "mkdir", "readlink",
+		"rename", "rmdir", "symlink", "truncate", "unlink", "utimes",
+	} {
+		m.wrap(fsys, name, read_only)
+	}
+}
+
+// wrap replaces the method of given name with the one calling f.
+// f returns the operation if the call is for the mount, or false to call the original method.
+func (m *mount) wrap(fsys js.Value, name string, f func(args []js.Value) (func() (any, error), bool)) {
+	orig := fsys.Get(name)
+	fsys.Set(name, js.FuncOf(func(this js.Value, args []js.Value) any {
+		var run func() (any, error)
+		ok := false
+		if len(args) > 1 {
+			run, ok = f(args)
+		}
+		if !ok {
+			params := make([]any, len(args))
+			for i, arg := range args {
+				params[i] = arg
+			}
+			return orig.Call("apply", fsys, params)
+		}
+
+		cb := args[len(args)-1]
+		go func() {
+			v, err := run()
+			if err != nil {
+				cb.Invoke(jsError(err))
+				return
+			}
+			cb.Invoke(nil, v)
+		}()
+		return nil
+	}))
+}
@@ -0,0 +1,52 @@
+package grpcwasm
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"runtime/pprof"
+	"runtime/trace"
+	"time"
+)
+
+// Profile returns the runtime/pprof profile of given name, e.g. "heap", "allocs",
+// "goroutine", "block" or "mutex".
+// With debug 0 the profile is in gzipped protobuf format which can be opened by `go tool pprof`.
+// Otherwise it is in legacy text format; see [pprof.Profile.WriteTo] for the meaning of debug.
+//
+// Block and mutex profiles are empty unless their rates are set, e.g. by [WithContentionProfiles].
+func Profile(name string, debug int) ([]byte, error) {
+	p := pprof.Lookup(name)
+	if p == nil {
+		return nil, fmt.Errorf("unknown profile: %q", name)
+	}
+
+	b := &bytes.Buffer{}
+	if err := p.WriteTo(b, debug); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+// Trace captures runtime/trace execution trace for given duration
+// which can be opened by `go tool trace`.
+// It returns what is captured so far if the context is done before the duration.
+// Only one trace can be captured at a time.
+func Trace(ctx context.Context, d time.Duration) ([]byte, error) {
+	b := &bytes.Buffer{}
+	if err := trace.Start(b); err != nil {
+		return nil, err
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+	case <-ctx.Done():
+	}
+
+	trace.Stop()
+	return b.Bytes(), nil
+}
@@ -0,0 +1,46 @@
+package grpcwasm_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	grpcwasm "github.com/lesomnus/grpc-wasm"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfile(t *testing.T) {
+	for _, name := range []string{"heap", "allocs", "goroutine", "block", "mutex"} {
+		t.Run(name, func(t *testing.T) {
+			data, err := grpcwasm.Profile(name, 0)
+			require.NoError(t, err)
+			// gzip magic number.
+			require.Equal(t, []byte{0x1f, 0x8b}, data[:2])
+		})
+	}
+	t.Run("text", func(t *testing.T) {
+		data, err := grpcwasm.Profile("goroutine", 2)
+		require.NoError(t, err)
+		require.Contains(t, string(data), "goroutine")
+	})
+	t.Run("unknown", func(t *testing.T) {
+		_, err := grpcwasm.Profile("foo", 0)
+		require.ErrorContains(t, err, "foo")
+	})
+}
+
+func TestTrace(t *testing.T) {
+	t.Run("captures for given duration", func(t *testing.T) {
+		data, err := grpcwasm.Trace(t.Context(), 10*time.Millisecond)
+		require.NoError(t, err)
+		require.Contains(t, string(data[:16]), "go ")
+	})
+	t.Run("stops when context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+
+		data, err := grpcwasm.Trace(ctx, time.Hour)
+		require.NoError(t, err)
+		require.NotEmpty(t, data)
+	})
+}
@@ -0,0 +1,59 @@
+package grpcwasm
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+)
+
+// SeededSource is a PCG source safe for concurrent use that can be re-seeded.
+type SeededSource struct {
+	mu  sync.Mutex
+	src *rand.PCG
+}
+
+func NewSeededSource(seed uint64) *SeededSource {
+	return &SeededSource{src: rand.NewPCG(seed, seed)}
+}
+
+func (s *SeededSource) Uint64() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.src.Uint64()
+}
+
+// Seed resets the source so it yields the same sequence as a new source of given seed.
+func (s *SeededSource) Seed(seed uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.src.Seed(seed, seed)
+}
+
+type randKey struct{}
+
+// ContextWithRand returns a context whose [RandFrom] is given generator.
+func ContextWithRand(ctx context.Context, r *rand.Rand) context.Context {
+	return context.WithValue(ctx, randKey{}, r)
+}
+
+// RandFrom returns the generator set by [ContextWithRand], or the generator of the bridge
+// the call came from if it is a context of a handler, which the JS side can seed.
+// It returns a generator of the global source of math/rand/v2 if neither is found.
+// The generator is safe for concurrent use.
+func RandFrom(ctx context.Context) *rand.Rand {
+	if r, ok := ctx.Value(randKey{}).(*rand.Rand); ok {
+		return r
+	}
+	if l := listenerFrom(ctx); l != nil {
+		return l.rand
+	}
+	return rand.New(globalSource{})
+}
+
+type globalSource struct{}
+
+func (globalSource) Uint64() uint64 {
+	return rand.Uint64()
+}
@@ -0,0 +1,145 @@
+package grpcwasm
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+	"weak"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// NewServer creates a gRPC server with [RecoveryUnaryServerInterceptor] and
+// [RecoveryStreamServerInterceptor] installed before the interceptors in given options.
+// grpc-go does not recover panics in handlers, and a panic kills the whole WASM instance,
+// so [Serve], [ServeFactory], and [ServeAuto] log a warning for the servers not made by it.
+func NewServer(opts ...grpc.ServerOption) *grpc.Server {
+	opts = append([]grpc.ServerOption{
+		grpc.StatsHandler(serverStats{}),
+		grpc.ChainUnaryInterceptor(RecoveryUnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(RecoveryStreamServerInterceptor()),
+	}, opts...)
+	s := grpc.NewServer(opts...)
+
+	p := weak.Make(s)
+	recovered_servers.Store(p, struct{}{})
+	runtime.AddCleanup(s, func(p weak.Pointer[grpc.Server]) {
+		recovered_servers.Delete(p)
+	}, p)
+	return s
+}
+
+// recovered_servers holds the servers made by [NewServer] until they are collected.
+var recovered_servers sync.Map
+
+// warnNotRecovered logs a warning if given server is not made by [NewServer].
+func warnNotRecovered(s *grpc.Server) {
+	if _, ok := recovered_servers.Load(weak.Make(s)); ok {
+		return
+	}
+	log.Print("grpcwasm: server is not made by grpcwasm.NewServer, so a panic in a handler kills the process")
+}
+
+// PanicEvent describes a panic recovered in a handler.
+type PanicEvent struct {
+	Method string    `js:"method"`
+	Value  string    `js:"value"`
+	Stack  string    `js:"stack"`
+	Time   time.Time `js:"time"`
+}
+
+// RecoveryUnaryServerInterceptor turns a panic in the handler into Internal status.
+// The stack trace is logged and attached to the status as [errdetails.DebugInfo].
+func RecoveryUnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (res any, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recovered(ctx, info.FullMethod, r)
+			}
+		}()
+
+		return handler(ctx, req)
+	}
+}
+
+// RecoveryStreamServerInterceptor turns a panic in the handler into Internal status.
+// See [RecoveryUnaryServerInterceptor].
+func RecoveryStreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recovered(ss.Context(), info.FullMethod, r)
+			}
+		}()
+
+		return handler(srv, ss)
+	}
+}
+
+func recovered(ctx context.Context, method string, r any) error {
+	e := PanicEvent{
+		Method: method,
+		Value:  fmt.Sprint(r),
+		Stack:  string(debug.Stack()),
+		Time:   time.Now(),
+	}
+	log.Printf("grpcwasm: panic in %s: %s\n%s", e.Method, e.Value, e.Stack)
+
+	// Reported to the listener the call came from.
+	if l := listenerFrom(ctx); l != nil {
+		l.panics.publish(e)
+	}
+
+	s := status.Newf(codes.Internal, "panic in %s: %s", e.Method, e.Value)
+	if s_, err := s.WithDetails(&errdetails.DebugInfo{
+		StackEntries: strings.Split(strings.TrimSpace(e.Stack), "\n"),
+		Detail:       e.Value,
+	}); err == nil {
+		s = s_
+	}
+	return s.Err()
+}
+
+// panicHub fans out panic events to its subscribers.
+// Events are dropped for subscribers that do not keep up.
+type panicHub struct {
+	mu   sync.Mutex
+	subs map[chan PanicEvent]struct{}
+}
+
+func (h *panicHub) publish(e PanicEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for c := range h.subs {
+		select {
+		case c <- e:
+		default:
+		}
+	}
+}
+
+func (h *panicHub) subscribe() (<-chan PanicEvent, func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.subs == nil {
+		h.subs = map[chan PanicEvent]struct{}{}
+	}
+
+	c := make(chan PanicEvent, 64)
+	h.subs[c] = struct{}{}
+	return c, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		delete(h.subs, c)
+	}
+}
@@ -0,0 +1,84 @@
+package grpcwasm_test
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+
+	grpcwasm "github.com/lesomnus/grpc-wasm"
+	"github.com/lesomnus/grpc-wasm/internal/echo"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// panicEchoServer panics in every method.
+type panicEchoServer struct {
+	echo.UnimplementedEchoServiceServer
+}
+
+func (panicEchoServer) Once(context.Context, *echo.EchoRequest) (*echo.EchoResponse, error) {
+	panic("handler panicked")
+}
+
+func (panicEchoServer) Live(grpc.BidiStreamingServer[echo.EchoRequest, echo.EchoResponse]) error {
+	panic("handler panicked")
+}
+
+func TestNewServer(t *testing.T) {
+	x := require.New(t)
+
+	l := bufconn.Listen(1 << 20)
+	defer l.Close()
+
+	conn, err := grpc.NewClient("passthrough://bufnet",
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(ctx context.Context, s string) (net.Conn, error) {
+			return l.DialContext(ctx)
+		}),
+	)
+	x.NoError(err)
+	defer conn.Close()
+
+	s := grpcwasm.NewServer()
+	echo.RegisterEchoServiceServer(s, panicEchoServer{})
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		s.Serve(l)
+	}()
+	defer wg.Wait()
+	defer s.Stop()
+
+	client := echo.NewEchoServiceClient(conn)
+	t.Run("unary", func(t *testing.T) {
+		x := require.New(t)
+
+		_, err := client.Once(t.Context(), &echo.EchoRequest{})
+		s := status.Convert(err)
+		x.Equal(codes.Internal, s.Code())
+		x.Contains(s.Message(), "handler panicked")
+
+		x.Len(s.Details(), 1)
+		info, ok := s.Details()[0].(*errdetails.DebugInfo)
+		x.True(ok)
+		x.Equal("handler panicked", info.GetDetail())
+		x.NotEmpty(info.GetStackEntries())
+	})
+	t.Run("stream", func(t *testing.T) {
+		x := require.New(t)
+
+		stream, err := client.Live(t.Context())
+		x.NoError(err)
+
+		_, err = stream.Recv()
+		x.Equal(codes.Internal, status.Code(err))
+	})
+}
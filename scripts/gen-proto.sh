@@ -17,6 +17,8 @@ OUTPUT_DIR_GO="$__root/internal/echo"
 OUTPUT_DIR_ES="$__root/src/@connectrpc/test/proto"
 OUTPUT_DIR_TS="$__root/src/@protobuf-ts/test/proto"
 MODULE_NAME="github.com/lesomnus/grpc-wasm/internal/echo"
+OUTPUT_DIR_INSPECTOR="$__root/inspector"
+INSPECTOR_MODULE_NAME="github.com/lesomnus/grpc-wasm/inspector"
 
 protoc \
 	--plugin=protoc-gen-ts=./node_modules/.bin/protoc-gen-ts \
@@ -36,4 +38,16 @@ protoc \
 	--ts_out=${OUTPUT_DIR_TS} \
 	--ts_opt=server_generic \
 	\
-	"$PROTO_ROOT"/**/*.proto
+	"$PROTO_ROOT"/echo/*.proto
+
+protoc \
+	--proto_path=${PROTO_ROOT} \
+	\
+	--go_out=${OUTPUT_DIR_INSPECTOR} \
+	--go_opt=module=${INSPECTOR_MODULE_NAME} \
+	--go_opt=default_api_level=API_OPAQUE \
+	\
+	--go-grpc_out=${OUTPUT_DIR_INSPECTOR} \
+	--go-grpc_opt=module=${INSPECTOR_MODULE_NAME} \
+	\
+	"$PROTO_ROOT"/inspector/*.proto
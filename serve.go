@@ -8,15 +8,56 @@ import (
 	"google.golang.org/grpc"
 )
 
-func Serve(s *grpc.Server) error {
-	l, err := Listen()
+// Serve serves given server on the listener made by [Listen] until the JS side closes it.
+// The server should be made by [NewServer] so panics in the handlers do not kill the WASM instance.
+// After the server stopped, it waits for the calls from JS side to finish
+// up to the timeout set by [WithShutdownTimeout].
+func Serve(s *grpc.Server, opts ...ListenOption) error {
+	warnNotRecovered(s)
+
+	l, err := Listen(opts...)
 	if err != nil {
 		return fmt.Errorf("listen: %w", err)
 	}
 
 	err = s.Serve(l)
 	s.Stop()
-	l.Wait()
 
-	return err
+	return l.shutdown(err)
+}
+
+// ServeFactory serves the servers made by given factory on the listener made by [Listen]
+// until the JS side closes it.
+// Unlike [Serve], the JS side can reset the server without restarting the WASM instance,
+// and every connection can have its own server with [WithIsolatedServers].
+// The factory should make the servers by [NewServer].
+func ServeFactory(f ServerFactory, opts ...ListenOption) error {
+	l, err := Listen(append([]ListenOption{WithServerFactory(f)}, opts...)...)
+	if err != nil {
+		return fmt.Errorf("listen: %w", err)
+	}
+
+	err = l.Run()
+
+	return l.shutdown(err)
+}
+
+// ServeAuto serves given server the way the target supports, so one main package
+// builds both natively and for WASM.
+// In WASM, it is [Serve] with the options set by [WithListenOptions].
+// Natively, it serves gRPC on the address set by [WithAddr], and gRPC-Web
+// on the one set by [WithWeb], until the context set by [WithServeContext] ends.
+func ServeAuto(s *grpc.Server, opts ...ServeOption) error {
+	c := newServeConfig(opts)
+	defer c.register(s)()
+
+	listen_opts := c.listen_opts
+	if h := c.statsHandler("bridge"); h != nil {
+		listen_opts = append(listen_opts, WithStatsHandler(h))
+	}
+	if c.logger != nil {
+		c.logger.Info("serving", "transport", "bridge")
+	}
+
+	return Serve(s, listen_opts...)
 }
@@ -0,0 +1,138 @@
+//go:build !wasm
+
+package grpcwasm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// shutdownGracePeriod is how long [ServeAuto] waits for the calls in flight when it stops natively.
+const shutdownGracePeriod = 10 * time.Second
+
+// ServeAuto serves given server the way the target supports, so one main package
+// builds both natively and for WASM.
+// Natively, it serves gRPC on the address set by [WithAddr], and gRPC-Web
+// on the one set by [WithWeb], until the context set by [WithServeContext] ends.
+// In WASM, it is [Serve] with the options set by [WithListenOptions].
+// The server should be made by [NewServer] on every target; calls are logged natively only if it is.
+func ServeAuto(s *grpc.Server, opts ...ServeOption) error {
+	warnNotRecovered(s)
+
+	c := newServeConfig(opts)
+	ctx := c.ctx
+	if ctx == nil {
+		var stop context.CancelFunc
+		ctx, stop = signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+	}
+
+	lis := c.lis
+	if lis == nil {
+		var err error
+		if lis, err = net.Listen("tcp", c.addr); err != nil {
+			return fmt.Errorf("listen: %w", err)
+		}
+	}
+	defer lis.Close()
+
+	web_lis := c.web_lis
+	if web_lis == nil && c.web_addr != "" {
+		var err error
+		if web_lis, err = net.Listen("tcp", c.web_addr); err != nil {
+			return fmt.Errorf("listen web: %w", err)
+		}
+	}
+	if web_lis != nil {
+		defer web_lis.Close()
+	}
+
+	shutdown_health := c.register(s)
+	defer shutdown_health()
+
+	defer s.Stop()
+
+	errs := make(chan error, 2)
+	go func() {
+		if err := s.Serve(transportListener{Listener: lis, handler: c.statsHandler("grpc")}); err != nil {
+			errs <- err
+		}
+	}()
+	if c.logger != nil {
+		c.logger.Info("serving", "transport", "grpc", "addr", lis.Addr().String())
+	}
+
+	var web *http.Server
+	if web_lis != nil {
+		// Web calls are served by the same server through an in-process connection.
+		buf := bufconn.Listen(1 << 20)
+		defer buf.Close()
+		go s.Serve(transportListener{Listener: buf, handler: c.statsHandler("web")})
+
+		web_conn, err := dialBuffered(buf)
+		if err != nil {
+			s.Stop()
+			return fmt.Errorf("dial: %w", err)
+		}
+		defer web_conn.Close()
+
+		web = &http.Server{
+			Handler:   &webHandler{conn: web_conn},
+			Protocols: &http.Protocols{},
+		}
+		web.Protocols.SetHTTP1(true)
+		web.Protocols.SetUnencryptedHTTP2(true)
+		go func() {
+			if err := web.Serve(web_lis); !errors.Is(err, http.ErrServerClosed) {
+				errs <- err
+			}
+		}()
+		if c.logger != nil {
+			c.logger.Info("serving", "transport", "web", "addr", web_lis.Addr().String())
+		}
+	}
+
+	var err error
+	select {
+	case <-ctx.Done():
+	case err = <-errs:
+		err = fmt.Errorf("serve: %w", err)
+	}
+
+	// Let health checks fail while draining.
+	shutdown_health()
+
+	shutdown_ctx, cancel := context.WithTimeout(context.Background(), shutdownGracePeriod)
+	defer cancel()
+
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		s.GracefulStop()
+	}()
+	if web != nil {
+		if err := web.Shutdown(shutdown_ctx); err != nil {
+			web.Close()
+		}
+	}
+	select {
+	case <-stopped:
+	case <-shutdown_ctx.Done():
+		s.Stop()
+	}
+	if c.logger != nil {
+		c.logger.Info("stopped")
+	}
+
+	return err
+}
@@ -0,0 +1,78 @@
+//go:build wasip1
+
+package grpcwasm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// Serve serves given server to the WASI host over stdin and stdout
+// with the protocol of package [framing] until stdin ends.
+// Logs go to stderr.
+// The server should be made by [NewServer] so panics in the handlers do not kill the module.
+// It is served on the listener made by [NewListener] with given options, as [ServeFramed] does.
+func Serve(s *grpc.Server, opts ...ListenOption) error {
+	return serve(s, opts...)
+}
+
+// ServeAuto serves given server the way the target supports, so one main package
+// builds both natively and for WASM.
+// In WASI, it is [Serve] with the options set by [WithListenOptions];
+// the logger set by [WithLogger] should write to stderr.
+// Natively, it serves gRPC on the address set by [WithAddr], and gRPC-Web
+// on the one set by [WithWeb], until the context set by [WithServeContext] ends.
+func ServeAuto(s *grpc.Server, opts ...ServeOption) error {
+	c := newServeConfig(opts)
+	defer c.register(s)()
+
+	listen_opts := c.listen_opts
+	if h := c.statsHandler("framed"); h != nil {
+		listen_opts = append(listen_opts, WithStatsHandler(h))
+	}
+	if c.logger != nil {
+		c.logger.Info("serving", "transport", "framed")
+	}
+	return serve(s, listen_opts...)
+}
+
+func serve(s *grpc.Server, opts ...ListenOption) error {
+	warnNotRecovered(s)
+
+	// Blocking read on stdin would block every goroutine.
+	if err := syscall.SetNonblock(0, true); err != nil {
+		return fmt.Errorf("set stdin non-blocking: %w", err)
+	}
+	stdin := pollingReader{os.NewFile(0, "stdin")}
+
+	err := ServeFramed(context.Background(), s, stdin, os.Stdout, opts...)
+	s.Stop()
+
+	return err
+}
+
+// pollingReader reads a non-blocking file, sleeping while there is nothing to read
+// so other goroutines run.
+// Not every WASI host supports polling stdin, so the runtime cannot wait for it.
+type pollingReader struct {
+	f *os.File
+}
+
+func (r pollingReader) Read(p []byte) (int, error) {
+	d := 100 * time.Microsecond
+	for {
+		n, err := r.f.Read(p)
+		if !errors.Is(err, syscall.EAGAIN) {
+			return n, err
+		}
+
+		time.Sleep(d)
+		d = min(2*d, 10*time.Millisecond)
+	}
+}
@@ -0,0 +1,103 @@
+package grpcwasm
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/lesomnus/grpc-wasm/internal/blob"
+)
+
+// Snapshotter is implemented by the services whose state can be captured and restored,
+// e.g. a fake backend with a seeded database.
+type Snapshotter interface {
+	Snapshot() ([]byte, error)
+	Restore(data []byte) error
+}
+
+// SnapshotVersion is the version of the blob made by [Snapshots.Snapshot].
+const SnapshotVersion = 1
+
+var snapshotFormat = blob.Format{Magic: "GWSS", Version: SnapshotVersion, Name: "snapshot"}
+
+// Snapshots combines the snapshots of the registered services into one versioned blob.
+//
+// The blob is:
+//
+//	"GWSS" | version (uvarint) | count (uvarint) | { name length (uvarint) | name | data length (uvarint) | data }...
+type Snapshots struct {
+	mu       sync.Mutex
+	services map[string]Snapshotter
+}
+
+func NewSnapshots() *Snapshots {
+	return &Snapshots{
+		services: map[string]Snapshotter{},
+	}
+}
+
+// Register registers the service of given name.
+// It replaces the service registered with the same name,
+// so the servers made by [ServerFactory] can register their new services.
+func (r *Snapshots) Register(name string, s Snapshotter) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.services[name] = s
+}
+
+func (r *Snapshots) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.services, name)
+}
+
+// Snapshot captures the snapshots of every registered service.
+func (r *Snapshots) Snapshot() ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := make([]string, 0, len(r.services))
+	for name := range r.services {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	entries := make([]blob.Entry, len(names))
+	for i, name := range names {
+		data, err := r.services[name].Snapshot()
+		if err != nil {
+			return nil, fmt.Errorf("snapshot %q: %w", name, err)
+		}
+		entries[i] = blob.Entry{Name: name, Data: data}
+	}
+
+	return snapshotFormat.Encode(entries), nil
+}
+
+// Restore restores the services from the blob made by [Snapshots.Snapshot].
+// Services not in the blob are left untouched.
+// Nothing is restored if the blob has a service that is not registered.
+func (r *Snapshots) Restore(data []byte) error {
+	entries, err := snapshotFormat.Decode(data)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, e := range entries {
+		if _, ok := r.services[e.Name]; !ok {
+			return fmt.Errorf("service %q is not registered", e.Name)
+		}
+	}
+	for _, e := range entries {
+		if err := r.services[e.Name].Restore(e.Data); err != nil {
+			return fmt.Errorf("restore %q: %w", e.Name, err)
+		}
+	}
+
+	return nil
+}
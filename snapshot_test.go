@@ -0,0 +1,107 @@
+package grpcwasm_test
+
+import (
+	"errors"
+	"testing"
+
+	grpcwasm "github.com/lesomnus/grpc-wasm"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSnapshotter struct {
+	state string
+	err   error
+}
+
+func (s *fakeSnapshotter) Snapshot() ([]byte, error) {
+	return []byte(s.state), s.err
+}
+
+func (s *fakeSnapshotter) Restore(data []byte) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.state = string(data)
+	return nil
+}
+
+func TestSnapshots(t *testing.T) {
+	t.Run("restore", func(t *testing.T) {
+		x := require.New(t)
+
+		users := &fakeSnapshotter{state: "Lebowski"}
+		rugs := &fakeSnapshotter{state: "tied the room together"}
+
+		r := grpcwasm.NewSnapshots()
+		r.Register("users", users)
+		r.Register("rugs", rugs)
+
+		data, err := r.Snapshot()
+		x.NoError(err)
+
+		users.state = "Walter"
+		rugs.state = ""
+
+		err = r.Restore(data)
+		x.NoError(err)
+		x.Equal("Lebowski", users.state)
+		x.Equal("tied the room together", rugs.state)
+	})
+	t.Run("services not in the snapshot are untouched", func(t *testing.T) {
+		x := require.New(t)
+
+		r := grpcwasm.NewSnapshots()
+		data, err := r.Snapshot()
+		x.NoError(err)
+
+		users := &fakeSnapshotter{state: "Lebowski"}
+		r.Register("users", users)
+		err = r.Restore(data)
+		x.NoError(err)
+		x.Equal("Lebowski", users.state)
+	})
+	t.Run("unregistered service", func(t *testing.T) {
+		x := require.New(t)
+
+		users := &fakeSnapshotter{state: "Lebowski"}
+		rugs := &fakeSnapshotter{state: "tied the room together"}
+
+		r := grpcwasm.NewSnapshots()
+		r.Register("users", users)
+		r.Register("rugs", rugs)
+		data, err := r.Snapshot()
+		x.NoError(err)
+
+		users.state = "Walter"
+		r.Unregister("rugs")
+		err = r.Restore(data)
+		x.ErrorContains(err, `"rugs" is not registered`)
+		x.Equal("Walter", users.state)
+	})
+	t.Run("snapshot error", func(t *testing.T) {
+		x := require.New(t)
+
+		r := grpcwasm.NewSnapshots()
+		r.Register("users", &fakeSnapshotter{err: errors.New("Nihilists")})
+		_, err := r.Snapshot()
+		x.ErrorContains(err, "Nihilists")
+	})
+	t.Run("malformed", func(t *testing.T) {
+		x := require.New(t)
+
+		r := grpcwasm.NewSnapshots()
+		r.Register("users", &fakeSnapshotter{state: "Lebowski"})
+		data, err := r.Snapshot()
+		x.NoError(err)
+
+		err = r.Restore([]byte("Lebowski"))
+		x.ErrorContains(err, "not a snapshot")
+
+		err = r.Restore(data[:len(data)-1])
+		x.ErrorContains(err, "malformed")
+
+		data[4] = 2
+		err = r.Restore(data)
+		x.ErrorContains(err, "unsupported snapshot version 2")
+	})
+}
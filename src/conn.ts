@@ -20,19 +20,25 @@ export interface Conn {
 	open_bidi_stream(method: string, option: CallOption): Promise<BidiStreamingClient>;
 }
 
+// Closes connections dropped without being closed so the bridge can reclaim them.
+const finalizer = new FinalizationRegistry<() => void>((close) => close());
+
 export class ClientConn implements Conn {
 	private close_work: Promise<void> | undefined;
 
 	constructor(
 		private worker: BridgeWorker,
 		private id: ConnId,
-	) {}
+	) {
+		finalizer.register(this, () => void worker.close(id), this);
+	}
 
 	close(): Promise<void> {
 		if (this.close_work) {
 			return this.close_work;
 		}
 
+		finalizer.unregister(this);
 		this.close_work = this.worker.close(this.id);
 		return this.close_work;
 	}
@@ -0,0 +1,111 @@
+import type { Metadata } from "./types";
+
+export type GrpcWasmErrorKind =
+	// Status returned by the server.
+	| "status"
+	// Connection to the server failed, i.e. status UNAVAILABLE.
+	| "transport"
+	// Arguments given to the bridge are wrong.
+	| "argument"
+	// Any other failure in the bridge including panics.
+	| "internal";
+
+// Keep compatibility with proto message google.protobuf.Any.
+export type ErrorDetail = {
+	type_url: string;
+	value: Uint8Array;
+};
+
+export type GrpcWasmErrorInit = {
+	kind?: GrpcWasmErrorKind;
+	code?: number;
+	grpcMessage?: string;
+	details?: ErrorDetail[];
+	metadata?: Metadata;
+	cause?: unknown;
+};
+
+// Every rejection from the bridge is a GrpcWasmError.
+// The bridge builds its `cause` chain from the Go error chain.
+export class GrpcWasmError extends Error {
+	readonly kind: GrpcWasmErrorKind;
+	readonly code: number;
+	readonly grpcMessage: string;
+	readonly details: ErrorDetail[];
+	readonly metadata: Metadata;
+
+	constructor(message: string, init: GrpcWasmErrorInit = {}) {
+		super(message, init.cause === undefined ? undefined : { cause: init.cause });
+		this.name = "GrpcWasmError";
+		this.kind = init.kind ?? "internal";
+		this.code = init.code ?? 2;
+		this.grpcMessage = init.grpcMessage ?? message;
+		this.details = init.details ?? [];
+		this.metadata = init.metadata ?? {};
+	}
+
+	// GrpcWasmTypeError is a GrpcWasmError too.
+	static [Symbol.hasInstance](v: unknown): boolean {
+		return Function.prototype[Symbol.hasInstance].call(GrpcWasmError, v) || v instanceof GrpcWasmTypeError;
+	}
+}
+
+// Wrong arguments and panics at the boundary are rejected with GrpcWasmTypeError,
+// which is a TypeError as well as a GrpcWasmError.
+export class GrpcWasmTypeError extends TypeError {
+	readonly kind: GrpcWasmErrorKind;
+	readonly code: number;
+	readonly grpcMessage: string;
+	readonly details: ErrorDetail[];
+	readonly metadata: Metadata;
+
+	constructor(message: string, init: GrpcWasmErrorInit = {}) {
+		super(message, init.cause === undefined ? undefined : { cause: init.cause });
+		this.name = "GrpcWasmTypeError";
+		this.kind = init.kind ?? "argument";
+		this.code = init.code ?? 3;
+		this.grpcMessage = init.grpcMessage ?? message;
+		this.details = init.details ?? [];
+		this.metadata = init.metadata ?? {};
+	}
+}
+
+type SerializedError = {
+	__grpc_wasm_error: GrpcWasmErrorInit & { message: string; type_error?: boolean };
+};
+
+// Serializer passing GrpcWasmError between the worker and the main thread
+// since structured clone drops the fields of the errors.
+export const errorSerializer = {
+	serialize(input: unknown, fallback: (input: unknown) => unknown): unknown {
+		if (!(input instanceof GrpcWasmError)) {
+			return fallback(input);
+		}
+
+		const v: SerializedError = {
+			__grpc_wasm_error: {
+				message: input.message,
+				type_error: input instanceof GrpcWasmTypeError,
+				kind: input.kind,
+				code: input.code,
+				grpcMessage: input.grpcMessage,
+				details: input.details,
+				metadata: input.metadata,
+				cause: input.cause === undefined ? undefined : errorSerializer.serialize(input.cause, fallback),
+			},
+		};
+		return v;
+	},
+	deserialize(message: unknown, fallback: (message: unknown) => unknown): unknown {
+		if (typeof message !== "object" || message === null || !("__grpc_wasm_error" in message)) {
+			return fallback(message);
+		}
+
+		const { message: msg, cause, type_error, ...init } = (message as SerializedError).__grpc_wasm_error;
+		const E = type_error ? GrpcWasmTypeError : GrpcWasmError;
+		return new E(msg, {
+			...init,
+			cause: cause === undefined ? undefined : errorSerializer.deserialize(cause, fallback),
+		});
+	},
+};
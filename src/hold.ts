@@ -0,0 +1,80 @@
+import type { HeldCall, HoldResult, RpcStatus } from "./types";
+import type { BridgeWorker, HoldId } from "./worker";
+
+// Hold keeps the calls it holds until they are released or failed.
+// Closing it releases every call it holds.
+export interface Hold extends AsyncIterable<HeldCall> {
+	// Calls held now, in the order they arrived.
+	held(): Promise<HeldCall[]>;
+	// Next call held after the ones received before.
+	recv(): Promise<HoldResult>;
+	release(id: number): Promise<void>;
+	release_all(): Promise<void>;
+	// Fails the call with the status, which must not be OK.
+	fail(id: number, status: RpcStatus): Promise<void>;
+	close(): Promise<void>;
+}
+
+// Closes holds dropped without being closed so the held calls are not stuck.
+const finalizer = new FinalizationRegistry<() => void>((close) => close());
+
+export class ClientHold implements Hold {
+	private close_work: Promise<void> | undefined;
+
+	constructor(
+		private worker: BridgeWorker,
+		private id: HoldId,
+	) {
+		finalizer.register(this, () => void worker.hold_close(id), this);
+	}
+
+	held(): Promise<HeldCall[]> {
+		if (this.close_work) {
+			return Promise.resolve([]);
+		}
+		return this.worker.hold_held(this.id);
+	}
+
+	recv(): Promise<HoldResult> {
+		if (this.close_work) {
+			return Promise.resolve({ done: true });
+		}
+		return this.worker.hold_recv(this.id);
+	}
+
+	release(id: number): Promise<void> {
+		return this.worker.hold_release(this.id, id);
+	}
+
+	release_all(): Promise<void> {
+		return this.worker.hold_release_all(this.id);
+	}
+
+	fail(id: number, status: RpcStatus): Promise<void> {
+		return this.worker.hold_fail(this.id, id, status);
+	}
+
+	close(): Promise<void> {
+		if (this.close_work) {
+			return this.close_work;
+		}
+
+		finalizer.unregister(this);
+		this.close_work = this.worker.hold_close(this.id);
+		return this.close_work;
+	}
+
+	async *[Symbol.asyncIterator](): AsyncIterator<HeldCall> {
+		try {
+			while (true) {
+				const v = await this.recv();
+				if (v.done) {
+					return;
+				}
+				yield v.call;
+			}
+		} finally {
+			await this.close();
+		}
+	}
+}
@@ -0,0 +1,90 @@
+// Host functions are functions of the page that the bridge calls by `grpcwasm.CallHost`.
+// The bridge runs in a worker, so the calls are relayed through a MessagePort.
+
+// Arguments are decoded from the Go values, e.g. []byte is a Uint8Array.
+// The result must survive structured clone.
+// `code` of the thrown error is the gRPC status code the Go side sees, e.g. 5 for NOT_FOUND.
+// biome-ignore lint/suspicious/noExplicitAny: arguments are whatever the Go side gives.
+export type HostFunction = (...args: any[]) => unknown;
+
+export type HostFunctions = Record<string, HostFunction>;
+
+// Port of the host functions given to the worker.
+export type HostPort = {
+	port: MessagePort;
+	names: string[];
+};
+
+type HostRequest = {
+	id: number;
+	name: string;
+	args: unknown[];
+};
+
+type HostError = {
+	name: string;
+	message: string;
+	code?: number;
+};
+
+type HostResponse = { id: number; value: unknown } | { id: number; error: HostError };
+
+function toHostError(err: unknown): HostError {
+	if (!(err instanceof Error)) {
+		return { name: "", message: String(err) };
+	}
+
+	const code = (err as { code?: unknown }).code;
+	return {
+		name: err.name,
+		message: err.message,
+		code: typeof code === "number" ? code : undefined,
+	};
+}
+
+// Answers the calls from the worker through the port.
+export function serveHost(port: MessagePort, fns: HostFunctions) {
+	port.onmessage = async (e: MessageEvent<HostRequest>) => {
+		const { id, name, args } = e.data;
+		let res: HostResponse;
+		try {
+			res = { id, value: await fns[name](...args) };
+			port.postMessage(res);
+		} catch (err) {
+			res = { id, error: toHostError(err) };
+			port.postMessage(res);
+		}
+	};
+}
+
+// Returns the functions calling the ones served through the port by `serveHost`.
+export function hostFunctions({ port, names }: HostPort): HostFunctions {
+	let seq = 0;
+	const pending = new Map<number, { resolve: (v: unknown) => void; reject: (err: unknown) => void }>();
+	port.onmessage = (e: MessageEvent<HostResponse>) => {
+		const res = e.data;
+		const p = pending.get(res.id);
+		pending.delete(res.id);
+		if (p === undefined) {
+			return;
+		}
+		if ("error" in res) {
+			const err = Object.assign(new Error(res.error.message), res.error);
+			p.reject(err);
+		} else {
+			p.resolve(res.value);
+		}
+	};
+
+	const fns: HostFunctions = {};
+	for (const name of names) {
+		fns[name] = (...args) =>
+			new Promise((resolve, reject) => {
+				const id = ++seq;
+				pending.set(id, { resolve, reject });
+				const req: HostRequest = { id, name, args };
+				port.postMessage(req);
+			});
+	}
+	return fns;
+}
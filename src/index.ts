@@ -1,6 +1,12 @@
 export * from "./types";
+export * from "./error";
 export { type Sock, open } from "./sock";
+export type { HostFunction, HostFunctions } from "./host";
 export type { Conn } from "./conn";
+export type { Inspection } from "./inspect";
+export type { Panics } from "./panics";
+export type { Interception } from "./intercept";
+export type { Hold } from "./hold";
 export type {
 	ClientStream,
 	ServerStreamingClient,
@@ -0,0 +1,55 @@
+import type { InspectResult, InspectorEvent } from "./types";
+import type { BridgeWorker, InspectionId } from "./worker";
+
+// Inspection receives events of every call going through the bridge.
+// The first event is always a snapshot of the calls currently open.
+// Another snapshot follows if events were dropped because they were not read in time.
+export interface Inspection extends AsyncIterable<InspectorEvent> {
+	recv(): Promise<InspectResult>;
+	close(): Promise<void>;
+}
+
+// Closes inspections dropped without being closed so the bridge can reclaim them.
+const finalizer = new FinalizationRegistry<() => void>((close) => close());
+
+export class ClientInspection implements Inspection {
+	private close_work: Promise<void> | undefined;
+
+	constructor(
+		private worker: BridgeWorker,
+		private id: InspectionId,
+	) {
+		finalizer.register(this, () => void worker.inspect_close(id), this);
+	}
+
+	recv(): Promise<InspectResult> {
+		if (this.close_work) {
+			return Promise.resolve({ done: true });
+		}
+		return this.worker.inspect_recv(this.id);
+	}
+
+	close(): Promise<void> {
+		if (this.close_work) {
+			return this.close_work;
+		}
+
+		finalizer.unregister(this);
+		this.close_work = this.worker.inspect_close(this.id);
+		return this.close_work;
+	}
+
+	async *[Symbol.asyncIterator](): AsyncIterator<InspectorEvent> {
+		try {
+			while (true) {
+				const v = await this.recv();
+				if (v.done) {
+					return;
+				}
+				yield v.event;
+			}
+		} finally {
+			await this.close();
+		}
+	}
+}
@@ -0,0 +1,37 @@
+import { Transfer } from "threads";
+
+import { serveHost } from "./host";
+import type { Interceptor } from "./types";
+import type { BridgeWorker, InterceptionId } from "./worker";
+
+// Interception keeps the interceptor in the chain until it is closed.
+export interface Interception {
+	close(): Promise<void>;
+}
+
+export class ClientInterception implements Interception {
+	private close_work: Promise<void> | undefined;
+
+	private constructor(
+		private worker: BridgeWorker,
+		private id: InterceptionId,
+		private port: MessagePort,
+	) {}
+
+	// The interceptor runs in this thread and the bridge calls it through a MessagePort.
+	static async open(worker: BridgeWorker, interceptor: Interceptor): Promise<ClientInterception> {
+		const { port1, port2 } = new MessageChannel();
+		serveHost(port1, { intercept: interceptor });
+		const id = await worker.intercept(Transfer(port2, [port2]) as unknown as MessagePort);
+		return new ClientInterception(worker, id, port1);
+	}
+
+	close(): Promise<void> {
+		if (this.close_work) {
+			return this.close_work;
+		}
+
+		this.close_work = this.worker.intercept_close(this.id).finally(() => this.port.close());
+		return this.close_work;
+	}
+}
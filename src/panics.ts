@@ -0,0 +1,53 @@
+import type { PanicEvent, PanicResult } from "./types";
+import type { BridgeWorker, PanicsId } from "./worker";
+
+// Panics receives the panics recovered in the handlers of the bridge.
+export interface Panics extends AsyncIterable<PanicEvent> {
+	recv(): Promise<PanicResult>;
+	close(): Promise<void>;
+}
+
+// Closes subscriptions dropped without being closed so the bridge can reclaim them.
+const finalizer = new FinalizationRegistry<() => void>((close) => close());
+
+export class ClientPanics implements Panics {
+	private close_work: Promise<void> | undefined;
+
+	constructor(
+		private worker: BridgeWorker,
+		private id: PanicsId,
+	) {
+		finalizer.register(this, () => void worker.panics_close(id), this);
+	}
+
+	recv(): Promise<PanicResult> {
+		if (this.close_work) {
+			return Promise.resolve({ done: true });
+		}
+		return this.worker.panics_recv(this.id);
+	}
+
+	close(): Promise<void> {
+		if (this.close_work) {
+			return this.close_work;
+		}
+
+		finalizer.unregister(this);
+		this.close_work = this.worker.panics_close(this.id);
+		return this.close_work;
+	}
+
+	async *[Symbol.asyncIterator](): AsyncIterator<PanicEvent> {
+		try {
+			while (true) {
+				const v = await this.recv();
+				if (v.done) {
+					return;
+				}
+				yield v.event;
+			}
+		} finally {
+			await this.close();
+		}
+	}
+}
@@ -0,0 +1,129 @@
+import { beforeEach, describe, expect, test } from "vitest";
+
+import { type Conn, GrpcWasmError, type Sock, open } from "./index";
+
+import { EchoRequest } from "./@protobuf-ts/test/proto/echo/echo";
+
+describe("sock", () => {
+	let sock: Sock;
+	let conn: Conn;
+	beforeEach(async () => {
+		const p = new URL("./test/echobridge.wasm", import.meta.url);
+		const sock_ = await open(p.toString());
+		const conn_ = await sock_.dial();
+		sock = sock_;
+		conn = conn_;
+		return async () => {
+			await conn_.close();
+			await sock_.close();
+		};
+	});
+
+	const once = (m: EchoRequest) =>
+		conn.invoke("/echo.EchoService/Once", EchoRequest.toBinary(m), {});
+
+	test("active calls", async () => {
+		const p = once({ message: "Lebowski", overVoid: true });
+
+		let calls = await sock.active_calls();
+		for (let i = 0; calls.length === 0 && i < 100; i++) {
+			await new Promise((resolve) => setTimeout(resolve, 10));
+			calls = await sock.active_calls();
+		}
+		expect(calls).toHaveLength(1);
+		expect(calls[0].method).toEqual("/echo.EchoService/Once");
+		expect(calls[0].type).toEqual("unary");
+
+		await sock.cancel_call(calls[0].id, { code: 14, message: "unavailable" });
+		const { status } = await p;
+		expect(status.code).toEqual(14);
+		expect(status.message).toEqual("unavailable");
+		expect(await sock.active_calls()).toHaveLength(0);
+	});
+	test("rejects with GrpcWasmError", async () => {
+		const err = await sock.cancel_call(0).catch((err) => err);
+		expect(err).toBeInstanceOf(GrpcWasmError);
+		expect(err.kind).toEqual("status");
+		expect(err.code).toEqual(5);
+	});
+	test("profile", async () => {
+		const heap = await sock.profile("heap");
+		expect(heap.slice(0, 2)).toEqual(new Uint8Array([0x1f, 0x8b]));
+
+		const goroutine = await sock.profile("goroutine", { debug: 2 });
+		expect(new TextDecoder().decode(goroutine)).toContain("goroutine");
+	});
+	test("trace", async () => {
+		const trace = await sock.trace(10);
+		expect(trace.length).toBeGreaterThan(0);
+	});
+	test("memory", async () => {
+		const prev = await sock.set_memory({ limit: 1 << 30, gc_percent: 50 });
+		const stats = await sock.memory_stats();
+		expect(stats.memory_limit).toEqual(1 << 30);
+		expect(stats.gc_percent).toEqual(50);
+
+		await sock.gc();
+		const { gc_cycles } = await sock.memory_stats();
+		expect(gc_cycles).toBeGreaterThan(stats.gc_cycles);
+
+		await sock.set_memory(prev);
+	});
+	test("handles", async () => {
+		const before = await sock.handles();
+
+		const conn = await sock.dial();
+		const stream = await conn.open_bidi_stream("/echo.EchoService/Live", {});
+		expect(await sock.handles()).toMatchObject({
+			conns: before.conns + 1,
+			streams: before.streams + 1,
+		});
+
+		await stream.close();
+		await conn.close();
+		expect(await sock.handles()).toEqual(before);
+	});
+	test("metrics", async () => {
+		await once({ message: "Lebowski" });
+		await once({ message: "Lebowski", status: { code: 5, message: "" } });
+
+		const metrics = await sock.metrics();
+		const m = metrics.methods.find((m) => m.method === "/echo.EchoService/Once");
+		expect(m).not.toBeUndefined();
+		expect(m?.started).toEqual(2);
+		expect(m?.handled.OK).toEqual(1);
+		expect(m?.handled.NotFound).toEqual(1);
+		expect(m?.latency.count).toEqual(2);
+
+		const text = await sock.metrics_text();
+		expect(text).toContain("grpc_server_started_total");
+
+		await sock.reset_metrics();
+		const { methods } = await sock.metrics();
+		expect(methods).toHaveLength(0);
+	});
+	test("inspect", async () => {
+		const inspection = await sock.inspect();
+
+		const first = await inspection.recv();
+		expect(first.done).toBe(false);
+		if (first.done) return;
+		expect(first.event.snapshot).not.toBeUndefined();
+
+		await once({ message: "Lebowski" });
+
+		let decoded: unknown;
+		for await (const event of inspection) {
+			if (event.message_sent) {
+				decoded = event.message_sent.decoded;
+			}
+			if (event.call_finished) {
+				break;
+			}
+		}
+		expect(decoded).toEqual({ message: "Lebowski" });
+
+		const last = await inspection.recv();
+		expect(last.done).toBe(true);
+	});
+});
@@ -1,11 +1,100 @@
-import { type ModuleThread, Thread, Worker, spawn } from "threads";
+import { type ModuleThread, Thread, Transfer, Worker, registerSerializer, spawn } from "threads";
 
 import { ClientConn, type Conn } from "./conn";
-import type { BridgeWorker } from "./worker";
+import { errorSerializer } from "./error";
+import { ClientHold, type Hold } from "./hold";
+import { type HostFunctions, serveHost } from "./host";
+import { ClientInspection, type Inspection } from "./inspect";
+import { ClientInterception, type Interception } from "./intercept";
+import { ClientPanics, type Panics } from "./panics";
+import type {
+	ActiveCall,
+	ClockState,
+	Handles,
+	Interceptor,
+	MemoryOption,
+	MemoryStats,
+	Metrics,
+	ProfileName,
+	ProfileOption,
+	RpcStatus,
+} from "./types";
+import type { BridgeWorker, StartOption } from "./worker";
 
 export interface Sock {
 	close(): Promise<void>;
 	dial(): Promise<Conn>;
+
+	// Calls in flight made through the bridge.
+	active_calls(): Promise<ActiveCall[]>;
+	// Cancels the call of given ID so the client sees given status.
+	// The call is cancelled with status CANCELLED if the status is not given.
+	cancel_call(id: number, status?: RpcStatus): Promise<void>;
+
+	// Metrics are available only if the bridge is served with `grpcwasm.WithMetrics`.
+	metrics(): Promise<Metrics>;
+	// Metrics in Prometheus text exposition format.
+	metrics_text(): Promise<string>;
+	reset_metrics(): Promise<void>;
+
+	// runtime/pprof profile of given name.
+	// Block and mutex profiles are empty unless the bridge is served with `grpcwasm.WithContentionProfiles`.
+	profile(name: ProfileName, option?: ProfileOption): Promise<Uint8Array>;
+	// runtime/trace execution trace captured for given duration.
+	trace(duration_ms: number): Promise<Uint8Array>;
+
+	// Sets the soft memory limit and GOGC of the bridge.
+	// Resolves with the previous values.
+	set_memory(option: MemoryOption): Promise<MemoryOption>;
+	// Forces a garbage collection.
+	gc(): Promise<void>;
+	memory_stats(): Promise<MemoryStats>;
+	// Number of live objects the bridge exported to JS, useful to find leaks.
+	handles(): Promise<Handles>;
+
+	// Replaces the server with a new one made by the factory, draining the calls in flight.
+	// Available only if the bridge is served with `grpcwasm.ServeFactory`.
+	reset(): Promise<void>;
+	// Captures the state of the services into one blob.
+	// Available only if the bridge is served with `grpcwasm.WithSnapshots`.
+	snapshot(): Promise<Uint8Array>;
+	// Restores the state of the services from the blob made by `snapshot`.
+	restore(data: Uint8Array): Promise<void>;
+
+	// Clock that handlers get by `grpcwasm.ClockFrom`.
+	// It runs along with the real time until it is frozen.
+	clock_now(): Promise<ClockState>;
+	clock_freeze(): Promise<void>;
+	clock_resume(): Promise<void>;
+	// Sets or advances the time, firing the timers and tickers due by the time.
+	clock_set(date: Date): Promise<void>;
+	clock_advance(duration_ms: number): Promise<void>;
+	// Re-seeds the generator that handlers get by `grpcwasm.RandFrom`.
+	set_seed(seed: number): Promise<void>;
+	// Sets the location handlers get by `grpcwasm.LocationFrom` to given IANA time zone, e.g. "Asia/Seoul".
+	set_timezone(name: string): Promise<void>;
+	// Adds or replaces the files read through `grpcwasm.Files`.
+	put_files(files: Record<string, Uint8Array>): Promise<void>;
+	// Removes the files of the paths and the files under them; "/" removes every file.
+	remove_files(paths: string[]): Promise<void>;
+	// Entries of the store handlers get by `grpcwasm.KVFrom`, e.g. to save them as a file.
+	kv_export(): Promise<Uint8Array>;
+	// Replaces every entry of the store with the ones exported by `kv_export`.
+	kv_import(data: Uint8Array): Promise<void>;
+
+	// Adds the interceptor at the end of the chain called by the servers with
+	// `grpcwasm.JsUnaryServerInterceptor` and `grpcwasm.JsStreamServerInterceptor`.
+	intercept(interceptor: Interceptor): Promise<Interception>;
+	// Holds the calls of the methods matching the pattern, e.g. "/echo.EchoService/*",
+	// before their handlers run until they are released through the returned handle.
+	// Streams are held when they open and then at every message in both directions.
+	// Calls are held only by the servers with the interceptors of `intercept`.
+	hold(pattern: string): Promise<Hold>;
+
+	// Inspection is available only if the bridge is served with `grpcwasm.WithInspector`.
+	inspect(): Promise<Inspection>;
+	// Panics recovered in the handlers.
+	panics(): Promise<Panics>;
 }
 
 class ClientSock {
@@ -20,10 +109,133 @@ class ClientSock {
 		const id = await this.worker.dial();
 		return new ClientConn(this.worker, id);
 	}
+
+	active_calls(): Promise<ActiveCall[]> {
+		return this.worker.active_calls();
+	}
+
+	cancel_call(id: number, status?: RpcStatus): Promise<void> {
+		return this.worker.cancel_call(id, status);
+	}
+
+	metrics(): Promise<Metrics> {
+		return this.worker.metrics();
+	}
+
+	metrics_text(): Promise<string> {
+		return this.worker.metrics_text();
+	}
+
+	reset_metrics(): Promise<void> {
+		return this.worker.reset_metrics();
+	}
+
+	profile(name: ProfileName, option?: ProfileOption): Promise<Uint8Array> {
+		return this.worker.profile(name, option);
+	}
+
+	trace(duration_ms: number): Promise<Uint8Array> {
+		return this.worker.trace(duration_ms);
+	}
+
+	set_memory(option: MemoryOption): Promise<MemoryOption> {
+		return this.worker.set_memory(option);
+	}
+
+	gc(): Promise<void> {
+		return this.worker.gc();
+	}
+
+	memory_stats(): Promise<MemoryStats> {
+		return this.worker.memory_stats();
+	}
+
+	handles(): Promise<Handles> {
+		return this.worker.handles();
+	}
+
+	reset(): Promise<void> {
+		return this.worker.reset();
+	}
+
+	snapshot(): Promise<Uint8Array> {
+		return this.worker.snapshot();
+	}
+
+	restore(data: Uint8Array): Promise<void> {
+		return this.worker.restore(data);
+	}
+
+	clock_now(): Promise<ClockState> {
+		return this.worker.clock_now();
+	}
+
+	clock_freeze(): Promise<void> {
+		return this.worker.clock_freeze();
+	}
+
+	clock_resume(): Promise<void> {
+		return this.worker.clock_resume();
+	}
+
+	clock_set(date: Date): Promise<void> {
+		return this.worker.clock_set(date);
+	}
+
+	clock_advance(duration_ms: number): Promise<void> {
+		return this.worker.clock_advance(duration_ms);
+	}
+
+	set_seed(seed: number): Promise<void> {
+		return this.worker.set_seed(seed);
+	}
+
+	set_timezone(name: string): Promise<void> {
+		return this.worker.set_timezone(name);
+	}
+
+	put_files(files: Record<string, Uint8Array>): Promise<void> {
+		return this.worker.put_files(files);
+	}
+
+	remove_files(paths: string[]): Promise<void> {
+		return this.worker.remove_files(paths);
+	}
+
+	kv_export(): Promise<Uint8Array> {
+		return this.worker.kv_export();
+	}
+
+	kv_import(data: Uint8Array): Promise<void> {
+		return this.worker.kv_import(data);
+	}
+
+	intercept(interceptor: Interceptor): Promise<Interception> {
+		return ClientInterception.open(this.worker, interceptor);
+	}
+
+	async hold(pattern: string): Promise<Hold> {
+		const id = await this.worker.hold(pattern);
+		return new ClientHold(this.worker, id);
+	}
+
+	async inspect(): Promise<Inspection> {
+		const id = await this.worker.inspect();
+		return new ClientInspection(this.worker, id);
+	}
+
+	async panics(): Promise<Panics> {
+		const id = await this.worker.panics();
+		return new ClientPanics(this.worker, id);
+	}
 }
 
-export type OpenOption = {
+registerSerializer(errorSerializer);
+
+export type OpenOption = Omit<StartOption, "host"> & {
 	workerUrl?: string;
+	// Functions the bridge calls by `grpcwasm.CallHost`.
+	host?: HostFunctions;
 };
 
 export async function open(
@@ -45,6 +257,21 @@ export async function open(
 		});
 	}
 	const b = await spawn<BridgeWorker>(w);
-	await b.start(app);
+	const start: StartOption = {
+		args: option.args,
+		env: option.env,
+		config: option.config,
+		files: option.files,
+		urls: option.urls,
+		archives: option.archives,
+	};
+	const transfer: Transferable[] = [];
+	if (option.host !== undefined) {
+		const { port1, port2 } = new MessageChannel();
+		serveHost(port1, option.host);
+		start.host = { port: port2, names: Object.keys(option.host) };
+		transfer.push(port2);
+	}
+	await b.start(app, Transfer(start, transfer) as unknown as StartOption);
 	return new ClientSock(b);
 }
@@ -21,13 +21,18 @@ export interface BidiStreamingClient extends ClientStream {
 	close_send(): Promise<void>;
 }
 
+// Closes streams dropped without being closed so the bridge can reclaim them.
+const finalizer = new FinalizationRegistry<() => void>((close) => close());
+
 export class BidiStream implements BidiStreamingClient {
 	private close_work: Promise<void> | undefined;
 
 	constructor(
 		private worker: BridgeWorker,
 		private id: StreamId,
-	) {}
+	) {
+		finalizer.register(this, () => void worker.stream_close(id), this);
+	}
 
 	header(): Promise<Metadata> {
 		this.throwIfClosed();
@@ -75,6 +80,7 @@ export class BidiStream implements BidiStreamingClient {
 			return this.close_work;
 		}
 
+		finalizer.unregister(this);
 		this.close_work = this.worker.stream_close(this.id);
 		return this.close_work;
 	}
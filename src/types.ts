@@ -35,3 +35,196 @@ export type StreamFinalResult = {
 };
 
 export type StreamResult = StreamDataResult | StreamFinalResult;
+
+export type ActiveCall = {
+	id: number;
+	conn_id: number;
+	method: string;
+	type: "unary" | "client_stream" | "server_stream" | "bidi_stream";
+	// Metadata sent by the client.
+	meta: Metadata;
+	date_started: Date;
+	msg_sent: number;
+	msg_received: number;
+};
+
+export type Histogram = {
+	// Upper bounds in seconds with cumulative counts.
+	buckets: { le: number; count: number }[];
+	count: number;
+	sum: number;
+};
+
+export type MethodMetrics = {
+	method: string;
+	type: "unary" | "client_stream" | "server_stream" | "bidi_stream";
+	started: number;
+	// Keyed by the name of the status code, e.g. "OK", "NotFound".
+	handled: { [code: string]: number | undefined };
+	in_flight: number;
+	msg_received: number;
+	msg_sent: number;
+	bytes_received: number;
+	bytes_sent: number;
+	latency: Histogram;
+};
+
+export type Metrics = {
+	methods: MethodMetrics[];
+};
+
+export type InspectorMetadata = {
+	entries?: { [key: string]: { values?: string[] } | undefined };
+};
+
+export type InspectorCall = {
+	conn_id?: number;
+	call_id?: number;
+	method?: string;
+	client_stream?: boolean;
+	server_stream?: boolean;
+	// RFC 3339 timestamp.
+	date_started?: string;
+	header?: InspectorMetadata;
+	messages_sent?: number;
+	messages_received?: number;
+};
+
+// Protobuf JSON mapping of `grpcwasm.inspector.Event` with original field names.
+// Events are described from the point of view of the client.
+export type InspectorEvent = {
+	// RFC 3339 timestamp.
+	time: string;
+	conn_id?: number;
+	call_id?: number;
+
+	snapshot?: { calls?: InspectorCall[] };
+	conn_dialed?: {};
+	conn_closed?: {};
+	call_started?: { method?: string; client_stream?: boolean; server_stream?: boolean };
+	header_sent?: { header?: InspectorMetadata };
+	// `decoded` is set only if the descriptor of the message is available in the bridge.
+	message_sent?: { size?: number; decoded?: unknown };
+	header_received?: { header?: InspectorMetadata };
+	message_received?: { size?: number; decoded?: unknown };
+	trailer_received?: { trailer?: InspectorMetadata };
+	// Duration in protobuf JSON mapping, e.g. "0.001s".
+	call_finished?: { code?: number; message?: string; duration?: string };
+};
+
+export type InspectResult =
+	| {
+			done: false;
+			event: InspectorEvent;
+	  }
+	| {
+			done: true;
+	  };
+
+export type ProfileName = "heap" | "allocs" | "goroutine" | "block" | "mutex" | "threadcreate";
+
+export type ProfileOption = {
+	// 0 for gzipped protobuf format which can be opened by `go tool pprof`.
+	// Otherwise it is in legacy text format.
+	debug?: number;
+};
+
+export type MemoryOption = {
+	// Soft memory limit in bytes. Negative value leaves it unchanged.
+	limit?: number;
+	// GOGC. Negative value turns off GC.
+	gc_percent?: number;
+};
+
+export type MemoryStats = {
+	// Heap memory occupied by live objects that were marked by the previous GC.
+	heap_live: number;
+	// Memory occupied by live objects and dead objects that have not yet been freed.
+	heap_objects: number;
+	// All memory mapped by the Go runtime.
+	total: number;
+	goroutines: number;
+	gc_cycles: number;
+	// Distribution of stop-the-world pauses for GC in seconds.
+	gc_pauses: { count: number; p50: number; p99: number; max: number };
+	gc_percent: number;
+	memory_limit: number;
+};
+
+// Number of live objects the bridge exported to JS.
+export type Handles = {
+	// Go functions held by JS.
+	funcs: number;
+	conns: number;
+	streams: number;
+	inspections: number;
+	// Panic subscriptions.
+	panics: number;
+};
+
+// Clock of the bridge that handlers get by `grpcwasm.ClockFrom`.
+export type ClockState = {
+	now: Date;
+	frozen: boolean;
+};
+
+// Panic recovered in a handler of the bridge.
+export type PanicEvent = {
+	// Full method name of the call, e.g. "/echo.EchoService/Once".
+	method: string;
+	value: string;
+	stack: string;
+	time: Date;
+};
+
+export type PanicResult =
+	| {
+			done: false;
+			event: PanicEvent;
+	  }
+	| {
+			done: true;
+	  };
+
+// Traffic seen by an interceptor at the server boundary of the bridge.
+// Messages are in the protobuf binary format.
+export type InterceptEvent = {
+	method: string;
+	// "open" is the start of a streaming call, without payload.
+	// "request" is a message from the client and "response" is a message to the client.
+	kind: "open" | "request" | "response";
+	meta: Metadata;
+	payload: Uint8Array;
+};
+
+// Nothing to pass the event through, or what to replace.
+// A status other than OK fails the call.
+// Metadata can be replaced by "open" of streaming calls and "request" of unary calls.
+export type InterceptResult = void | {
+	payload?: Uint8Array;
+	meta?: Metadata;
+	status?: RpcStatus;
+};
+
+export type Interceptor = (e: InterceptEvent) => InterceptResult | Promise<InterceptResult>;
+
+// Call or stream message held by `Sock.hold` before it is delivered.
+// Messages are decoded into the protobuf JSON mapping with original field names.
+export type HeldCall = {
+	id: number;
+	method: string;
+	// "open" is the start of a streaming call, without message.
+	// "request" is a message from the client and "response" is a message to the client.
+	kind: "open" | "request" | "response";
+	meta: Metadata;
+	message?: unknown;
+};
+
+export type HoldResult =
+	| {
+			done: false;
+			call: HeldCall;
+	  }
+	| {
+			done: true;
+	  };
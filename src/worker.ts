@@ -2,10 +2,12 @@
 // Worker cannot be reused, means new worker should be initialized once it is closed.
 // Bridge is a WASM program which serves gRPC server.
 
-import { expose } from "threads/worker";
+import { expose, registerSerializer } from "threads/worker";
 
 import "./wasm_exec";
 import { Defer } from "./defer";
+import { GrpcWasmError, GrpcWasmTypeError, errorSerializer } from "./error";
+import { type HostFunctions, type HostPort, hostFunctions } from "./host";
 import { move } from "./move";
 import { Table } from "./table";
 import type * as types from "./types";
@@ -14,6 +16,31 @@ import type { Go } from "./wasm_exec";
 export type ConnId = number;
 export type CallId = number;
 export type StreamId = number;
+export type InspectionId = number;
+export type PanicsId = number;
+export type InterceptionId = number;
+export type HoldId = number;
+
+// Options the bridge runs with.
+export type StartOption = {
+	// Arguments following the program name in `os.Args`.
+	args?: string[];
+	// Environment variables seen by `os.Getenv`.
+	env?: Record<string, string>;
+	// Structured config decoded by `grpcwasm.LoadConfig`.
+	// It must survive `JSON.stringify`.
+	config?: unknown;
+	// Files read through `grpcwasm.Files`, keyed by slash-separated path.
+	// A string is encoded in UTF-8.
+	files?: Record<string, Uint8Array | string>;
+	// Files fetched from the URLs, keyed by the path as `files`.
+	urls?: Record<string, string>;
+	// Zip archives whose files are added as `files`.
+	// A string is the URL to fetch the archive from.
+	archives?: (Uint8Array | string)[];
+	// Port of the host functions served by the main thread.
+	host?: HostPort;
+};
 
 export type CallOption = {
 	meta?: types.Metadata;
@@ -25,9 +52,49 @@ export type InvokeResult = {
 };
 
 export type BridgeWorker = {
-	start(app: string | WebAssembly.Module): Promise<void>;
+	start(app: string | WebAssembly.Module, option?: StartOption): Promise<void>;
 	stop(): Promise<void>;
 	dial(): Promise<ConnId>;
+	metrics(): Promise<types.Metrics>;
+	metrics_text(): Promise<string>;
+	reset_metrics(): Promise<void>;
+	active_calls(): Promise<types.ActiveCall[]>;
+	cancel_call(id: number, status?: types.RpcStatus): Promise<void>;
+	profile(name: types.ProfileName, option?: types.ProfileOption): Promise<Uint8Array>;
+	trace(duration_ms: number): Promise<Uint8Array>;
+	set_memory(option: types.MemoryOption): Promise<types.MemoryOption>;
+	gc(): Promise<void>;
+	memory_stats(): Promise<types.MemoryStats>;
+	handles(): Promise<types.Handles>;
+	reset(): Promise<void>;
+	snapshot(): Promise<Uint8Array>;
+	restore(data: Uint8Array): Promise<void>;
+	clock_now(): Promise<types.ClockState>;
+	clock_freeze(): Promise<void>;
+	clock_resume(): Promise<void>;
+	clock_set(date: Date): Promise<void>;
+	clock_advance(duration_ms: number): Promise<void>;
+	set_seed(seed: number): Promise<void>;
+	set_timezone(name: string): Promise<void>;
+	put_files(files: Record<string, Uint8Array>): Promise<void>;
+	remove_files(paths: string[]): Promise<void>;
+	kv_export(): Promise<Uint8Array>;
+	kv_import(data: Uint8Array): Promise<void>;
+	intercept(port: MessagePort): Promise<InterceptionId>;
+	intercept_close(id: InterceptionId): Promise<void>;
+	hold(pattern: string): Promise<HoldId>;
+	hold_held(id: HoldId): Promise<types.HeldCall[]>;
+	hold_recv(id: HoldId): Promise<types.HoldResult>;
+	hold_release(id: HoldId, call: number): Promise<void>;
+	hold_release_all(id: HoldId): Promise<void>;
+	hold_fail(id: HoldId, call: number, status: types.RpcStatus): Promise<void>;
+	hold_close(id: HoldId): Promise<void>;
+	inspect(): Promise<InspectionId>;
+	inspect_recv(id: InspectionId): Promise<types.InspectResult>;
+	inspect_close(id: InspectionId): Promise<void>;
+	panics(): Promise<PanicsId>;
+	panics_recv(id: PanicsId): Promise<types.PanicResult>;
+	panics_close(id: PanicsId): Promise<void>;
 	close(id: ConnId): Promise<void>;
 	invoke(id: ConnId, method: string, req: Uint8Array, option: CallOption): Promise<CallId>;
 	recv(id: CallId): Promise<types.RpcResult>;
@@ -45,10 +112,62 @@ export type BridgeWorker = {
 interface Socket {
 	close(): void;
 	dial(): Promise<Conn>;
+	metrics(): Promise<types.Metrics>;
+	metrics_text(): Promise<string>;
+	reset_metrics(): Promise<void>;
+	inspect(): Promise<Inspection>;
+	active_calls(): Promise<types.ActiveCall[]>;
+	cancel_call(id: number, status?: types.RpcStatus): Promise<void>;
+	profile(name: types.ProfileName, option?: types.ProfileOption): Promise<Uint8Array>;
+	trace(duration_ms: number): Promise<Uint8Array>;
+	set_memory(option: types.MemoryOption): Promise<types.MemoryOption>;
+	gc(): Promise<void>;
+	memory_stats(): Promise<types.MemoryStats>;
+	handles(): Promise<types.Handles>;
+	panics(): Promise<Panics>;
+	reset(): Promise<void>;
+	snapshot(): Promise<Uint8Array>;
+	restore(data: Uint8Array): Promise<void>;
+	clock_now(): Promise<types.ClockState>;
+	clock_freeze(): Promise<void>;
+	clock_resume(): Promise<void>;
+	clock_set(date: Date): Promise<void>;
+	clock_advance(duration_ms: number): Promise<void>;
+	set_seed(seed: number): Promise<void>;
+	set_timezone(name: string): Promise<void>;
+	put_files(files: Record<string, Uint8Array>): Promise<void>;
+	remove_files(paths: string[]): Promise<void>;
+	kv_export(): Promise<Uint8Array>;
+	kv_import(data: Uint8Array): Promise<void>;
+	intercept(interceptor: types.Interceptor): Promise<Interception>;
+	hold(pattern: string): Promise<Hold>;
 }
 
+type Inspection = {
+	recv(): Promise<types.InspectResult>;
+	close(): Promise<void>;
+};
+
+type Panics = {
+	recv(): Promise<types.PanicResult>;
+	close(): Promise<void>;
+};
+
+type Interception = {
+	close(): Promise<void>;
+};
+
+type Hold = {
+	held(): Promise<types.HeldCall[]>;
+	recv(): Promise<types.HoldResult>;
+	release(id: number): Promise<void>;
+	release_all(): Promise<void>;
+	fail(id: number, status: types.RpcStatus): Promise<void>;
+	close(): Promise<void>;
+};
+
 type InvokeOption = CallOption & {
-	abort_request?: Promise<void>;
+	signal?: AbortSignal;
 };
 
 type Conn = {
@@ -82,13 +201,55 @@ type Bridge = {
 };
 
 // Bridge will settle the grpc_wasm.
+// Bridge rejects with GrpcWasmError and GrpcWasmTypeError given here.
 declare global {
 	var grpc_wasm: Defer<Socket> | undefined;
+	var grpc_wasm_config: unknown;
+	var grpc_wasm_files: Record<string, Uint8Array> | undefined;
+	var grpc_wasm_archives: Uint8Array[] | undefined;
+	var grpc_wasm_host: HostFunctions | undefined;
+	var GrpcWasmError: unknown;
+	var GrpcWasmTypeError: unknown;
 }
 globalThis.grpc_wasm = undefined;
+globalThis.GrpcWasmError = GrpcWasmError;
+globalThis.GrpcWasmTypeError = GrpcWasmTypeError;
+registerSerializer(errorSerializer);
+
+async function fetchBytes(url: string): Promise<Uint8Array> {
+	const res = await fetch(url);
+	if (!res.ok) {
+		throw new Error(`fetch ${url}: ${res.status} ${res.statusText}`);
+	}
+	return new Uint8Array(await res.arrayBuffer());
+}
+
+async function loadFiles(option: StartOption): Promise<void> {
+	const enc = new TextEncoder();
+	const files: Record<string, Uint8Array> = {};
+	for (const [name, data] of Object.entries(option.files ?? {})) {
+		files[name] = typeof data === "string" ? enc.encode(data) : data;
+	}
+	await Promise.all(
+		Object.entries(option.urls ?? {}).map(async ([name, url]) => {
+			files[name] = await fetchBytes(url);
+		}),
+	);
+	const archives = await Promise.all(
+		(option.archives ?? []).map((v) => (typeof v === "string" ? fetchBytes(v) : v)),
+	);
+
+	globalThis.grpc_wasm_files = files;
+	globalThis.grpc_wasm_archives = archives;
+}
 
-async function init(app: string | WebAssembly.Module): Promise<Bridge> {
+async function init(app: string | WebAssembly.Module, option: StartOption): Promise<Bridge> {
 	const go = new globalThis.Go();
+	go.argv = [...go.argv, ...(option.args ?? [])];
+	go.env = { ...go.env, ...option.env };
+	globalThis.grpc_wasm_config = option.config;
+	await loadFiles(option);
+	globalThis.grpc_wasm_host = option.host === undefined ? undefined : hostFunctions(option.host);
 
 	let m: WebAssembly.Module;
 	if (app instanceof WebAssembly.Module) {
@@ -142,9 +303,13 @@ const ready = new Defer<Bridge>();
 const conns = new Table<ConnId, Conn>();
 const calls = new Table<CallId, Call>();
 const streams = new Table<StreamId, Stream>();
+const inspections = new Table<InspectionId, Inspection>();
+const panics = new Table<PanicsId, Panics>();
+const interceptions = new Table<InterceptionId, Interception>();
+const holds = new Table<HoldId, Hold>();
 
 expose({
-	start(app: string | WebAssembly.Module): Promise<void> {
+	start(app: string | WebAssembly.Module, option: StartOption = {}): Promise<void> {
 		if (isStopped()) {
 			throw new Error("bridge closed");
 		}
@@ -153,7 +318,7 @@ expose({
 			return start_work;
 		}
 
-		start_work = init(app).then(
+		start_work = init(app, option).then(
 			(ctx) => ready.resolve(ctx),
 			(err) => {
 				ready.reject(err);
@@ -194,6 +359,177 @@ expose({
 
 		return conns.add(conn);
 	},
+	async metrics() {
+		const { sock } = await ready;
+		return sock.metrics();
+	},
+	async metrics_text() {
+		const { sock } = await ready;
+		return sock.metrics_text();
+	},
+	async reset_metrics() {
+		const { sock } = await ready;
+		return sock.reset_metrics();
+	},
+	async active_calls() {
+		const { sock } = await ready;
+		return sock.active_calls();
+	},
+	async cancel_call(id, status) {
+		const { sock } = await ready;
+		return sock.cancel_call(id, status);
+	},
+	async profile(name, option) {
+		const { sock } = await ready;
+		const v = await sock.profile(name, option);
+		return move(v, [v.buffer]);
+	},
+	async trace(duration_ms) {
+		const { sock } = await ready;
+		const v = await sock.trace(duration_ms);
+		return move(v, [v.buffer]);
+	},
+	async set_memory(option) {
+		const { sock } = await ready;
+		return sock.set_memory(option);
+	},
+	async gc() {
+		const { sock } = await ready;
+		return sock.gc();
+	},
+	async memory_stats() {
+		const { sock } = await ready;
+		return sock.memory_stats();
+	},
+	async handles() {
+		const { sock } = await ready;
+		return sock.handles();
+	},
+	async reset() {
+		const { sock } = await ready;
+		return sock.reset();
+	},
+	async snapshot() {
+		const { sock } = await ready;
+		return sock.snapshot();
+	},
+	async restore(data) {
+		const { sock } = await ready;
+		return sock.restore(data);
+	},
+	async clock_now() {
+		const { sock } = await ready;
+		return sock.clock_now();
+	},
+	async clock_freeze() {
+		const { sock } = await ready;
+		return sock.clock_freeze();
+	},
+	async clock_resume() {
+		const { sock } = await ready;
+		return sock.clock_resume();
+	},
+	async clock_set(date) {
+		const { sock } = await ready;
+		return sock.clock_set(date);
+	},
+	async clock_advance(duration_ms) {
+		const { sock } = await ready;
+		return sock.clock_advance(duration_ms);
+	},
+	async set_seed(seed) {
+		const { sock } = await ready;
+		return sock.set_seed(seed);
+	},
+	async set_timezone(name) {
+		const { sock } = await ready;
+		return sock.set_timezone(name);
+	},
+	async put_files(files) {
+		const { sock } = await ready;
+		return sock.put_files(files);
+	},
+	async remove_files(paths) {
+		const { sock } = await ready;
+		return sock.remove_files(paths);
+	},
+	async kv_export() {
+		const { sock } = await ready;
+		return sock.kv_export();
+	},
+	async kv_import(data) {
+		const { sock } = await ready;
+		return sock.kv_import(data);
+	},
+	async intercept(port) {
+		const { sock } = await ready;
+		const { intercept } = hostFunctions({ port, names: ["intercept"] });
+		const interception = await sock.intercept(intercept as types.Interceptor);
+
+		return interceptions.add(interception);
+	},
+	async intercept_close(id) {
+		const interception = interceptions.delete(id);
+		return interception?.close();
+	},
+	async hold(pattern) {
+		const { sock } = await ready;
+		const hold = await sock.hold(pattern);
+
+		return holds.add(hold);
+	},
+	hold_held(id) {
+		const hold = holds.must(id);
+		return hold.held();
+	},
+	hold_recv(id) {
+		const hold = holds.must(id);
+		return hold.recv();
+	},
+	hold_release(id, call) {
+		const hold = holds.must(id);
+		return hold.release(call);
+	},
+	hold_release_all(id) {
+		const hold = holds.must(id);
+		return hold.release_all();
+	},
+	hold_fail(id, call, status) {
+		const hold = holds.must(id);
+		return hold.fail(call, status);
+	},
+	async hold_close(id) {
+		const hold = holds.delete(id);
+		return hold?.close();
+	},
+	async inspect() {
+		const { sock } = await ready;
+		const inspection = await sock.inspect();
+
+		return inspections.add(inspection);
+	},
+	inspect_recv(id) {
+		const inspection = inspections.must(id);
+		return inspection.recv();
+	},
+	async inspect_close(id) {
+		const inspection = inspections.delete(id);
+		return inspection?.close();
+	},
+	async panics() {
+		const { sock } = await ready;
+		const sub = await sock.panics();
+
+		return panics.add(sub);
+	},
+	panics_recv(id) {
+		const sub = panics.must(id);
+		return sub.recv();
+	},
+	async panics_close(id) {
+		const sub = panics.delete(id);
+		return sub?.close();
+	},
 	async close(id: ConnId): Promise<void> {
 		const conn = conns.delete(id);
 		return conn?.close();
@@ -201,11 +537,11 @@ expose({
 	invoke(id: ConnId, method: string, req: Uint8Array, option): Promise<CallId> {
 		const conn = conns.must(id);
 
-		const abort_request = new Defer<void>();
-		const cancel = () => abort_request.resolve();
+		const ac = new AbortController();
+		const cancel = () => ac.abort();
 		const result = conn.invoke(method, req, {
 			meta: option.meta,
-			abort_request,
+			signal: ac.signal,
 		});
 		result.finally(() => {
 			cancel();
@@ -216,7 +552,7 @@ expose({
 	},
 	async recv(id) {
 		const call = calls.must(id);
-		const v = await call.result;
+		const v = await call.result.finally(() => calls.delete(id));
 		return move(v, [v.response.buffer]);
 	},
 	cancel(id) {
@@ -0,0 +1,78 @@
+package grpcwasm
+
+import (
+	"context"
+
+	"github.com/lesomnus/grpc-wasm/internal/js"
+	"github.com/lesomnus/grpc-wasm/internal/jz"
+	"github.com/lesomnus/grpc-wasm/kv"
+)
+
+type kvKey struct{}
+
+// ContextWithKV returns a context whose [KVFrom] is given store.
+func ContextWithKV(ctx context.Context, s *kv.Store) context.Context {
+	return context.WithValue(ctx, kvKey{}, s)
+}
+
+// KVFrom returns the store set by [ContextWithKV], or the store of the bridge
+// the call came from if it is a context of a handler.
+// It returns nil if neither is found.
+func KVFrom(ctx context.Context) *kv.Store {
+	if s, ok := ctx.Value(kvKey{}).(*kv.Store); ok {
+		return s
+	}
+	if l := listenerFrom(ctx); l != nil {
+		return l.kv_store
+	}
+	return nil
+}
+
+// WithKV sets the store handlers get by [KVFrom], which the JS side can export and import.
+// By default, it is a store in memory.
+func WithKV(s *kv.Store) ListenOption {
+	return func(l *Listener) {
+		l.kv_store = s
+	}
+}
+
+// JsKVExport returns every entry of the store set by [WithKV] in the format of [kv.Store.Snapshot].
+//
+// Signature:
+//
+//	function(): Promise<Uint8Array>;
+func (l *Listener) JsKVExport(this js.Value, args []js.Value) any {
+	return l.scope.Promise(func() (js.Value, js.Value) {
+		data, err := l.kv_store.Snapshot()
+		if err != nil {
+			return js.Undefined(), jz.ToError(err)
+		}
+
+		return jz.BytesToJs(data), js.Undefined()
+	})
+}
+
+var kvImportParams = jz.Params{
+	{Name: "data", Kind: jz.KindUint8Array},
+}
+
+// JsKVImport replaces every entry of the store set by [WithKV]
+// with the ones exported by [Listener.JsKVExport].
+//
+// Signature:
+//
+//	function(data: Uint8Array): Promise<void>;
+func (l *Listener) JsKVImport(this js.Value, args []js.Value) any {
+	if err := kvImportParams.Check(args); err != nil {
+		return jz.Reject(jz.ToError(err))
+	}
+
+	data := jz.BytesToGo(args[0])
+	return l.scope.Promise(func() (js.Value, js.Value) {
+		if err := l.kv_store.Import(l.ctx, data); err != nil {
+			return js.Undefined(), jz.ToError(err)
+		}
+
+		return js.Undefined(), js.Undefined()
+	})
+}
@@ -0,0 +1,96 @@
+package grpcwasm_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	grpcwasm "github.com/lesomnus/grpc-wasm"
+	"github.com/lesomnus/grpc-wasm/internal/echo"
+	"github.com/lesomnus/grpc-wasm/internal/js"
+	"github.com/lesomnus/grpc-wasm/internal/jz"
+	"github.com/lesomnus/grpc-wasm/kv"
+	"github.com/stretchr/testify/require"
+)
+
+// counterServer counts the calls in the store of the bridge.
+type counterServer struct {
+	echo.UnimplementedEchoServiceServer
+}
+
+func (counterServer) Once(ctx context.Context, req *echo.EchoRequest) (*echo.EchoResponse, error) {
+	s := grpcwasm.KVFrom(ctx)
+	err := s.Update(ctx, func(tx *kv.Tx) error {
+		v, err := tx.Get("count")
+		if err != nil && !errors.Is(err, kv.ErrNotFound) {
+			return err
+		}
+		return tx.Put("count", append(v, '+'))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := s.Get(ctx, "count")
+	if err != nil {
+		return nil, err
+	}
+	res := &echo.EchoResponse{}
+	res.SetMessage(string(v))
+	return res, nil
+}
+
+func TestKVFrom(t *testing.T) {
+	x := require.New(t)
+
+	x.Nil(grpcwasm.KVFrom(context.Background()))
+
+	s := kv.NewMemory()
+	x.Same(s, grpcwasm.KVFrom(grpcwasm.ContextWithKV(context.Background(), s)))
+}
+
+func TestListener_JsKV(t *testing.T) {
+	x := require.New(t)
+	ctx := t.Context()
+
+	store := kv.NewMemory()
+	l := grpcwasm.NewListener(grpcwasm.WithKV(store))
+	defer l.Close()
+
+	s := grpcwasm.NewServer()
+	echo.RegisterEchoServiceServer(s, counterServer{})
+	go s.Serve(l)
+	defer s.Stop()
+
+	conn, err := l.Dial()
+	x.NoError(err)
+	defer conn.Close()
+
+	count := func() string {
+		v, err_js := jsInvoke(x, conn, echo.EchoService_Once_FullMethodName, &echo.EchoRequest{}, nil)
+		x.True(err_js.IsUndefined())
+
+		res := echo.EchoResponse{}
+		x.NoError(protoUnmarshal(v.Get("response"), &res))
+		return res.GetMessage()
+	}
+	x.Equal("+", count())
+
+	sock := l.ToJsValue()
+	data, err_js := jz.Await(sock.Call("kv_export"))
+	x.True(err_js.IsUndefined())
+
+	x.Equal("++", count())
+	v, err := store.Get(ctx, "count")
+	x.NoError(err)
+	x.Equal("++", string(v))
+
+	_, err_js = jz.Await(sock.Call("kv_import", data))
+	x.True(err_js.IsUndefined())
+	x.Equal("++", count())
+
+	_, err_js = jz.Await(sock.Call("kv_import", js.ValueOf("Lebowski")))
+	x.Equal("argument", err_js.Get("kind").String())
+	_, err_js = jz.Await(sock.Call("kv_import", jz.BytesToJs([]byte("Lebowski"))))
+	x.Contains(err_js.Get("message").String(), "not a kv dump")
+}
@@ -1,49 +1,143 @@
-//go:build js && wasm
-
 package grpcwasm
 
 import (
 	"context"
 	"errors"
 	"io"
-	"syscall/js"
+	"sync"
+	"time"
 
+	"github.com/lesomnus/grpc-wasm/internal/js"
 	"github.com/lesomnus/grpc-wasm/internal/jz"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
 type Stream struct {
 	grpc.ClientStream
 
-	scope *jz.Scope
+	scope   *jz.Scope
+	call    *activeCall
+	handles *handleCounts
 
 	ctx    context.Context
 	cancel context.CancelFunc
+
+	// Set once the stream is exported to JS.
+	obj *jz.Object
+
+	mu sync.Mutex
+	// Number of JS calls in progress.
+	busy int
+	// Reclaims the stream if JS does not touch it for a while.
+	idle_timeout time.Duration
+	idle_timer   *time.Timer
+	released     bool
 }
 
 func NewStream(ctx context.Context, conn *Conn, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (*Stream, error) {
-	ctx, cancel := context.WithCancel(ctx)
+	ctx, call := conn.calls.add(ctx, conn.id, method, methodTypeOf(desc.ClientStreams, desc.ServerStreams))
+	cancel := func() {
+		call.cancel(nil)
+	}
 	opts = append(opts, grpc.OnFinish(func(err error) {
 		cancel()
+		conn.calls.remove(call)
 	}))
 
 	s, err := conn.NewStream(ctx, desc, method, opts...)
 	if err != nil {
+		cancel()
+		conn.calls.remove(call)
 		return nil, err
 	}
 
 	return &Stream{
 		ClientStream: s,
 
-		scope: conn.scope,
+		scope:   conn.scope,
+		call:    call,
+		handles: conn.handles,
 
 		ctx:    ctx,
 		cancel: cancel,
+
+		idle_timeout: conn.stream_idle_timeout,
 	}, nil
 }
 
+// enter marks a JS call in progress and returns a function that unmarks it.
+// The idle timer runs only while no JS call is in progress.
+func (s *Stream) enter() func() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.busy++
+	if s.idle_timer != nil {
+		s.idle_timer.Stop()
+	}
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		s.busy--
+		if s.busy == 0 && s.idle_timer != nil {
+			s.idle_timer.Reset(s.idle_timeout)
+		}
+	}
+}
+
+func (s *Stream) onIdle() {
+	s.mu.Lock()
+	busy := s.busy
+	s.mu.Unlock()
+	if busy > 0 {
+		return
+	}
+
+	s.release()
+}
+
+// release cancels the stream and releases its JS functions.
+func (s *Stream) release() {
+	s.cancel()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.released {
+		return
+	}
+	s.released = true
+
+	if s.idle_timer != nil {
+		s.idle_timer.Stop()
+	}
+	if s.obj != nil {
+		s.obj.Release()
+		s.handles.streams.Add(-1)
+	}
+}
+
+func (s *Stream) SendMsg(m any) error {
+	if err := s.ClientStream.SendMsg(m); err != nil {
+		return err
+	}
+
+	s.call.sent()
+	return nil
+}
+
+func (s *Stream) RecvMsg(m any) error {
+	if err := s.ClientStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	s.call.received()
+	return nil
+}
+
 // Signature:
 //
 //	type Metadata = {
@@ -51,17 +145,22 @@ func NewStream(ctx context.Context, conn *Conn, desc *grpc.StreamDesc, method st
 //	}
 //	function(): Promise<Metadata>
 func (s *Stream) JsHeader(this js.Value, args []js.Value) any {
+	exit := s.enter()
 	return s.scope.Promise(func() (js.Value, js.Value) {
+		defer exit()
+
 		md, err := s.Header()
 		if err != nil {
 			return js.Undefined(), jz.ToError(err)
 		}
-		if md == nil {
-			// the stream was terminated without header.
-			// TODO: reject?
-			md = metadata.MD{}
+		// Nil header, i.e. the stream was terminated without header,
+		// is marshaled into an empty object.
+		// TODO: reject?
+		v, err := jz.Marshal(rpcMeta(md))
+		if err != nil {
+			return js.Undefined(), jz.ToError(err)
 		}
-		return metaToJs(md), js.Undefined()
+		return v, js.Undefined()
 	})
 }
 
@@ -86,43 +185,68 @@ func (s *Stream) JsHeader(this js.Value, args []js.Value) any {
 //		}
 //	function(): Promise<StreamResult>
 func (s *Stream) JsRecv(this js.Value, args []js.Value) any {
+	exit := s.enter()
 	return s.scope.Promise(func() (js.Value, js.Value) {
+		defer exit()
+
 		data := []byte{}
 		err := s.RecvMsg(&data)
 		if err == nil {
-			return js.ValueOf(map[string]any{
-				"done":     js.ValueOf(false),
-				"response": jz.BytesToJs(data),
-			}), js.Undefined()
+			if data == nil {
+				data = []byte{}
+			}
+			v, err := jz.Marshal(streamResult{Response: data})
+			if err != nil {
+				return js.Undefined(), jz.ToError(err)
+			}
+			return v, js.Undefined()
 		}
 
 		st := status.Status{}
 		eof := errors.Is(err, io.EOF)
 		if !eof {
-			s, ok := status.FromError(err)
+			s_, ok := status.FromError(err)
 			if !ok {
-				return js.Undefined(), js.ValueOf(err.Error())
+				return js.Undefined(), jz.NewError(err, s.Trailer())
+			}
+			if v, ok := cancelCause(s.ctx); ok {
+				s_ = v
 			}
 
-			st = *s
+			st = *s_
 		}
 
-		md := s.Trailer()
-		return js.ValueOf(map[string]any{
-			"done":    js.ValueOf(true),
-			"trailer": metaToJs(md),
-			"status":  statusToJs(&st),
-		}), js.Undefined()
+		md := rpcMeta(s.Trailer())
+		rs := newRpcStatus(&st)
+		v, err := jz.Marshal(streamResult{
+			Done:    true,
+			Trailer: &md,
+			Status:  &rs,
+		})
+		if err != nil {
+			return js.Undefined(), jz.ToError(err)
+		}
+		return v, js.Undefined()
 	})
 }
 
+var sendParams = jz.Params{
+	{Name: "req", Kind: jz.KindUint8Array},
+}
+
 // Signature:
 //
 //	function(req: Uint8Array): Promise<void>
 func (s *Stream) JsSend(this js.Value, args []js.Value) any {
+	if err := sendParams.Check(args); err != nil {
+		return jz.Reject(jz.ToError(err))
+	}
+
+	data := jz.BytesToGo(args[0])
+	exit := s.enter()
 	return s.scope.Promise(func() (js.Value, js.Value) {
-		req := args[0]
-		data := jz.BytesToGo(req)
+		defer exit()
+
 		if err := s.SendMsg(data); err != nil {
 			return js.Undefined(), jz.ToError(err)
 		}
@@ -134,6 +258,7 @@ func (s *Stream) JsSend(this js.Value, args []js.Value) any {
 //
 //	function(): Promise<void>
 func (s *Stream) JsCloseSend(this js.Value, args []js.Value) any {
+	defer s.enter()()
 	if err := s.CloseSend(); err != nil {
 		return jz.Reject(jz.ToError(err))
 	}
@@ -144,16 +269,46 @@ func (s *Stream) JsCloseSend(this js.Value, args []js.Value) any {
 //
 //	function(): Promise<void>
 func (s *Stream) JsClose(this js.Value, args []js.Value) any {
-	s.cancel()
+	s.release()
 	return jz.Resolve(js.Undefined())
 }
 
-func (s *Stream) ToJs() js.Value {
-	return js.ValueOf(map[string]any{
-		"header":     js.FuncOf(s.JsHeader),
-		"recv":       js.FuncOf(s.JsRecv),
-		"send":       js.FuncOf(s.JsSend),
-		"close_send": js.FuncOf(s.JsCloseSend),
-		"close":      js.FuncOf(s.JsClose),
+// closedStreamResult is what recv resolves with once the stream is closed.
+func closedStreamResult() js.Value {
+	md := rpcMeta{}
+	rs := newRpcStatus(status.New(codes.Canceled, "stream is closed"))
+	v, _ := jz.Marshal(streamResult{
+		Done:    true,
+		Trailer: &md,
+		Status:  &rs,
 	})
+	return v
+}
+
+// ToJs exports the stream to JS.
+// The stream is cancelled and its functions are released when JS closes it,
+// when JS drops it without closing, or when JS does not touch it
+// for the idle timeout set by [WithStreamIdleTimeout].
+func (s *Stream) ToJs() js.Value {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.obj != nil {
+		return s.obj.Value()
+	}
+
+	s.obj = s.scope.Object().
+		Method("header", s.JsHeader).
+		Method("recv", s.JsRecv).
+		Method("send", s.JsSend).
+		Method("close_send", s.JsCloseSend).
+		Method("close", s.JsClose).
+		Fallback("recv", closedStreamResult()).
+		OnCollected(s.release)
+	s.handles.streams.Add(1)
+	if s.idle_timeout > 0 {
+		s.idle_timer = time.AfterFunc(s.idle_timeout, s.onIdle)
+	}
+
+	return s.obj.Value()
 }
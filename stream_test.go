@@ -1,15 +1,13 @@
-//go:build js && wasm
-
 package grpcwasm_test
 
 import (
 	"context"
-	"syscall/js"
 	"testing"
 	"time"
 
 	grpcwasm "github.com/lesomnus/grpc-wasm"
 	"github.com/lesomnus/grpc-wasm/internal/echo"
+	"github.com/lesomnus/grpc-wasm/internal/js"
 	"github.com/lesomnus/grpc-wasm/internal/jz"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc/codes"
@@ -48,6 +46,34 @@ func TestConn_JsBidiStream(t *testing.T) {
 		x.True(err_js.IsUndefined())
 		x.Equal(int(codes.Canceled), v.Get("status").Get("code").Int())
 	}))
+	t.Run("send wrong type", withConn(func(ctx context.Context, x *require.Assertions, conn *grpcwasm.Conn) {
+		stream, err_js := jz.Await(conn.JsOpenBidiStream(js.Undefined(), []js.Value{
+			js.ValueOf(echo.EchoService_Live_FullMethodName),
+		}).(js.Value))
+		x.True(err_js.IsUndefined())
+		defer stream.Call("close")
+
+		_, err_js = jz.Await(stream.Call("send", "Lebowski"))
+		x.True(err_js.InstanceOf(js.Global().Get("TypeError")))
+	}))
+	t.Run("abort", withConn(func(ctx context.Context, x *require.Assertions, conn *grpcwasm.Conn) {
+		ac := js.Global().Get("AbortController").New()
+		stream, err_js := jz.Await(conn.JsOpenBidiStream(js.Undefined(), []js.Value{
+			js.ValueOf(echo.EchoService_Live_FullMethodName),
+			js.ValueOf(map[string]any{
+				"signal": ac.Get("signal"),
+			}),
+		}).(js.Value))
+		x.True(err_js.IsUndefined())
+
+		p := stream.Call("recv")
+		time.Sleep(10 * time.Millisecond)
+		ac.Call("abort")
+
+		v, err_js := jz.Await(p)
+		x.True(err_js.IsUndefined())
+		x.Equal(int(codes.Canceled), v.Get("status").Get("code").Int())
+	}))
 	t.Run("close send", withConn(func(ctx context.Context, x *require.Assertions, conn *grpcwasm.Conn) {
 		// Graceful close on both side.
 		stream, err_js := jz.Await(conn.JsOpenBidiStream(js.Undefined(), []js.Value{
@@ -0,0 +1,329 @@
+package grpcwasm
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// webStreamDesc describes any method; the handler does not need to know its kind.
+var webStreamDesc = &grpc.StreamDesc{ServerStreams: true, ClientStreams: true}
+
+// maxWebMessageSize is the size limit of a message sent over HTTP,
+// same as the default one of the gRPC server.
+const maxWebMessageSize = 4 << 20
+
+// webHandler serves the calls of gRPC-Web clients made over HTTP
+// by forwarding them to given connection.
+// Only the binary format is supported, which Connect clients also speak through their gRPC-Web transport.
+// Any origin is allowed.
+// Compressed messages are rejected with Unimplemented status.
+// Handlers see the in-process connection as the peer, not the HTTP client.
+//
+// It is written here to keep the dependencies of the module to grpc-go and protobuf,
+// which every bridge pulls in; improbable-eng/grpc-web, the usual wrapper of grpc.Server,
+// is archived.
+type webHandler struct {
+	conn *grpc.ClientConn
+}
+
+func (h *webHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	allowCORS(w, r)
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST, OPTIONS")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	media, _, _ := strings.Cut(r.Header.Get("Content-Type"), ";")
+	media = strings.ToLower(strings.TrimSpace(media))
+	switch media {
+	case "application/grpc-web", "application/grpc-web+proto":
+		h.serve(w, r, media)
+	default:
+		http.Error(w, "unsupported content type", http.StatusUnsupportedMediaType)
+	}
+}
+
+func allowCORS(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Origin") == "" {
+		return
+	}
+
+	h := w.Header()
+	h.Set("Access-Control-Allow-Origin", "*")
+	h.Set("Access-Control-Expose-Headers", "*")
+	if r.Method == http.MethodOptions {
+		h.Set("Access-Control-Allow-Methods", "POST")
+		h.Set("Access-Control-Allow-Headers", r.Header.Get("Access-Control-Request-Headers"))
+		h.Set("Access-Control-Max-Age", "7200")
+	}
+}
+
+// serve makes the call of given request and writes the response messages in frames
+// prefixed by flags and length, ending with a frame of the status and the trailer.
+func (h *webHandler) serve(w http.ResponseWriter, r *http.Request, media string) {
+	rc := http.NewResponseController(w)
+	rc.EnableFullDuplex()
+
+	header_sent := false
+	send_header := func(md metadata.MD) {
+		if header_sent {
+			return
+		}
+		header_sent = true
+		writeMetadata(w.Header(), md)
+		w.Header().Set("Content-Type", media)
+		w.WriteHeader(http.StatusOK)
+	}
+	send := func(flags byte, data []byte) error {
+		b := make([]byte, 5, 5+len(data))
+		b[0] = flags
+		binary.BigEndian.PutUint32(b[1:], uint32(len(data)))
+		b = append(b, data...)
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+		return rc.Flush()
+	}
+
+	trailer, err := h.stream(r, send_header, func(msg []byte) error {
+		return send(0x00, msg)
+	})
+	send_header(nil)
+	send(0x80, grpcWebTrailer(trailer, err))
+}
+
+// stream makes the call of given request sending the messages read from its body,
+// and passes the response header and messages to given functions.
+// It returns the trailer and the error of the call.
+func (h *webHandler) stream(r *http.Request, on_header func(metadata.MD), on_msg func([]byte) error) (metadata.MD, error) {
+	ctx, cancel, err := callContext(r)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	cs, err := h.conn.NewStream(ctx, webStreamDesc, r.URL.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	bad_req := make(chan error, 1)
+	go func() {
+		for {
+			msg, err := readEnvelope(r.Body)
+			if err != nil {
+				if errors.Is(err, io.EOF) {
+					cs.CloseSend()
+				} else {
+					bad_req <- err
+					cancel()
+				}
+				return
+			}
+			if err := cs.SendMsg(msg); err != nil {
+				return
+			}
+		}
+	}()
+
+	if header, err := cs.Header(); err == nil {
+		on_header(header)
+	}
+	for {
+		var msg []byte
+		err := cs.RecvMsg(&msg)
+		if err == nil {
+			if err := on_msg(msg); err != nil {
+				// Client is gone.
+				return nil, status.FromContextError(context.Canceled).Err()
+			}
+			continue
+		}
+
+		select {
+		case err = <-bad_req:
+		default:
+		}
+		if errors.Is(err, io.EOF) {
+			err = nil
+		}
+		return cs.Trailer(), err
+	}
+}
+
+// readEnvelope reads a message prefixed by flags and length.
+// It returns io.EOF only if there is no more message.
+func readEnvelope(r io.Reader) ([]byte, error) {
+	prefix := [5]byte{}
+	if _, err := io.ReadFull(r, prefix[:]); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, status.Error(codes.InvalidArgument, "truncated message prefix")
+		}
+		return nil, err
+	}
+	if prefix[0]&0x01 != 0 {
+		return nil, status.Error(codes.Unimplemented, "compressed messages are not supported")
+	}
+
+	n := binary.BigEndian.Uint32(prefix[1:])
+	if n > maxWebMessageSize {
+		return nil, status.Errorf(codes.ResourceExhausted, "message larger than max (%d vs. %d)", n, maxWebMessageSize)
+	}
+	msg := make([]byte, n)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "truncated message")
+	}
+	return msg, nil
+}
+
+func grpcWebTrailer(trailer metadata.MD, err error) []byte {
+	s := status.Convert(err)
+
+	b := &bytes.Buffer{}
+	fmt.Fprintf(b, "grpc-status: %d\r\n", s.Code())
+	if msg := s.Message(); msg != "" {
+		fmt.Fprintf(b, "grpc-message: %s\r\n", percentEncode(msg))
+	}
+	if len(s.Details()) > 0 {
+		if details, err := proto.Marshal(s.Proto()); err == nil {
+			fmt.Fprintf(b, "grpc-status-details-bin: %s\r\n", base64.RawStdEncoding.EncodeToString(details))
+		}
+	}
+	for k, vs := range trailer {
+		for _, v := range vs {
+			fmt.Fprintf(b, "%s: %s\r\n", k, encodeMetadataValue(k, v))
+		}
+	}
+	return b.Bytes()
+}
+
+// percentEncode encodes the message as grpc-message header does.
+func percentEncode(msg string) string {
+	b := &strings.Builder{}
+	for i := range len(msg) {
+		c := msg[i]
+		if c < ' ' || c > '~' || c == '%' {
+			fmt.Fprintf(b, "%%%02X", c)
+		} else {
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// callContext returns the context for the call of given request
+// carrying its metadata and deadline.
+func callContext(r *http.Request) (context.Context, context.CancelFunc, error) {
+	md, err := metadataFromHeader(r.Header)
+	if err != nil {
+		return nil, nil, err
+	}
+	ctx := metadata.NewOutgoingContext(r.Context(), md)
+
+	timeout, ok, err := timeoutFromHeader(r.Header)
+	if err != nil {
+		return nil, nil, err
+	}
+	if ok {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		return ctx, cancel, nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	return ctx, cancel, nil
+}
+
+func timeoutFromHeader(h http.Header) (time.Duration, bool, error) {
+	v := h.Get("Grpc-Timeout")
+	if v == "" {
+		return 0, false, nil
+	}
+	units := map[byte]time.Duration{
+		'H': time.Hour,
+		'M': time.Minute,
+		'S': time.Second,
+		'm': time.Millisecond,
+		'u': time.Microsecond,
+		'n': time.Nanosecond,
+	}
+	unit, ok := units[v[len(v)-1]]
+	n, err := strconv.ParseUint(v[:len(v)-1], 10, 32)
+	if !ok || err != nil {
+		return 0, false, status.Errorf(codes.InvalidArgument, "invalid timeout %q", v)
+	}
+	return time.Duration(n) * unit, true, nil
+}
+
+// isWebHeader reports whether the request header is for HTTP or the protocol
+// rather than the metadata of the call.
+func isWebHeader(k string) bool {
+	switch k {
+	case "accept", "accept-encoding", "accept-language", "cache-control", "connection",
+		"content-encoding", "content-length", "content-type", "cookie", "host", "keep-alive",
+		"origin", "pragma", "proxy-connection", "referer", "te", "trailer", "transfer-encoding",
+		"upgrade", "user-agent", "x-grpc-web", "x-user-agent":
+		return true
+	}
+	for _, prefix := range []string{"access-control-", "grpc-", "sec-"} {
+		if strings.HasPrefix(k, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func metadataFromHeader(h http.Header) (metadata.MD, error) {
+	md := metadata.MD{}
+	for k, vs := range h {
+		k = strings.ToLower(k)
+		if isWebHeader(k) {
+			continue
+		}
+		for _, v := range vs {
+			if strings.HasSuffix(k, "-bin") {
+				b, err := base64.RawStdEncoding.DecodeString(strings.TrimRight(v, "="))
+				if err != nil {
+					return nil, status.Errorf(codes.InvalidArgument, "invalid binary header %q", k)
+				}
+				v = string(b)
+			}
+			md.Append(k, v)
+		}
+	}
+	return md, nil
+}
+
+func encodeMetadataValue(k string, v string) string {
+	if strings.HasSuffix(k, "-bin") {
+		return base64.RawStdEncoding.EncodeToString([]byte(v))
+	}
+	return v
+}
+
+func writeMetadata(h http.Header, md metadata.MD) {
+	for k, vs := range md {
+		for _, v := range vs {
+			h.Add(k, encodeMetadataValue(k, v))
+		}
+	}
+}